@@ -0,0 +1,166 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/okteto/okteto/pkg/cmd/pipeline"
+	"github.com/okteto/okteto/pkg/config"
+	oktetoLog "github.com/okteto/okteto/pkg/log"
+)
+
+const (
+	auditLogFile       = "deploy-audit.jsonl"
+	dryRunAuditLogFile = "deploy-dry-run-audit.jsonl"
+)
+
+// AuditEntry represents a mutating request done to the kubernetes API through the proxy
+type AuditEntry struct {
+	Time       time.Time `json:"time"`
+	Method     string    `json:"method"`
+	Group      string    `json:"group,omitempty"`
+	Version    string    `json:"version"`
+	Kind       string    `json:"kind"`
+	Resource   string    `json:"resource"`
+	Namespace  string    `json:"namespace,omitempty"`
+	Name       string    `json:"name,omitempty"`
+	StatusCode int       `json:"statusCode"`
+	DurationMs int64     `json:"durationMs"`
+	DryRun     bool      `json:"dryRun,omitempty"`
+}
+
+// auditRecorder keeps every mutating request done through the proxy
+type auditRecorder struct {
+	mu      sync.Mutex
+	entries []AuditEntry
+}
+
+func (a *auditRecorder) record(e AuditEntry) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.entries = append(a.entries, e)
+}
+
+func (a *auditRecorder) getEntries() []AuditEntry {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	result := make([]AuditEntry, len(a.entries))
+	copy(result, a.entries)
+	return result
+}
+
+// newAuditEntry builds the audit entry of a mutating request
+func newAuditEntry(method string, rr *resourceRequest, obj *responseObject, statusCode int, start time.Time) AuditEntry {
+	e := AuditEntry{
+		Time:       start.UTC(),
+		Method:     method,
+		Group:      rr.Group,
+		Version:    rr.Version,
+		Kind:       rr.Resource,
+		Resource:   rr.Resource,
+		Namespace:  rr.Namespace,
+		Name:       rr.Name,
+		StatusCode: statusCode,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if obj == nil || obj.Kind == "" || obj.Kind == "Status" {
+		return e
+	}
+	e.Kind = obj.Kind
+	if obj.Metadata.Name != "" {
+		e.Name = obj.Metadata.Name
+	}
+	if obj.Metadata.Namespace != "" {
+		e.Namespace = obj.Metadata.Namespace
+	}
+	return e
+}
+
+// getResourceAppliedData returns the data of the resource_applied event of a successful mutation
+func getResourceAppliedData(e AuditEntry) oktetoLog.ResourceAppliedData {
+	apiVersion := e.Version
+	if e.Group != "" {
+		apiVersion = fmt.Sprintf("%s/%s", e.Group, e.Version)
+	}
+	return oktetoLog.ResourceAppliedData{
+		Action:     getAction(e.Method),
+		APIVersion: apiVersion,
+		Kind:       e.Kind,
+		Resource:   e.Resource,
+		Namespace:  e.Namespace,
+		Name:       e.Name,
+		StatusCode: e.StatusCode,
+		DryRun:     e.DryRun,
+	}
+}
+
+// getAuditSummary returns the summary of the audit entries to be stored in the pipeline configmap
+func getAuditSummary(entries []AuditEntry) *pipeline.AuditSummary {
+	summary := &pipeline.AuditSummary{
+		Actions: map[string]int{},
+	}
+	for _, e := range entries {
+		summary.Total++
+		if e.StatusCode < 200 || e.StatusCode >= 300 {
+			summary.Failed++
+			continue
+		}
+		summary.Actions[getAction(e.Method)]++
+	}
+	return summary
+}
+
+// writeAuditLog writes the audit entries in JSONL format
+func writeAuditLog(path string, entries []AuditEntry) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, e := range entries {
+		if err := encoder.Encode(e); err != nil {
+			return fmt.Errorf("could not encode audit entry: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("could not write audit log '%s': %w", path, err)
+	}
+	return nil
+}
+
+// saveAuditLog writes the audit log of the deploy to the app home directory and to the file requested by the user.
+// Dry runs use their own file in the app home directory so they don't replace the audit log of the last deploy
+func (dc *DeployCommand) saveAuditLog(opts *Options) *pipeline.AuditSummary {
+	entries := dc.Proxy.GetAuditEntries()
+
+	fileName := auditLogFile
+	if opts.DryRun {
+		fileName = dryRunAuditLogFile
+	}
+	appLog := filepath.Join(config.GetAppHome(opts.Manifest.Namespace, opts.Name), fileName)
+	if err := writeAuditLog(appLog, entries); err != nil {
+		oktetoLog.Infof("could not save audit log: %s", err)
+	}
+
+	if opts.AuditLogPath != "" {
+		if err := writeAuditLog(opts.AuditLogPath, entries); err != nil {
+			oktetoLog.Warning("%s", err)
+		}
+	}
+	return getAuditSummary(entries)
+}
@@ -41,7 +41,7 @@ import (
 	oktetoPath "github.com/okteto/okteto/pkg/path"
 	"github.com/okteto/okteto/pkg/types"
 	"github.com/spf13/cobra"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 )
 
 const (
@@ -61,6 +61,7 @@ type Options struct {
 	Namespace        string
 	K8sContext       string
 	Variables        []string
+	VarFiles         []string
 	Manifest         *model.Manifest
 	Build            bool
 	Dependencies     bool
@@ -71,6 +72,25 @@ type Options struct {
 	Branch     string
 	Wait       bool
 	Timeout    time.Duration
+	// DryRun executes the deploy sending the mutating requests in dry-run mode to the API server
+	DryRun bool
+	// AuditLogPath is the file where the kubernetes mutations done by the deploy are written
+	AuditLogPath string
+	// LockTimeout is the time to wait for the lock of the development environment held by another operation
+	LockTimeout time.Duration
+	// ForceUnlock releases the lock of the development environment held by another operation
+	ForceUnlock bool
+	// Replay is the id of a deploy of the history whose variables are used
+	Replay int
+	// StoreVariables stores the values of the non-secret variables in the deploy history
+	StoreVariables bool
+	// Resume skips the commands completed by the last deploy if their definition and variables didn't change
+	Resume bool
+	// CommandsTimeout is the time to run all the commands of the manifest. Zero means no limit
+	CommandsTimeout time.Duration
+	// CheckEndpoints requests the endpoints after the deploy and fails the deploy if any of them is not healthy
+	CheckEndpoints bool
+	EndpointCheck  EndpointCheckOptions
 
 	ShowCTA bool
 }
@@ -87,6 +107,9 @@ type DeployCommand struct {
 	Builder            *buildv2.OktetoBuilder
 
 	PipelineType model.Archetype
+
+	history *historyRecorder
+	steps   *stepTracker
 }
 
 // Deploy deploys the okteto manifest
@@ -103,7 +126,13 @@ func Deploy(ctx context.Context) *cobra.Command {
 				return fmt.Errorf("'dependencies' is only supported in clusters that have Okteto installed")
 			}
 
-			if err := validateAndSet(options.Variables, os.Setenv); err != nil {
+			variables, err := utils.LoadVariables(options.VarFiles, options.Variables)
+			if err != nil {
+				return err
+			}
+			options.Variables = variables
+
+			if err := utils.SetVariables(options.Variables, os.Setenv); err != nil {
 				return err
 			}
 
@@ -139,7 +168,7 @@ func Deploy(ctx context.Context) *cobra.Command {
 				}
 			}
 
-			if okteto.IsOkteto() {
+			if okteto.IsOkteto() && !options.DryRun {
 				create, err := utils.ShouldCreateNamespace(ctx, okteto.Context().Namespace)
 				if err != nil {
 					return err
@@ -176,10 +205,12 @@ func Deploy(ctx context.Context) *cobra.Command {
 				return err
 			}
 
+			commandExecutor := executor.NewExecutor(oktetoLog.GetOutputFormat(), options.RunWithoutBash)
+			commandExecutor.SetTimeout(options.CommandsTimeout)
 			c := &DeployCommand{
 				GetManifest:        model.GetManifestV2,
 				Kubeconfig:         kubeconfig,
-				Executor:           executor.NewExecutor(oktetoLog.GetOutputFormat(), options.RunWithoutBash),
+				Executor:           commandExecutor,
 				Proxy:              proxy,
 				TempKubeconfigFile: GetTempKubeConfigFile(name),
 				K8sClientProvider:  okteto.NewK8sClientProvider(),
@@ -243,13 +274,25 @@ func Deploy(ctx context.Context) *cobra.Command {
 	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "overwrites the namespace where the development environment is deployed")
 	cmd.Flags().StringVarP(&options.K8sContext, "context", "c", "", "context where the development environment is deployed")
 	cmd.Flags().StringArrayVarP(&options.Variables, "var", "v", []string{}, "set a variable (can be set more than once)")
+	cmd.Flags().StringArrayVar(&options.VarFiles, "var-file", []string{}, utils.VarFileFlagUsage)
+	cmd.Flags().DurationVar(&options.CommandsTimeout, "commands-timeout", 0, "the length of time to run all the commands of the manifest, zero means never. The 'timeout' field of a command limits the time to run that command")
 	cmd.Flags().BoolVarP(&options.Build, "build", "", false, "force build of images when deploying the development environment")
 	cmd.Flags().BoolVarP(&options.Dependencies, "dependencies", "", false, "deploy the dependencies from manifest")
 	cmd.Flags().BoolVarP(&options.RunWithoutBash, "no-bash", "", false, "execute commands without bash")
 
 	cmd.Flags().BoolVarP(&options.Wait, "wait", "w", false, "wait until the development environment is deployed (defaults to false)")
 	cmd.Flags().DurationVarP(&options.Timeout, "timeout", "t", (5 * time.Minute), "the length of time to wait for completion, zero means never. Any other values should contain a corresponding time unit e.g. 1s, 2m, 3h ")
-
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "", false, "show the resources that would be created or changed without applying them. The deploy commands still run, only their requests to the cluster are not applied: side effects outside the cluster, like calls to external APIs, registry pushes or local files, still take place")
+	cmd.Flags().StringVar(&options.AuditLogPath, "audit-log", "", "path to a file where the kubernetes changes done by the deploy are written in JSONL format")
+	cmd.Flags().DurationVar(&options.LockTimeout, "lock-timeout", 0, "the length of time to wait if the development environment is locked by another deploy or destroy, zero fails immediately")
+	cmd.Flags().BoolVar(&options.ForceUnlock, "force-unlock", false, "release the lock of the development environment held by another deploy or destroy")
+	cmd.Flags().IntVar(&options.Replay, "replay", 0, "deploy using the variables of a deploy from 'okteto deploy history'. The values not stored in the history must be set again")
+	cmd.Flags().BoolVar(&options.StoreVariables, "store-variables", false, "store the values of the non-secret variables in the deploy history. By default only their names are stored")
+	cmd.Flags().BoolVar(&options.Resume, "resume", false, "skip the commands completed by the last deploy if their definition and variables didn't change")
+	cmd.Flags().BoolVar(&options.CheckEndpoints, "check-endpoints", false, "request every endpoint after the deploy and fail if any of them is not healthy")
+	addEndpointCheckFlags(cmd, &options.EndpointCheck)
+
+	cmd.AddCommand(History(ctx))
 	return cmd
 }
 
@@ -291,6 +334,20 @@ func (dc *DeployCommand) RunDeploy(ctx context.Context, deployOptions *Options)
 		return err
 	}
 
+	if deployOptions.Replay > 0 {
+		if err := loadReplayVariables(ctx, deployOptions, c); err != nil {
+			return err
+		}
+		// the manifest is loaded again to expand the replayed variables
+		deployOptions.Manifest, err = dc.GetManifest(deployOptions.ManifestPath)
+		if err != nil {
+			return err
+		}
+		if err := setDeployOptionsValuesFromManifest(ctx, deployOptions, cwd, c); err != nil {
+			return err
+		}
+	}
+
 	data := &pipeline.CfgData{
 		Name:       deployOptions.Name,
 		Namespace:  deployOptions.Manifest.Namespace,
@@ -307,9 +364,11 @@ func (dc *DeployCommand) RunDeploy(ctx context.Context, deployOptions *Options)
 	}
 
 	dc.Proxy.SetName(deployOptions.Name)
+	dc.Proxy.SetDryRun(deployOptions.DryRun)
+	dc.Proxy.SetPodTemplates(deployOptions.Manifest.Deploy.PodTemplates)
 	// don't divert if current namespace is the diverted namespace
 	if deployOptions.Manifest.Deploy.Divert != nil {
-		if !okteto.IsOkteto() {
+		if deployOptions.Manifest.Deploy.Divert.GetDriver() == model.DivertWeaverDriver && !okteto.IsOkteto() {
 			return oktetoErrors.ErrDivertNotSupported
 		}
 		if deployOptions.Manifest.Deploy.Divert.Namespace != deployOptions.Manifest.Namespace {
@@ -330,11 +389,40 @@ func (dc *DeployCommand) RunDeploy(ctx context.Context, deployOptions *Options)
 	oktetoLog.Debugf("starting server on %d", dc.Proxy.GetPort())
 	dc.Proxy.Start()
 
+	if deployOptions.DryRun {
+		return dc.runDryRun(ctx, deployOptions)
+	}
+
+	// nested deploys are protected by the lock of the deploy running them
+	if !utils.LoadBoolean(model.OktetoWithinDeployCommandContextEnvVar) {
+		lock, err := pipeline.AcquireLock(ctx, pipeline.LockOptions{
+			Name:      deployOptions.Name,
+			Namespace: deployOptions.Manifest.Namespace,
+			Operation: pipeline.DeployOperation,
+			Timeout:   deployOptions.LockTimeout,
+			Force:     deployOptions.ForceUnlock,
+		}, c)
+		if err != nil {
+			return err
+		}
+		defer lock.Release(ctx)
+	}
+	dc.history = newHistoryRecorder(deployOptions.Variables, deployOptions.StoreVariables)
+
 	cfg, err := getConfigMapFromData(ctx, data, c)
 	if err != nil {
 		return err
 	}
 
+	var previousSteps []pipeline.DeployStep
+	if deployOptions.Resume {
+		previousSteps, err = pipeline.GetDeploySteps(cfg)
+		if err != nil {
+			oktetoLog.Infof("could not resume the deploy: %s", err)
+		}
+	}
+	dc.steps = newStepTracker(previousSteps, deployOptions.Variables, deployOptions.Resume)
+
 	// TODO: take this out to a new function deploy dependencies
 	for depName, dep := range deployOptions.Manifest.Dependencies {
 		oktetoLog.Information("Deploying dependency '%s'", depName)
@@ -357,6 +445,7 @@ func (dc *DeployCommand) RunDeploy(ctx context.Context, deployOptions *Options)
 			return err
 		}
 		if err := pc.ExecuteDeployPipeline(ctx, pipOpts); err != nil {
+			dc.history.addTo(cfg, data, pipeline.ErrorStatus)
 			if errStatus := updateConfigMapStatus(ctx, cfg, c, data, err); errStatus != nil {
 				return errStatus
 			}
@@ -366,6 +455,7 @@ func (dc *DeployCommand) RunDeploy(ctx context.Context, deployOptions *Options)
 	}
 
 	if err := buildImages(ctx, dc.Builder.Build, dc.Builder.GetServicesToBuild, deployOptions); err != nil {
+		dc.history.addTo(cfg, data, pipeline.ErrorStatus)
 		return updateConfigMapStatusError(ctx, cfg, c, data, err)
 	}
 
@@ -373,27 +463,7 @@ func (dc *DeployCommand) RunDeploy(ctx context.Context, deployOptions *Options)
 
 	defer dc.cleanUp(ctx)
 
-	for _, variable := range deployOptions.Variables {
-		value := strings.SplitN(variable, "=", 2)[1]
-		if strings.TrimSpace(value) != "" {
-			oktetoLog.AddMaskedWord(value)
-		}
-	}
-	deployOptions.Variables = append(
-		deployOptions.Variables,
-		// Set KUBECONFIG environment variable as environment for the commands to be executed
-		fmt.Sprintf("%s=%s", model.KubeConfigEnvVar, dc.TempKubeconfigFile),
-		// Set OKTETO_WITHIN_DEPLOY_COMMAND_CONTEXT env variable, so all okteto commands ran inside this deploy
-		// know they are running inside another okteto deploy
-		fmt.Sprintf("%s=true", model.OktetoWithinDeployCommandContextEnvVar),
-		// Set OKTETO_SKIP_CONFIG_CREDENTIALS_UPDATE env variable, so all the Okteto commands executed within this command execution
-		// should not overwrite the server and the credentials in the kubeconfig
-		fmt.Sprintf("%s=true", model.OktetoSkipConfigCredentialsUpdate),
-		// Set OKTETO_DISABLE_SPINNER=true env variable, so all the Okteto commands disable spinner which leads to errors
-		fmt.Sprintf("%s=true", oktetoLog.OktetoDisableSpinnerEnvVar),
-		// Set OKTETO_NAMESPACE=namespace-name env variable, so all the commandsruns on the same namespace
-		fmt.Sprintf("%s=%s", model.OktetoNamespaceEnvVar, okteto.Context().Namespace),
-	)
+	dc.addDeployVariables(deployOptions)
 	oktetoLog.EnableMasking()
 	err = dc.deploy(ctx, deployOptions)
 	oktetoLog.DisableMasking()
@@ -401,6 +471,14 @@ func (dc *DeployCommand) RunDeploy(ctx context.Context, deployOptions *Options)
 	oktetoLog.AddToBuffer(oktetoLog.InfoLevel, "EOF")
 	oktetoLog.SetStage("")
 
+	data.AuditSummary = dc.saveAuditLog(deployOptions)
+	if inventory, errInventory := pipeline.GetInventory(cfg); errInventory != nil {
+		oktetoLog.Infof("could not update the inventory: %s", errInventory)
+	} else {
+		data.Inventory = inventory.Update(dc.Proxy.GetInventoryChanges())
+	}
+	data.Steps = dc.steps.getCompleted()
+
 	if err != nil {
 		if err == oktetoErrors.ErrIntSig {
 			return nil
@@ -410,13 +488,14 @@ func (dc *DeployCommand) RunDeploy(ctx context.Context, deployOptions *Options)
 		data.Status = pipeline.ErrorStatus
 	} else {
 		oktetoLog.SetStage("")
-		hasDeployed, err := pipeline.HasDeployedSomething(ctx, deployOptions.Name, deployOptions.Manifest.Namespace, c)
-		if err != nil {
-			return err
+		hasDeployed, hasDeployedErr := pipeline.HasDeployedSomething(ctx, deployOptions.Name, deployOptions.Manifest.Namespace, c)
+		if hasDeployedErr != nil {
+			return hasDeployedErr
 		}
+		data.Status = pipeline.DeployedStatus
 		if hasDeployed {
 			if deployOptions.Wait {
-				if err := dc.wait(ctx, deployOptions); err != nil {
+				if err := dc.wait(ctx, deployOptions, data.Inventory); err != nil {
 					return err
 				}
 			}
@@ -425,7 +504,14 @@ func (dc *DeployCommand) RunDeploy(ctx context.Context, deployOptions *Options)
 					oktetoLog.Infof("could not retrieve endpoints: %s", err)
 				}
 			}
-			if deployOptions.ShowCTA {
+			if deployOptions.CheckEndpoints {
+				err = dc.checkEndpoints(ctx, &EndpointsOptions{Name: deployOptions.Name, Namespace: deployOptions.Manifest.Namespace}, &deployOptions.EndpointCheck)
+				if err != nil {
+					oktetoLog.AddToBuffer(oktetoLog.InfoLevel, err.Error())
+					data.Status = pipeline.ErrorStatus
+				}
+			}
+			if deployOptions.ShowCTA && err == nil {
 				oktetoLog.Success(succesfullyDeployedmsg, deployOptions.Name)
 				if oktetoLog.IsInteractive() {
 					oktetoLog.Information("Run 'okteto up' to activate your development container")
@@ -433,8 +519,8 @@ func (dc *DeployCommand) RunDeploy(ctx context.Context, deployOptions *Options)
 			}
 			pipeline.AddDevAnnotations(ctx, deployOptions.Manifest, c)
 		}
-		data.Status = pipeline.DeployedStatus
 	}
+	dc.history.addTo(cfg, data, data.Status)
 
 	if err := pipeline.UpdateConfigMap(ctx, cfg, data, c); err != nil {
 		return err
@@ -443,22 +529,136 @@ func (dc *DeployCommand) RunDeploy(ctx context.Context, deployOptions *Options)
 	return err
 }
 
+// runDryRun executes the deploy commands sending every mutating request in dry-run mode.
+// Dependencies are not deployed, images are not built and the pipeline configmap is not updated.
+// The commands run as usual: only their requests to the cluster go through the proxy in dry-run mode
+func (dc *DeployCommand) runDryRun(ctx context.Context, deployOptions *Options) error {
+	for depName := range deployOptions.Manifest.Dependencies {
+		oktetoLog.Information("Skipping dependency '%s' in dry-run mode", depName)
+	}
+
+	skipBuild := func(_ context.Context, opts *types.BuildOptions) error {
+		oktetoLog.Information("Skipping build of %s in dry-run mode", strings.Join(opts.CommandArgs, ", "))
+		return nil
+	}
+	if err := buildImages(ctx, skipBuild, dc.Builder.GetServicesToBuild, deployOptions); err != nil {
+		return err
+	}
+
+	defer dc.cleanUp(ctx)
+
+	if deployOptions.Manifest.Deploy != nil && len(deployOptions.Manifest.Deploy.Commands) > 0 {
+		oktetoLog.Warning(`The deploy commands still run in dry-run mode: only their requests to your cluster through the okteto kubeconfig are not applied.
+    Any other side effect of the commands takes place, for example calls to cloud providers or external APIs, images or charts pushed to registries,
+    local files written by the commands, or requests to your cluster with a different kubeconfig`)
+	}
+
+	dc.addDeployVariables(deployOptions)
+	oktetoLog.EnableMasking()
+	err := dc.deploy(ctx, deployOptions)
+	oktetoLog.DisableMasking()
+	oktetoLog.SetStage("")
+	dc.saveAuditLog(deployOptions)
+	if err != nil {
+		if err == oktetoErrors.ErrIntSig {
+			return nil
+		}
+		return oktetoErrors.UserError{E: err}
+	}
+
+	printDryRunResources(deployOptions.Name, dc.Proxy.GetDryRunResources())
+	return nil
+}
+
+// addDeployVariables masks the user variables and adds the variables needed by the deploy commands
+func (dc *DeployCommand) addDeployVariables(deployOptions *Options) {
+	for _, variable := range deployOptions.Variables {
+		value := strings.SplitN(variable, "=", 2)[1]
+		if strings.TrimSpace(value) != "" {
+			oktetoLog.AddMaskedWord(value)
+		}
+	}
+	deployOptions.Variables = append(
+		deployOptions.Variables,
+		// Set KUBECONFIG environment variable as environment for the commands to be executed
+		fmt.Sprintf("%s=%s", model.KubeConfigEnvVar, dc.TempKubeconfigFile),
+		// Set OKTETO_WITHIN_DEPLOY_COMMAND_CONTEXT env variable, so all okteto commands ran inside this deploy
+		// know they are running inside another okteto deploy
+		fmt.Sprintf("%s=true", model.OktetoWithinDeployCommandContextEnvVar),
+		// Set OKTETO_SKIP_CONFIG_CREDENTIALS_UPDATE env variable, so all the Okteto commands executed within this command execution
+		// should not overwrite the server and the credentials in the kubeconfig
+		fmt.Sprintf("%s=true", model.OktetoSkipConfigCredentialsUpdate),
+		// Set OKTETO_DISABLE_SPINNER=true env variable, so all the Okteto commands disable spinner which leads to errors
+		fmt.Sprintf("%s=true", oktetoLog.OktetoDisableSpinnerEnvVar),
+		// Set OKTETO_NAMESPACE=namespace-name env variable, so all the commandsruns on the same namespace
+		fmt.Sprintf("%s=%s", model.OktetoNamespaceEnvVar, okteto.Context().Namespace),
+	)
+}
+
 func (dc *DeployCommand) deploy(ctx context.Context, opts *Options) error {
 	// deploy commands if any
-	for _, command := range opts.Manifest.Deploy.Commands {
+	for i, command := range opts.Manifest.Deploy.Commands {
+		if command.Image == "" {
+			command.Image = opts.Manifest.Deploy.Image
+		}
+		if command.Image != "" {
+			image, err := model.ExpandEnv(command.Image, false)
+			if err != nil {
+				return fmt.Errorf("error expanding the image of command '%s': %w", command.Name, err)
+			}
+			command.Image = image
+		}
+		if dc.steps.canSkip(i, command) {
+			oktetoLog.Information("Skipping '%s': it completed in the previous deploy", command.Name)
+			dc.steps.complete(command)
+			continue
+		}
 		oktetoLog.Information("Running '%s'", command.Name)
 		oktetoLog.SetStage(command.Name)
-		if err := dc.Executor.Execute(command, opts.Variables); err != nil {
+		start := time.Now()
+		err := dc.Executor.Execute(command, opts.Variables)
+		dc.history.recordCommand(command.Name, start, err)
+		if err != nil {
 			oktetoLog.AddToBuffer(oktetoLog.ErrorLevel, "error executing command '%s': %s", command.Name, err.Error())
 			return fmt.Errorf("error executing command '%s': %s", command.Name, err.Error())
 		}
+		dc.steps.complete(command)
+		oktetoLog.SetStage("")
+	}
+
+	// deploy helm release if any
+	if opts.Manifest.Deploy.Helm != nil {
+		oktetoLog.SetStage("Deploying helm release")
+		start := time.Now()
+		err := dc.deployHelm(ctx, opts)
+		dc.history.recordCommand("Deploying helm release", start, err)
+		if err != nil {
+			oktetoLog.AddToBuffer(oktetoLog.ErrorLevel, "error deploying helm release: %s", err.Error())
+			return err
+		}
+		oktetoLog.SetStage("")
+	}
+
+	// deploy kustomization if any
+	if opts.Manifest.Deploy.Kustomize != nil {
+		oktetoLog.SetStage("Deploying kustomization")
+		start := time.Now()
+		err := dc.deployKustomize(ctx, opts)
+		dc.history.recordCommand("Deploying kustomization", start, err)
+		if err != nil {
+			oktetoLog.AddToBuffer(oktetoLog.ErrorLevel, "error deploying kustomization: %s", err.Error())
+			return err
+		}
 		oktetoLog.SetStage("")
 	}
 
 	// deploy compose if any
 	if opts.Manifest.Deploy.ComposeSection != nil {
 		oktetoLog.SetStage("Deploying compose")
-		if err := dc.deployStack(ctx, opts); err != nil {
+		start := time.Now()
+		err := dc.deployStack(ctx, opts)
+		dc.history.recordCommand("Deploying compose", start, err)
+		if err != nil {
 			oktetoLog.AddToBuffer(oktetoLog.ErrorLevel, "error deploying compose: %s", err.Error())
 			return err
 		}
@@ -468,7 +668,10 @@ func (dc *DeployCommand) deploy(ctx context.Context, opts *Options) error {
 	// deploy endpoits if any
 	if opts.Manifest.Deploy.Endpoints != nil {
 		oktetoLog.SetStage("Endpoints configuration")
-		if err := dc.deployEndpoints(ctx, opts); err != nil {
+		start := time.Now()
+		err := dc.deployEndpoints(ctx, opts)
+		dc.history.recordCommand("Endpoints configuration", start, err)
+		if err != nil {
 			oktetoLog.AddToBuffer(oktetoLog.ErrorLevel, "error generating endpoints: %s", err.Error())
 			return err
 		}
@@ -477,8 +680,15 @@ func (dc *DeployCommand) deploy(ctx context.Context, opts *Options) error {
 
 	// deploy diver if any
 	if opts.Manifest.Deploy.Divert != nil && opts.Manifest.Deploy.Divert.Namespace != opts.Manifest.Namespace {
+		if opts.DryRun {
+			oktetoLog.Information("Skipping divert from '%s' in dry-run mode", opts.Manifest.Deploy.Divert.Namespace)
+			return nil
+		}
 		oktetoLog.SetStage("Divert configuration")
-		if err := dc.deployDivert(ctx, opts); err != nil {
+		start := time.Now()
+		err := dc.deployDivert(ctx, opts)
+		dc.history.recordCommand("Divert configuration", start, err)
+		if err != nil {
 			oktetoLog.AddToBuffer(oktetoLog.ErrorLevel, "error creating divert: %s", err.Error())
 			return err
 		}
@@ -525,34 +735,33 @@ func (dc *DeployCommand) deployDivert(ctx context.Context, opts *Options) error
 	oktetoLog.StartSpinner()
 	defer oktetoLog.StopSpinner()
 
-	c, _, err := dc.K8sClientProvider.Provide(okteto.Context().Cfg)
+	c, cfg, err := dc.K8sClientProvider.Provide(okteto.Context().Cfg)
 	if err != nil {
 		return err
 	}
+	var dynClient dynamic.Interface
+	if opts.Manifest.Deploy.Divert.GetDriver() == model.DivertIstioDriver {
+		dynClient, err = dynamic.NewForConfig(cfg)
+		if err != nil {
+			return err
+		}
+	}
 
-	result, err := c.NetworkingV1().Ingresses(opts.Manifest.Deploy.Divert.Namespace).List(ctx, metav1.ListOptions{})
+	driver, err := diverts.NewDriver(opts.Manifest, c, dynClient)
 	if err != nil {
 		return err
 	}
-
-	for i := range result.Items {
-		select {
-		case <-ctx.Done():
-			oktetoLog.Infof("deployDivert context cancelled")
-			return ctx.Err()
-		default:
-			oktetoLog.Spinner(fmt.Sprintf("Diverting ingress %s/%s...", result.Items[i].Namespace, result.Items[i].Name))
-			if err := diverts.DivertIngress(ctx, opts.Manifest, &result.Items[i], c); err != nil {
-				return err
-			}
-		}
-	}
-	return nil
+	return driver.Deploy(ctx)
 }
 
 func (dc *DeployCommand) deployEndpoints(ctx context.Context, opts *Options) error {
+	cfg := okteto.Context().Cfg
+	if opts.DryRun {
+		// endpoints are created through the proxy so they are recorded as dry-run resources
+		cfg = kubeconfig.Get([]string{dc.TempKubeconfigFile})
+	}
 
-	c, _, err := dc.K8sClientProvider.Provide(okteto.Context().Cfg)
+	c, _, err := dc.K8sClientProvider.Provide(cfg)
 	if err != nil {
 		return err
 	}
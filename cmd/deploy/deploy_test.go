@@ -14,19 +14,26 @@
 package deploy
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	buildv2 "github.com/okteto/okteto/cmd/build/v2"
 	"github.com/okteto/okteto/internal/test"
 	"github.com/okteto/okteto/pkg/cmd/pipeline"
+	"github.com/okteto/okteto/pkg/config"
 	"github.com/okteto/okteto/pkg/errors"
 	"github.com/okteto/okteto/pkg/k8s/configmaps"
 	oktetoLog "github.com/okteto/okteto/pkg/log"
 	"github.com/okteto/okteto/pkg/model"
 	"github.com/okteto/okteto/pkg/okteto"
 	"github.com/okteto/okteto/pkg/types"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/apps/v1"
 	apiv1 "k8s.io/api/core/v1"
@@ -78,10 +85,16 @@ type fakeProxy struct {
 	token         string
 	started       bool
 	shutdown      bool
+	dryRun        bool
+	auditEntries  []AuditEntry
+	created       []pipeline.InventoryItem
+	deleted       []pipeline.InventoryItem
 }
 
 type fakeExecutor struct {
-	err      error
+	err error
+	// failOn is the name of the command that fails
+	failOn   string
 	executed []model.DeployCommand
 }
 
@@ -108,6 +121,24 @@ func (*fakeProxy) SetName(_ string) {}
 
 func (*fakeProxy) SetDivert(_ string) {}
 
+func (fk *fakeProxy) SetDryRun(dryRun bool) {
+	fk.dryRun = dryRun
+}
+
+func (*fakeProxy) GetDryRunResources() []DryRunResource {
+	return nil
+}
+
+func (*fakeProxy) SetPodTemplates(_ []model.PodTemplateRule) {}
+
+func (fk *fakeProxy) GetAuditEntries() []AuditEntry {
+	return fk.auditEntries
+}
+
+func (fk *fakeProxy) GetInventoryChanges() ([]pipeline.InventoryItem, []pipeline.InventoryItem) {
+	return fk.created, fk.deleted
+}
+
 func (fk *fakeProxy) Shutdown(_ context.Context) error {
 	if fk.errOnShutdown != nil {
 		return fk.errOnShutdown
@@ -130,6 +161,9 @@ func (fe *fakeExecutor) Execute(command model.DeployCommand, _ []string) error {
 	if fe.err != nil {
 		return fe.err
 	}
+	if fe.failOn != "" && fe.failOn == command.Name {
+		return assert.AnError
+	}
 
 	return nil
 }
@@ -263,11 +297,18 @@ func TestCreateConfigMapWithBuildError(t *testing.T) {
 
 	expectedCfg.Data["output"] = cfg.Data["output"]
 
+	history, err := pipeline.GetHistory(cfg)
+	assert.NoError(t, err)
+	assert.Len(t, history, 1)
+	assert.Equal(t, pipeline.ErrorStatus, history[0].Status)
+	expectedCfg.Data["history"] = cfg.Data["history"]
+
 	assert.True(t, strings.Contains(oktetoLog.GetOutputBuffer().String(), errors.InvalidDockerfile))
 	assert.Equal(t, expectedCfg, cfg)
 }
 
 func TestDeployWithErrorExecutingCommands(t *testing.T) {
+	t.Setenv(model.OktetoFolderEnvVar, t.TempDir())
 	p := &fakeProxy{}
 	e := &fakeExecutor{
 		err: assert.AnError,
@@ -318,6 +359,7 @@ func TestDeployWithErrorExecutingCommands(t *testing.T) {
 }
 
 func TestDeployWithErrorBecauseOtherPipelineRunning(t *testing.T) {
+	t.Setenv(model.OktetoFolderEnvVar, t.TempDir())
 	p := &fakeProxy{
 		errOnShutdown: assert.AnError,
 	}
@@ -335,13 +377,16 @@ func TestDeployWithErrorBecauseOtherPipelineRunning(t *testing.T) {
 		ManifestPath: "",
 		Variables:    []string{},
 	}
+	now := time.Now().UTC()
+	lease, err := json.Marshal(pipeline.Lease{ID: "other", Holder: "cindy@laptop", Operation: pipeline.DeployOperation, AcquiredAt: now, RenewedAt: now, ExpiresAt: now.Add(time.Minute)})
+	assert.NoError(t, err)
 	cmap := &apiv1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      pipeline.TranslatePipelineName(opts.Name),
 			Namespace: "test",
 		},
 		Data: map[string]string{
-			"actionLock": "test",
+			"lease": string(lease),
 		},
 	}
 	deployment := &v1.Deployment{
@@ -360,9 +405,10 @@ func TestDeployWithErrorBecauseOtherPipelineRunning(t *testing.T) {
 	}
 	ctx := context.Background()
 
-	err := c.RunDeploy(ctx, opts)
+	err = c.RunDeploy(ctx, opts)
 
 	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "is locked by 'cindy@laptop' running 'deploy'")
 	// No command was executed
 	assert.Len(t, e.executed, 0)
 	// Proxy started
@@ -379,6 +425,7 @@ func TestDeployWithErrorBecauseOtherPipelineRunning(t *testing.T) {
 }
 
 func TestDeployWithErrorShuttingdownProxy(t *testing.T) {
+	t.Setenv(model.OktetoFolderEnvVar, t.TempDir())
 	p := &fakeProxy{
 		errOnShutdown: assert.AnError,
 	}
@@ -438,7 +485,26 @@ func TestDeployWithErrorShuttingdownProxy(t *testing.T) {
 }
 
 func TestDeployWithoutErrors(t *testing.T) {
-	p := &fakeProxy{}
+	t.Setenv(model.OktetoFolderEnvVar, t.TempDir())
+	p := &fakeProxy{
+		auditEntries: []AuditEntry{
+			{
+				Method:     "POST",
+				Kind:       "Deployment",
+				Name:       "api",
+				StatusCode: 201,
+			},
+			{
+				Method:     "PUT",
+				Kind:       "Service",
+				Name:       "api",
+				StatusCode: 409,
+			},
+		},
+		created: []pipeline.InventoryItem{
+			{APIVersion: "apps/v1", Kind: "Deployment", Resource: "deployments", Namespace: "test", Name: "api", UID: "1234"},
+		},
+	}
 	e := &fakeExecutor{}
 	okteto.CurrentStore = &okteto.OktetoContextStore{
 		Contexts: map[string]*okteto.OktetoContext{
@@ -468,6 +534,7 @@ func TestDeployWithoutErrors(t *testing.T) {
 		Name:         "movies",
 		ManifestPath: "",
 		Variables:    []string{},
+		AuditLogPath: filepath.Join(t.TempDir(), "audit.jsonl"),
 	}
 
 	err := c.RunDeploy(ctx, opts)
@@ -491,6 +558,76 @@ func TestDeployWithoutErrors(t *testing.T) {
 	assert.Nil(t, err)
 	assert.NotNil(t, cfg)
 	assert.Equal(t, pipeline.DeployedStatus, cfg.Data["status"])
+	assert.Equal(t, `{"total":2,"failed":1,"actions":{"create":1}}`, cfg.Data["audit"])
+	inventory, err := pipeline.GetInventory(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, pipeline.Inventory(p.created), inventory)
+	history, err := pipeline.GetHistory(cfg)
+	assert.NoError(t, err)
+	assert.Len(t, history, 1)
+	assert.Equal(t, pipeline.DeployedStatus, history[0].Status)
+	assert.Len(t, history[0].Commands, 3)
+
+	// check audit log has been written
+	auditLog, err := os.ReadFile(opts.AuditLogPath)
+	assert.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(string(auditLog)), "\n"), 2)
+}
+
+func TestDeployDryRun(t *testing.T) {
+	oktetoFolder := t.TempDir()
+	t.Setenv(model.OktetoFolderEnvVar, oktetoFolder)
+	p := &fakeProxy{}
+	e := &fakeExecutor{}
+	okteto.CurrentStore = &okteto.OktetoContextStore{
+		Contexts: map[string]*okteto.OktetoContext{
+			"test": {
+				Namespace: "test",
+			},
+		},
+		CurrentContext: "test",
+	}
+	c := &DeployCommand{
+		GetManifest:       getFakeManifest,
+		Proxy:             p,
+		Executor:          e,
+		Kubeconfig:        &fakeKubeConfig{},
+		K8sClientProvider: test.NewFakeK8sProvider(),
+	}
+	ctx := context.Background()
+	opts := &Options{
+		Name:         "movies",
+		ManifestPath: "",
+		Variables:    []string{},
+		DryRun:       true,
+	}
+	output := &bytes.Buffer{}
+	oktetoLog.SetOutput(output)
+	defer oktetoLog.Init(logrus.WarnLevel)
+
+	err := c.RunDeploy(ctx, opts)
+
+	assert.NoError(t, err)
+	// users are warned that the commands are not simulated
+	assert.Contains(t, output.String(), "The deploy commands still run in dry-run mode")
+	// Commands are executed against the proxy in dry-run mode
+	assert.Equal(t, fakeManifest.Deploy.Commands, e.executed)
+	assert.True(t, p.dryRun)
+	assert.True(t, p.started)
+	assert.True(t, p.shutdown)
+
+	// check configmap has not been created
+	fakeClient, _, err := c.K8sClientProvider.Provide(clientcmdapi.NewConfig())
+	if err != nil {
+		t.Fatal("could not create fake k8s client")
+	}
+	_, err = configmaps.Get(ctx, pipeline.TranslatePipelineName(opts.Name), okteto.Context().Namespace, fakeClient)
+	assert.True(t, errors.IsNotFound(err))
+
+	// the audit log of the last deploy is not replaced
+	appHome := config.GetAppHome(okteto.Context().Namespace, opts.Name)
+	assert.FileExists(t, filepath.Join(appHome, dryRunAuditLogFile))
+	assert.NoFileExists(t, filepath.Join(appHome, auditLogFile))
 }
 
 func getManifestWithError(_ string) (*model.Manifest, error) {
@@ -634,3 +771,77 @@ func TestBuildImages(t *testing.T) {
 	}
 
 }
+
+func TestDeployCommandsWithImage(t *testing.T) {
+	t.Setenv("HELM_VERSION", "3.10.0")
+	e := &fakeExecutor{}
+	c := &DeployCommand{
+		Executor: e,
+	}
+	opts := &Options{
+		Manifest: &model.Manifest{
+			Deploy: &model.DeployInfo{
+				Image: "alpine/helm:${HELM_VERSION}",
+				Commands: []model.DeployCommand{
+					{Name: "helm", Command: "helm upgrade --install movies chart"},
+					{Name: "kubectl", Command: "kubectl apply -f k8s.yml", Image: "bitnami/kubectl:1.25"},
+				},
+			},
+		},
+	}
+
+	err := c.deploy(context.Background(), opts)
+	assert.NoError(t, err)
+	assert.Len(t, e.executed, 2)
+	assert.Equal(t, "alpine/helm:3.10.0", e.executed[0].Image)
+	assert.Equal(t, "bitnami/kubectl:1.25", e.executed[1].Image)
+	// the manifest is not modified
+	assert.Equal(t, "", opts.Manifest.Deploy.Commands[0].Image)
+}
+
+func TestDeployResume(t *testing.T) {
+	t.Setenv(model.OktetoFolderEnvVar, t.TempDir())
+	okteto.CurrentStore = &okteto.OktetoContextStore{
+		Contexts: map[string]*okteto.OktetoContext{
+			"test": {
+				Namespace: "test",
+			},
+		},
+		CurrentContext: "test",
+	}
+	e := &fakeExecutor{failOn: "ls -la"}
+	c := &DeployCommand{
+		GetManifest:       getFakeManifest,
+		Proxy:             &fakeProxy{},
+		Executor:          e,
+		Kubeconfig:        &fakeKubeConfig{},
+		K8sClientProvider: test.NewFakeK8sProvider(),
+	}
+	ctx := context.Background()
+
+	err := c.RunDeploy(ctx, &Options{Name: "movies", Variables: []string{"A=1"}})
+	assert.Error(t, err)
+	assert.Len(t, e.executed, 2)
+
+	fakeClient, _, err := c.K8sClientProvider.Provide(clientcmdapi.NewConfig())
+	assert.NoError(t, err)
+	cfg, err := configmaps.Get(ctx, pipeline.TranslatePipelineName("movies"), "test", fakeClient)
+	assert.NoError(t, err)
+	steps, err := pipeline.GetDeploySteps(cfg)
+	assert.NoError(t, err)
+	assert.Len(t, steps, 1)
+	assert.Equal(t, "printenv", steps[0].Name)
+
+	// the completed command is skipped
+	e.failOn = ""
+	e.executed = nil
+	err = c.RunDeploy(ctx, &Options{Name: "movies", Variables: []string{"A=1"}, Resume: true})
+	assert.NoError(t, err)
+	assert.Equal(t, fakeManifest.Deploy.Commands[1:], e.executed)
+
+	// changing the variables runs every command
+	e.executed = nil
+	err = c.RunDeploy(ctx, &Options{Name: "movies", Variables: []string{"A=2"}, Resume: true})
+	assert.NoError(t, err)
+	assert.Equal(t, fakeManifest.Deploy.Commands, e.executed)
+}
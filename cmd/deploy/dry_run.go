@@ -0,0 +1,103 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"text/tabwriter"
+
+	oktetoLog "github.com/okteto/okteto/pkg/log"
+)
+
+const dryRunQueryParam = "dryRun"
+
+// DryRunResource represents a resource that would be modified by a deploy
+type DryRunResource struct {
+	Action    string `json:"action"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+}
+
+// dryRunRecorder keeps the resources the API server accepted in dry-run mode
+type dryRunRecorder struct {
+	mu        sync.Mutex
+	resources []DryRunResource
+}
+
+func (d *dryRunRecorder) record(r DryRunResource) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.resources = append(d.resources, r)
+}
+
+func (d *dryRunRecorder) getResources() []DryRunResource {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	result := make([]DryRunResource, len(d.resources))
+	copy(result, d.resources)
+	return result
+}
+
+// setDryRun adds the dryRun=All query parameter so the API server validates the request without persisting it
+func setDryRun(r *http.Request) {
+	q := r.URL.Query()
+	q.Set(dryRunQueryParam, "All")
+	r.URL.RawQuery = q.Encode()
+}
+
+// newDryRunResource builds the dry-run entry of a request accepted by the API server
+func newDryRunResource(method string, rr *resourceRequest, obj *responseObject) DryRunResource {
+	result := DryRunResource{
+		Action:    getAction(method),
+		Kind:      rr.Resource,
+		Namespace: rr.Namespace,
+		Name:      rr.Name,
+	}
+	if obj == nil || obj.Kind == "" || obj.Kind == "Status" {
+		return result
+	}
+	result.Kind = obj.Kind
+	if obj.Metadata.Name != "" {
+		result.Name = obj.Metadata.Name
+	}
+	if obj.Metadata.Namespace != "" {
+		result.Namespace = obj.Metadata.Namespace
+	}
+	return result
+}
+
+// writeDryRunForbidden answers locally the requests that can't be executed in dry-run mode
+func writeDryRunForbidden(rw http.ResponseWriter, rr *resourceRequest) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusForbidden)
+	fmt.Fprintf(rw, `{"kind":"Status","apiVersion":"v1","status":"Failure","message":"%s/%s is not allowed in dry-run mode","reason":"Forbidden","code":403}`, rr.Resource, rr.Subresource)
+}
+
+func printDryRunResources(name string, resources []DryRunResource) {
+	if len(resources) == 0 {
+		oktetoLog.Information("Deploying '%s' wouldn't create or change any resource", name)
+		return
+	}
+	oktetoLog.Information("Deploying '%s' would apply the following changes:", name)
+	w := tabwriter.NewWriter(os.Stdout, 1, 1, 2, ' ', 0)
+	fmt.Fprintf(w, "Action\tKind\tNamespace\tName\n")
+	for _, r := range resources {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Action, r.Kind, r.Namespace, r.Name)
+	}
+	w.Flush()
+}
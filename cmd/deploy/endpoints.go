@@ -37,6 +37,9 @@ type EndpointsOptions struct {
 	Output       string
 	Namespace    string
 	K8sContext   string
+	// Check requests the endpoints and fails if any of them is not healthy
+	Check        bool
+	CheckOptions EndpointCheckOptions
 }
 
 // Endpoints deploys the okteto manifest
@@ -109,6 +112,9 @@ func Endpoints(ctx context.Context) *cobra.Command {
 			if err := validateOutput(options.Output); err != nil {
 				return err
 			}
+			if options.Check {
+				return c.checkEndpoints(ctx, options, &options.CheckOptions)
+			}
 			return c.showEndpoints(ctx, options)
 		},
 	}
@@ -118,6 +124,8 @@ func Endpoints(ctx context.Context) *cobra.Command {
 	cmd.Flags().StringVarP(&options.K8sContext, "context", "c", "", "context where the development environment is deployed")
 
 	cmd.Flags().StringVarP(&options.Output, "output", "o", "", "output format. One of: ['json', 'md']")
+	cmd.Flags().BoolVar(&options.Check, "check", false, "request every endpoint until it's healthy and fail if any of them is not healthy")
+	addEndpointCheckFlags(cmd, &options.CheckOptions)
 
 	return cmd
 }
@@ -0,0 +1,228 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	oktetoErrors "github.com/okteto/okteto/pkg/errors"
+	oktetoLog "github.com/okteto/okteto/pkg/log"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultCheckPath    = "/"
+	defaultCheckTimeout = 2 * time.Minute
+	// checkRequestTimeout is the timeout of every request to an endpoint
+	checkRequestTimeout = 10 * time.Second
+)
+
+// checkRetryInterval is the time between two requests to an endpoint that is not healthy
+var checkRetryInterval = 3 * time.Second
+
+// EndpointCheckOptions defines how the endpoints are checked
+type EndpointCheckOptions struct {
+	// Path is the path requested to every endpoint
+	Path string
+	// ExpectedStatus is the status code of a healthy endpoint. Zero means any status code lower than 400
+	ExpectedStatus int
+	// Timeout is the time to wait for the endpoints to be healthy
+	Timeout time.Duration
+}
+
+// EndpointCheck is the result of checking an endpoint
+type EndpointCheck struct {
+	URL        string `json:"url"`
+	Healthy    bool   `json:"healthy"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	// LatencyMs is the latency of the last request in milliseconds
+	LatencyMs int64  `json:"latencyMs"`
+	Attempts  int    `json:"attempts"`
+	Error     string `json:"error,omitempty"`
+}
+
+// addEndpointCheckFlags adds the flags that configure the endpoint checks
+func addEndpointCheckFlags(cmd *cobra.Command, opts *EndpointCheckOptions) {
+	cmd.Flags().StringVar(&opts.Path, "check-path", defaultCheckPath, "the path requested to every endpoint when checking the endpoints")
+	cmd.Flags().IntVar(&opts.ExpectedStatus, "check-status", 0, "the status code of a healthy endpoint. By default, any status code lower than 400")
+	cmd.Flags().DurationVar(&opts.Timeout, "check-timeout", defaultCheckTimeout, "the length of time to wait for the endpoints to be healthy")
+}
+
+// checkEndpoints checks the endpoints of the development environment
+func (dc *DeployCommand) checkEndpoints(ctx context.Context, opts *EndpointsOptions, checkOpts *EndpointCheckOptions) error {
+	eps, err := dc.getEndpoints(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if len(eps) == 0 {
+		oktetoLog.Information("There are no endpoints to check for '%s'", opts.Name)
+		return nil
+	}
+	return runEndpointChecks(ctx, eps, checkOpts, opts.Output, &http.Client{})
+}
+
+// runEndpointChecks requests every endpoint until it's healthy or the timeout expires, and shows the results.
+// It returns an error if any endpoint is not healthy
+func runEndpointChecks(ctx context.Context, eps []string, opts *EndpointCheckOptions, output string, client *http.Client) error {
+	if output == "" {
+		oktetoLog.Spinner("Checking endpoints...")
+		oktetoLog.StartSpinner()
+		defer oktetoLog.StopSpinner()
+	}
+
+	results := getEndpointChecks(ctx, eps, opts, client)
+	oktetoLog.StopSpinner()
+	if err := showEndpointChecks(results, output); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, r := range results {
+		if !r.Healthy {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return oktetoErrors.UserError{
+			E:    fmt.Errorf("%d of %d endpoints are not healthy", failed, len(results)),
+			Hint: "Check the logs of your services or increase the time to wait for them with the '--check-timeout' flag",
+		}
+	}
+	return nil
+}
+
+// getEndpointChecks checks the endpoints in parallel. The results are in the order of the endpoints
+func getEndpointChecks(ctx context.Context, eps []string, opts *EndpointCheckOptions, client *http.Client) []EndpointCheck {
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	results := make([]EndpointCheck, len(eps))
+	var wg sync.WaitGroup
+	for i, ep := range eps {
+		wg.Add(1)
+		go func(i int, ep string) {
+			defer wg.Done()
+			results[i] = checkEndpoint(ctx, getCheckURL(ep, opts.Path), opts.ExpectedStatus, client)
+		}(i, ep)
+	}
+	wg.Wait()
+	return results
+}
+
+// checkEndpoint requests the url until it's healthy or the context is done
+func checkEndpoint(ctx context.Context, url string, expectedStatus int, client *http.Client) EndpointCheck {
+	result := EndpointCheck{URL: url}
+	for {
+		previous := result
+		result.Attempts++
+		start := time.Now()
+		status, err := requestEndpoint(ctx, url, client)
+		if err != nil && ctx.Err() != nil && previous.Attempts > 0 {
+			// the request was canceled by the check timeout, the previous attempt is more meaningful
+			return previous
+		}
+		result.LatencyMs = time.Since(start).Milliseconds()
+		result.StatusCode = status
+		result.Error = ""
+		switch {
+		case err != nil:
+			result.Error = err.Error()
+		case isHealthyStatus(status, expectedStatus):
+			result.Healthy = true
+			return result
+		default:
+			result.Error = fmt.Sprintf("unexpected status code %d", status)
+		}
+		oktetoLog.Infof("endpoint '%s' is not healthy: %s", url, result.Error)
+
+		select {
+		case <-ctx.Done():
+			return result
+		case <-time.After(checkRetryInterval):
+		}
+	}
+}
+
+func requestEndpoint(ctx context.Context, url string, client *http.Client) (int, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, checkRequestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		oktetoLog.Infof("could not read the response of '%s': %s", url, err)
+	}
+	return resp.StatusCode, nil
+}
+
+func isHealthyStatus(status, expectedStatus int) bool {
+	if expectedStatus != 0 {
+		return status == expectedStatus
+	}
+	return status < http.StatusBadRequest
+}
+
+func getCheckURL(endpoint, path string) string {
+	if path == "" {
+		return endpoint
+	}
+	return fmt.Sprintf("%s/%s", strings.TrimSuffix(endpoint, "/"), strings.TrimPrefix(path, "/"))
+}
+
+func showEndpointChecks(results []EndpointCheck, output string) error {
+	switch output {
+	case "json":
+		bytes, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		oktetoLog.Println(string(bytes))
+	case "md":
+		oktetoLog.Printf("| Endpoint | Status | Latency | Attempts |\n")
+		oktetoLog.Printf("| --- | --- | --- | --- |\n")
+		for _, r := range results {
+			oktetoLog.Printf("| %s | %s | %dms | %d |\n", r.URL, getCheckStatus(r), r.LatencyMs, r.Attempts)
+		}
+	default:
+		w := tabwriter.NewWriter(os.Stdout, 1, 1, 2, ' ', 0)
+		fmt.Fprintf(w, "Endpoint\tStatus\tLatency\tAttempts\n")
+		for _, r := range results {
+			fmt.Fprintf(w, "%s\t%s\t%dms\t%d\n", r.URL, getCheckStatus(r), r.LatencyMs, r.Attempts)
+		}
+		w.Flush()
+	}
+	return nil
+}
+
+func getCheckStatus(r EndpointCheck) string {
+	if r.Healthy {
+		return fmt.Sprintf("healthy (%d)", r.StatusCode)
+	}
+	return fmt.Sprintf("unhealthy: %s", r.Error)
+}
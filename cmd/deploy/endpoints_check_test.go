@@ -0,0 +1,97 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_getCheckURL(t *testing.T) {
+	var tests = []struct {
+		name     string
+		endpoint string
+		path     string
+		expected string
+	}{
+		{name: "default path", endpoint: "https://app-ns.okteto.dev", path: "/", expected: "https://app-ns.okteto.dev/"},
+		{name: "endpoint with trailing slash", endpoint: "https://app-ns.okteto.dev/", path: "/healthz", expected: "https://app-ns.okteto.dev/healthz"},
+		{name: "path without slash", endpoint: "https://app-ns.okteto.dev/api", path: "healthz", expected: "https://app-ns.okteto.dev/api/healthz"},
+		{name: "empty path", endpoint: "https://app-ns.okteto.dev/api", path: "", expected: "https://app-ns.okteto.dev/api"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, getCheckURL(tt.endpoint, tt.path))
+		})
+	}
+}
+
+func Test_isHealthyStatus(t *testing.T) {
+	assert.True(t, isHealthyStatus(http.StatusOK, 0))
+	assert.True(t, isHealthyStatus(http.StatusFound, 0))
+	assert.False(t, isHealthyStatus(http.StatusNotFound, 0))
+	assert.False(t, isHealthyStatus(http.StatusOK, http.StatusUnauthorized))
+	assert.True(t, isHealthyStatus(http.StatusUnauthorized, http.StatusUnauthorized))
+}
+
+func Test_runEndpointChecks(t *testing.T) {
+	checkRetryInterval = 10 * time.Millisecond
+	defer func() { checkRetryInterval = 3 * time.Second }()
+
+	var requests int32
+	eventually := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if atomic.AddInt32(&requests, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer eventually.Close()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	opts := &EndpointCheckOptions{Path: "/healthz", Timeout: time.Second}
+
+	results := getEndpointChecks(context.Background(), []string{eventually.URL, failing.URL}, opts, eventually.Client())
+	assert.Len(t, results, 2)
+	assert.True(t, results[0].Healthy)
+	assert.Equal(t, http.StatusOK, results[0].StatusCode)
+	assert.Equal(t, 3, results[0].Attempts)
+	assert.Empty(t, results[0].Error)
+	assert.False(t, results[1].Healthy)
+	assert.Equal(t, http.StatusInternalServerError, results[1].StatusCode)
+	assert.Greater(t, results[1].Attempts, 1)
+	assert.Equal(t, "unexpected status code 500", results[1].Error)
+
+	err := runEndpointChecks(context.Background(), []string{eventually.URL}, opts, "json", eventually.Client())
+	assert.NoError(t, err)
+
+	opts.Timeout = 50 * time.Millisecond
+	err = runEndpointChecks(context.Background(), []string{eventually.URL, failing.URL}, opts, "json", failing.Client())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "1 of 2 endpoints are not healthy")
+}
@@ -0,0 +1,147 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/okteto/okteto/pkg/cmd/helm"
+	"github.com/okteto/okteto/pkg/cmd/kustomize"
+	"github.com/okteto/okteto/pkg/k8s/kubeconfig"
+	oktetoLog "github.com/okteto/okteto/pkg/log"
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/okteto/okteto/pkg/okteto"
+	"k8s.io/client-go/dynamic"
+)
+
+func (dc *DeployCommand) deployHelm(ctx context.Context, opts *Options) error {
+	spec, err := expandHelmDeploy(opts.Manifest.Deploy.Helm, opts.Name)
+	if err != nil {
+		return err
+	}
+	if opts.DryRun {
+		spec.Wait = false
+	}
+
+	// the release is deployed through the proxy to label its resources
+	_, cfg, err := dc.K8sClientProvider.Provide(kubeconfig.Get([]string{dc.TempKubeconfigFile}))
+	if err != nil {
+		return err
+	}
+	namespace := okteto.Context().Namespace
+	helmOpts := &helm.Options{
+		Release:   spec.Release,
+		Namespace: namespace,
+		Timeout:   opts.Timeout,
+	}
+	oktetoLog.Spinner(fmt.Sprintf("Deploying helm release '%s'...", spec.Release))
+	oktetoLog.StartSpinner()
+	defer oktetoLog.StopSpinner()
+	if _, err := helm.Deploy(ctx, spec, helmOpts, kubeconfig.NewRESTClientGetter(cfg, namespace)); err != nil {
+		return err
+	}
+	oktetoLog.Success("Helm release '%s' successfully deployed", spec.Release)
+	return nil
+}
+
+func (dc *DeployCommand) deployKustomize(ctx context.Context, opts *Options) error {
+	path, err := model.ExpandEnv(opts.Manifest.Deploy.Kustomize.Path, false)
+	if err != nil {
+		return err
+	}
+	objs, err := kustomize.Build(path, getKustomizeImages(opts.Manifest.Build, os.LookupEnv))
+	if err != nil {
+		return err
+	}
+
+	// the resources are applied through the proxy to label them
+	_, cfg, err := dc.K8sClientProvider.Provide(kubeconfig.Get([]string{dc.TempKubeconfigFile}))
+	if err != nil {
+		return err
+	}
+	namespace := okteto.Context().Namespace
+	dynClient, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+	mapper, err := kubeconfig.NewRESTClientGetter(cfg, namespace).ToRESTMapper()
+	if err != nil {
+		return err
+	}
+	oktetoLog.Spinner(fmt.Sprintf("Applying kustomization '%s'...", path))
+	oktetoLog.StartSpinner()
+	defer oktetoLog.StopSpinner()
+	if err := kustomize.Apply(ctx, objs, namespace, dynClient, mapper); err != nil {
+		return err
+	}
+	oktetoLog.Success("Kustomization '%s' successfully applied", path)
+	return nil
+}
+
+// expandHelmDeploy returns a copy of the helm section with the environment variables expanded
+func expandHelmDeploy(spec *model.HelmDeploy, name string) (*model.HelmDeploy, error) {
+	result := &model.HelmDeploy{
+		Wait:        spec.Wait,
+		ValuesFiles: []string{},
+		Set:         map[string]string{},
+	}
+	var err error
+	fields := []struct {
+		value  string
+		result *string
+	}{
+		{value: spec.Chart, result: &result.Chart},
+		{value: spec.Release, result: &result.Release},
+		{value: spec.Repository, result: &result.Repository},
+		{value: spec.Version, result: &result.Version},
+	}
+	for _, f := range fields {
+		if *f.result, err = model.ExpandEnv(f.value, false); err != nil {
+			return nil, err
+		}
+	}
+	for _, file := range spec.ValuesFiles {
+		expanded, err := model.ExpandEnv(file, false)
+		if err != nil {
+			return nil, err
+		}
+		result.ValuesFiles = append(result.ValuesFiles, expanded)
+	}
+	for k, v := range spec.Set {
+		expanded, err := model.ExpandEnv(v, false)
+		if err != nil {
+			return nil, err
+		}
+		result.Set[k] = expanded
+	}
+	if result.Release == "" {
+		result.Release = name
+	}
+	return result, nil
+}
+
+// getKustomizeImages returns the images built by okteto indexed by the name of their service in the build section
+func getKustomizeImages(build model.ManifestBuild, lookupEnv func(string) (string, bool)) map[string]string {
+	result := map[string]string{}
+	for service := range build {
+		key := fmt.Sprintf("OKTETO_BUILD_%s_IMAGE", strings.ToUpper(strings.ReplaceAll(service, "-", "_")))
+		if image, ok := lookupEnv(key); ok && image != "" {
+			result[service] = image
+		}
+	}
+	return result
+}
@@ -0,0 +1,70 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"testing"
+
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_expandHelmDeploy(t *testing.T) {
+	t.Setenv("CHART_VERSION", "1.2.0")
+	t.Setenv("OKTETO_BUILD_API_IMAGE", "okteto.dev/api:okteto")
+
+	spec := &model.HelmDeploy{
+		Chart:       "./chart",
+		Version:     "${CHART_VERSION}",
+		ValuesFiles: []string{"values-${CHART_VERSION}.yaml"},
+		Set:         map[string]string{"api.image": "${OKTETO_BUILD_API_IMAGE}"},
+		Wait:        true,
+	}
+	result, err := expandHelmDeploy(spec, "movies")
+	assert.NoError(t, err)
+	assert.Equal(t, &model.HelmDeploy{
+		Chart:       "./chart",
+		Release:     "movies",
+		Version:     "1.2.0",
+		ValuesFiles: []string{"values-1.2.0.yaml"},
+		Set:         map[string]string{"api.image": "okteto.dev/api:okteto"},
+		Wait:        true,
+	}, result)
+	assert.Equal(t, "${CHART_VERSION}", spec.Version)
+
+	spec.Release = "api"
+	result, err = expandHelmDeploy(spec, "movies")
+	assert.NoError(t, err)
+	assert.Equal(t, "api", result.Release)
+}
+
+func Test_getKustomizeImages(t *testing.T) {
+	env := map[string]string{
+		"OKTETO_BUILD_API_IMAGE":       "okteto.dev/api:okteto",
+		"OKTETO_BUILD_FRONT_END_IMAGE": "okteto.dev/front-end:okteto",
+	}
+	lookupEnv := func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+	build := model.ManifestBuild{
+		"api":       &model.BuildInfo{},
+		"front-end": &model.BuildInfo{},
+		"worker":    &model.BuildInfo{},
+	}
+	assert.Equal(t, map[string]string{
+		"api":       "okteto.dev/api:okteto",
+		"front-end": "okteto.dev/front-end:okteto",
+	}, getKustomizeImages(build, lookupEnv))
+}
@@ -0,0 +1,283 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	contextCMD "github.com/okteto/okteto/cmd/context"
+	"github.com/okteto/okteto/cmd/utils"
+	"github.com/okteto/okteto/pkg/cmd/pipeline"
+	oktetoErrors "github.com/okteto/okteto/pkg/errors"
+	"github.com/okteto/okteto/pkg/k8s/configmaps"
+	oktetoLog "github.com/okteto/okteto/pkg/log"
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/okteto/okteto/pkg/okteto"
+	"github.com/spf13/cobra"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	commandSucceeded = "success"
+	commandFailed    = "error"
+)
+
+// HistoryOptions defines the options to show the deploy history
+type HistoryOptions struct {
+	Name       string
+	Namespace  string
+	K8sContext string
+	Output     string
+}
+
+// History shows the latest deploys of a development environment
+func History(ctx context.Context) *cobra.Command {
+	options := &HistoryOptions{}
+	cmd := &cobra.Command{
+		Use:   "history <name>",
+		Short: "Show the latest deploys of a development environment",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateHistoryOutput(options.Output); err != nil {
+				return err
+			}
+			options.Name = args[0]
+
+			ctxOptions := &contextCMD.ContextOptions{
+				Context:   options.K8sContext,
+				Namespace: options.Namespace,
+				Show:      options.Output == "",
+			}
+			if err := contextCMD.NewContextCommand().Run(ctx, ctxOptions); err != nil {
+				return err
+			}
+			if options.Namespace == "" {
+				options.Namespace = okteto.Context().Namespace
+			}
+
+			dc := &DeployCommand{
+				K8sClientProvider: okteto.NewK8sClientProvider(),
+			}
+			return dc.showHistory(ctx, options)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "namespace where the development environment is deployed")
+	cmd.Flags().StringVarP(&options.K8sContext, "context", "c", "", "context where the development environment is deployed")
+	cmd.Flags().StringVarP(&options.Output, "output", "o", "", "output format. One of: ['json']")
+	return cmd
+}
+
+func validateHistoryOutput(output string) error {
+	switch output {
+	case "", "json":
+		return nil
+	default:
+		return fmt.Errorf("output format is not accepted. Value must be one of: ['json']")
+	}
+}
+
+func (dc *DeployCommand) showHistory(ctx context.Context, opts *HistoryOptions) error {
+	c, _, err := dc.K8sClientProvider.Provide(okteto.Context().Cfg)
+	if err != nil {
+		return err
+	}
+	history, err := getHistory(ctx, opts.Name, opts.Namespace, c)
+	if err != nil {
+		return err
+	}
+
+	if opts.Output == "json" {
+		bytes, err := json.MarshalIndent(history, "", "  ")
+		if err != nil {
+			return err
+		}
+		oktetoLog.Println(string(bytes))
+		return nil
+	}
+
+	if len(history) == 0 {
+		oktetoLog.Printf("There are no deploys in the history of '%s'\n", opts.Name)
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 1, 1, 2, ' ', 0)
+	fmt.Fprintf(w, "ID\tStatus\tStarted\tDuration\tUser\tCommit\n")
+	for i := len(history) - 1; i >= 0; i-- {
+		entry := history[i]
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n",
+			entry.ID,
+			entry.Status,
+			entry.StartTime.Local().Format(time.RFC3339),
+			entry.Duration().Round(time.Second),
+			valueOrDash(entry.User),
+			valueOrDash(shortSHA(entry.GitSHA)),
+		)
+	}
+	return w.Flush()
+}
+
+func getHistory(ctx context.Context, name, namespace string, c kubernetes.Interface) (pipeline.History, error) {
+	cmap, err := configmaps.Get(ctx, pipeline.TranslatePipelineName(name), namespace, c)
+	if err != nil {
+		if oktetoErrors.IsNotFound(err) {
+			return nil, oktetoErrors.UserError{
+				E:    fmt.Errorf("development environment '%s' not found in namespace '%s'", name, namespace),
+				Hint: "Run 'okteto deploy' to deploy your development environment",
+			}
+		}
+		return nil, err
+	}
+	return pipeline.GetHistory(cmap)
+}
+
+// historyRecorder collects the information of a deploy stored in the pipeline history
+type historyRecorder struct {
+	startTime   time.Time
+	variables   []string
+	storeValues bool
+	commands    []pipeline.HistoryCommand
+}
+
+func newHistoryRecorder(variables []string, storeValues bool) *historyRecorder {
+	return &historyRecorder{
+		startTime:   time.Now().UTC(),
+		variables:   append([]string{}, variables...),
+		storeValues: storeValues,
+	}
+}
+
+// recordCommand adds a step of the deploy to the history. It's a no-op if the deploy is not recorded
+func (r *historyRecorder) recordCommand(name string, start time.Time, err error) {
+	if r == nil {
+		return
+	}
+	status := commandSucceeded
+	if err != nil {
+		status = commandFailed
+	}
+	r.commands = append(r.commands, pipeline.HistoryCommand{
+		Name:     name,
+		Status:   status,
+		Duration: time.Since(start),
+	})
+}
+
+// addTo appends the deploy to the history stored in the pipeline configmap
+func (r *historyRecorder) addTo(cmap *apiv1.ConfigMap, data *pipeline.CfgData, status string) {
+	if r == nil {
+		return
+	}
+	history, err := pipeline.GetHistory(cmap)
+	if err != nil {
+		oktetoLog.Infof("could not update the deploy history: %s", err)
+		return
+	}
+	data.History = history.Add(pipeline.HistoryEntry{
+		Status:    status,
+		StartTime: r.startTime,
+		EndTime:   time.Now().UTC(),
+		User:      okteto.Context().Username,
+		GitSHA:    os.Getenv(model.OktetoGitCommitEnvVar),
+		Variables: pipeline.NewHistoryVariables(r.variables, r.storeValues),
+		Commands:  r.commands,
+	})
+}
+
+// loadReplayVariables sets the variables of a previous deploy. Variables set by the user take precedence
+func loadReplayVariables(ctx context.Context, opts *Options, c kubernetes.Interface) error {
+	history, err := getHistory(ctx, opts.Name, opts.Manifest.Namespace, c)
+	if err != nil {
+		return err
+	}
+	entry, err := history.Get(opts.Replay)
+	if err != nil {
+		return oktetoErrors.UserError{
+			E:    err,
+			Hint: fmt.Sprintf("Run 'okteto deploy history %s' to list the deploys that can be replayed", opts.Name),
+		}
+	}
+
+	if currentSHA := os.Getenv(model.OktetoGitCommitEnvVar); entry.GitSHA != "" && currentSHA != "" && currentSHA != entry.GitSHA {
+		oktetoLog.Warning("Deploy '%d' was executed from commit '%s' but the current commit is '%s'", entry.ID, shortSHA(entry.GitSHA), shortSHA(currentSHA))
+	}
+
+	variables, err := getReplayVariables(entry, opts.Variables, os.LookupEnv)
+	if err != nil {
+		return err
+	}
+	if err := utils.SetVariables(variables, os.Setenv); err != nil {
+		return err
+	}
+	opts.Variables = variables
+	oktetoLog.Information("Replaying deploy '%d' of '%s'", entry.ID, opts.Name)
+	return nil
+}
+
+// getReplayVariables returns the variables of a deploy overridden by the user variables.
+// Redacted values must be provided by the user or the local environment
+func getReplayVariables(entry *pipeline.HistoryEntry, overrides []string, lookupEnv func(string) (string, bool)) ([]string, error) {
+	userValues := map[string]string{}
+	for _, v := range overrides {
+		kv := strings.SplitN(v, "=", 2)
+		if len(kv) == 2 {
+			userValues[kv[0]] = kv[1]
+		}
+	}
+
+	result := []string{}
+	replayed := map[string]bool{}
+	for _, v := range entry.Variables {
+		replayed[v.Name] = true
+		value, ok := userValues[v.Name]
+		if !ok && v.Redacted {
+			value, ok = lookupEnv(v.Name)
+			if !ok {
+				return nil, oktetoErrors.UserError{
+					E:    fmt.Errorf("the value of variable '%s' is not stored in the deploy history", v.Name),
+					Hint: fmt.Sprintf("Set it with '--var %s=<value>' or as an environment variable. Use '--store-variables' to store the values of the non-secret variables in the deploy history", v.Name),
+				}
+			}
+		}
+		if !ok {
+			value = v.Value
+		}
+		result = append(result, fmt.Sprintf("%s=%s", v.Name, value))
+	}
+	for _, v := range overrides {
+		if !replayed[strings.SplitN(v, "=", 2)[0]] {
+			result = append(result, v)
+		}
+	}
+	return result, nil
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
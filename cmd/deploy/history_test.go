@@ -0,0 +1,113 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/okteto/okteto/pkg/cmd/pipeline"
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_getReplayVariables(t *testing.T) {
+	entry := &pipeline.HistoryEntry{
+		ID: 1,
+		Variables: []pipeline.HistoryVariable{
+			{Name: "NAME", Value: "old"},
+			{Name: "REPLICAS", Value: "2"},
+			{Name: "API_TOKEN", Value: pipeline.RedactedValue, Redacted: true},
+		},
+	}
+	lookupEnv := func(key string) (string, bool) {
+		if key == "API_TOKEN" {
+			return "from-env", true
+		}
+		return "", false
+	}
+
+	result, err := getReplayVariables(entry, []string{"NAME=new", "EXTRA=1"}, lookupEnv)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"NAME=new", "REPLICAS=2", "API_TOKEN=from-env", "EXTRA=1"}, result)
+
+	result, err = getReplayVariables(entry, []string{"API_TOKEN=from-flag"}, lookupEnv)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"NAME=old", "REPLICAS=2", "API_TOKEN=from-flag"}, result)
+
+	noEnv := func(string) (string, bool) { return "", false }
+	_, err = getReplayVariables(entry, nil, noEnv)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "API_TOKEN")
+}
+
+func Test_historyRecorder(t *testing.T) {
+	var nilRecorder *historyRecorder
+	nilRecorder.recordCommand("ignored", time.Now(), nil)
+	nilRecorder.addTo(nil, &pipeline.CfgData{}, pipeline.DeployedStatus)
+
+	t.Setenv(model.OktetoGitCommitEnvVar, "0123456789")
+	r := newHistoryRecorder([]string{"NAME=value", "DB_PASSWORD=secret"}, true)
+	r.recordCommand("first", time.Now(), nil)
+	r.recordCommand("second", time.Now(), errors.New("failed"))
+
+	data := &pipeline.CfgData{}
+	cmap := &apiv1.ConfigMap{Data: map[string]string{
+		"history": `[{"id":4,"status":"deployed","startTime":"2022-01-01T00:00:00Z","endTime":"2022-01-01T00:01:00Z"}]`,
+	}}
+	r.addTo(cmap, data, pipeline.ErrorStatus)
+
+	assert.Len(t, data.History, 2)
+	entry := data.History[1]
+	assert.Equal(t, 5, entry.ID)
+	assert.Equal(t, pipeline.ErrorStatus, entry.Status)
+	assert.Equal(t, "0123456789", entry.GitSHA)
+	assert.Equal(t, []pipeline.HistoryVariable{
+		{Name: "NAME", Value: "value"},
+		{Name: "DB_PASSWORD", Value: pipeline.RedactedValue, Redacted: true},
+	}, entry.Variables)
+	assert.Equal(t, commandSucceeded, entry.Commands[0].Status)
+	assert.Equal(t, commandFailed, entry.Commands[1].Status)
+
+	r = newHistoryRecorder([]string{"NAME=value"}, false)
+	r.addTo(cmap, data, pipeline.DeployedStatus)
+	assert.Equal(t, []pipeline.HistoryVariable{
+		{Name: "NAME", Value: pipeline.RedactedValue, Redacted: true},
+	}, data.History[1].Variables)
+}
+
+func Test_getHistory(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewSimpleClientset(&apiv1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pipeline.TranslatePipelineName("movies"),
+			Namespace: "test",
+		},
+		Data: map[string]string{
+			"history": `[{"id":1,"status":"deployed","startTime":"2022-01-01T00:00:00Z","endTime":"2022-01-01T00:01:00Z"}]`,
+		},
+	})
+
+	history, err := getHistory(ctx, "movies", "test", c)
+	assert.NoError(t, err)
+	assert.Len(t, history, 1)
+
+	_, err = getHistory(ctx, "unknown", "test", c)
+	assert.Error(t, err)
+}
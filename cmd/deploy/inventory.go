@@ -0,0 +1,122 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/okteto/okteto/pkg/cmd/pipeline"
+	"github.com/okteto/okteto/pkg/model"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// inventoryRecorder keeps the objects created and deleted through the proxy
+type inventoryRecorder struct {
+	mu      sync.Mutex
+	created []pipeline.InventoryItem
+	deleted []pipeline.InventoryItem
+}
+
+// record keeps the object created, updated or deleted by a successful request of the deploy 'name'
+func (i *inventoryRecorder) record(method string, rr *resourceRequest, obj *responseObject, statusCode int, name string) {
+	if rr.Subresource != "" || isNamespaceRequest(rr) {
+		return
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	switch {
+	case method == http.MethodDelete && rr.Name != "":
+		item := newInventoryItem(rr, nil)
+		// objects created and deleted by the same deploy are not part of the inventory
+		created := []pipeline.InventoryItem{}
+		for _, c := range i.created {
+			if !c.Matches(item) {
+				created = append(created, c)
+			}
+		}
+		i.created = created
+		i.deleted = append(i.deleted, item)
+	case isInventoryChange(method, statusCode, obj, name):
+		if obj == nil || obj.Kind == "" || obj.Kind == "Status" || obj.Metadata.Name == "" {
+			return
+		}
+		item := newInventoryItem(rr, obj)
+		for idx := range i.created {
+			if i.created[idx].Matches(item) {
+				i.created[idx] = item
+				return
+			}
+		}
+		i.created = append(i.created, item)
+	}
+}
+
+func (i *inventoryRecorder) getChanges() ([]pipeline.InventoryItem, []pipeline.InventoryItem) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	created := make([]pipeline.InventoryItem, len(i.created))
+	copy(created, i.created)
+	deleted := make([]pipeline.InventoryItem, len(i.deleted))
+	copy(deleted, i.deleted)
+	return created, deleted
+}
+
+// isInventoryChange returns if a successful request adds its object to the inventory of the deploy 'name':
+// creations, and updates or patches that create the object (201) or apply it with the deployed-by label of the deploy.
+// Objects created by a previous version of okteto or without an inventory are added when the deploy applies them again
+func isInventoryChange(method string, statusCode int, obj *responseObject, name string) bool {
+	if statusCode < 200 || statusCode >= 300 {
+		return false
+	}
+	switch method {
+	case http.MethodPost:
+		return true
+	case http.MethodPut, http.MethodPatch:
+		if statusCode == http.StatusCreated {
+			return true
+		}
+		return obj != nil && name != "" && obj.Metadata.Labels[model.DeployedByLabel] == name
+	default:
+		return false
+	}
+}
+
+// isNamespaceRequest returns if the request targets a namespace. Namespaces are never part of the inventory
+// because destroying them would also destroy the pipeline configmap
+func isNamespaceRequest(rr *resourceRequest) bool {
+	return rr.Group == "" && rr.Resource == "namespaces"
+}
+
+func newInventoryItem(rr *resourceRequest, obj *responseObject) pipeline.InventoryItem {
+	item := pipeline.InventoryItem{
+		APIVersion: schema.GroupVersion{Group: rr.Group, Version: rr.Version}.String(),
+		Resource:   rr.Resource,
+		Namespace:  rr.Namespace,
+		Name:       rr.Name,
+	}
+	if obj == nil {
+		return item
+	}
+	if obj.APIVersion != "" {
+		item.APIVersion = obj.APIVersion
+	}
+	item.Kind = obj.Kind
+	item.Name = obj.Metadata.Name
+	if obj.Metadata.Namespace != "" {
+		item.Namespace = obj.Metadata.Namespace
+	}
+	item.UID = string(obj.Metadata.UID)
+	return item
+}
@@ -0,0 +1,122 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"strings"
+
+	oktetoLog "github.com/okteto/okteto/pkg/log"
+	"github.com/okteto/okteto/pkg/model"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// podTemplateRule represents where the pod template is embedded in a workload kind
+type podTemplateRule struct {
+	group    string
+	kind     string
+	resource string
+	path     []string
+	// custom is true for the rules defined in the manifest
+	custom bool
+}
+
+// builtinPodTemplateRules are the kubernetes workloads with an embedded pod template
+var builtinPodTemplateRules = []podTemplateRule{
+	{group: "apps", kind: "Deployment", resource: "deployments", path: []string{"spec", "template"}},
+	{group: "apps", kind: "StatefulSet", resource: "statefulsets", path: []string{"spec", "template"}},
+	{group: "apps", kind: "DaemonSet", resource: "daemonsets", path: []string{"spec", "template"}},
+	{group: "apps", kind: "ReplicaSet", resource: "replicasets", path: []string{"spec", "template"}},
+	{group: "", kind: "ReplicationController", resource: "replicationcontrollers", path: []string{"spec", "template"}},
+	{group: "batch", kind: "Job", resource: "jobs", path: []string{"spec", "template"}},
+	{group: "batch", kind: "CronJob", resource: "cronjobs", path: []string{"spec", "jobTemplate", "spec", "template"}},
+}
+
+// podTemplateRegistry keeps the pod template rules of the built-in workloads and the ones defined in the manifest
+type podTemplateRegistry struct {
+	rules []podTemplateRule
+}
+
+// newPodTemplateRegistry returns a registry with the built-in rules and the rules defined in the manifest.
+// Rules defined in the manifest take precedence over the built-in ones
+func newPodTemplateRegistry(custom []model.PodTemplateRule) *podTemplateRegistry {
+	r := &podTemplateRegistry{}
+	for _, c := range custom {
+		gv, err := schema.ParseGroupVersion(c.APIVersion)
+		if err != nil {
+			oktetoLog.Infof("ignoring pod template rule for '%s': %s", c.Kind, err)
+			continue
+		}
+		resource := c.Resource
+		if resource == "" {
+			plural, _ := meta.UnsafeGuessKindToResource(gv.WithKind(c.Kind))
+			resource = plural.Resource
+		}
+		r.rules = append(r.rules, podTemplateRule{
+			group:    gv.Group,
+			kind:     c.Kind,
+			resource: resource,
+			path:     strings.Split(strings.Trim(c.Path, "."), "."),
+			custom:   true,
+		})
+	}
+	r.rules = append(r.rules, builtinPodTemplateRules...)
+	return r
+}
+
+// getPathByKind returns the path of the pod template of a kind, or nil if the kind doesn't embed a pod template
+func (r *podTemplateRegistry) getPathByKind(apiVersion, kind string) []string {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return nil
+	}
+	for _, rule := range r.getRules() {
+		if rule.group == gv.Group && rule.kind == kind {
+			return rule.path
+		}
+	}
+	return nil
+}
+
+// getCustomPathByKind returns the path of the pod template of a kind defined in the manifest, or nil if the manifest doesn't define it.
+// It's checked before translating the built-in workloads so the manifest rules take precedence
+func (r *podTemplateRegistry) getCustomPathByKind(apiVersion, kind string) []string {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return nil
+	}
+	for _, rule := range r.getRules() {
+		if rule.custom && rule.group == gv.Group && rule.kind == kind {
+			return rule.path
+		}
+	}
+	return nil
+}
+
+// getPathByResource returns the path of the pod template of a resource, or nil if the resource doesn't embed a pod template
+func (r *podTemplateRegistry) getPathByResource(group, resource string) []string {
+	for _, rule := range r.getRules() {
+		if rule.group == group && rule.resource == resource {
+			return rule.path
+		}
+	}
+	return nil
+}
+
+func (r *podTemplateRegistry) getRules() []podTemplateRule {
+	if r == nil {
+		return builtinPodTemplateRules
+	}
+	return r.rules
+}
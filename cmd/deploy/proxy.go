@@ -27,9 +27,11 @@ import (
 	"os/signal"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/okteto/okteto/cmd/utils"
+	"github.com/okteto/okteto/pkg/cmd/pipeline"
 	"github.com/okteto/okteto/pkg/k8s/labels"
 	oktetoLog "github.com/okteto/okteto/pkg/log"
 	"github.com/okteto/okteto/pkg/model"
@@ -38,6 +40,8 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	apiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/rest"
 )
 
@@ -48,6 +52,11 @@ type proxyInterface interface {
 	GetToken() string
 	SetName(name string)
 	SetDivert(divertedNamespace string)
+	SetDryRun(dryRun bool)
+	GetDryRunResources() []DryRunResource
+	GetAuditEntries() []AuditEntry
+	GetInventoryChanges() ([]pipeline.InventoryItem, []pipeline.InventoryItem)
+	SetPodTemplates(rules []model.PodTemplateRule)
 }
 
 type proxyConfig struct {
@@ -65,6 +74,13 @@ type Proxy struct {
 type proxyHandler struct {
 	Name              string
 	DivertedNamespace string
+	// DryRun sends the mutating requests with dryRun=All so nothing is persisted in the cluster
+	DryRun bool
+
+	dryRun       dryRunRecorder
+	audit        auditRecorder
+	inventory    inventoryRecorder
+	podTemplates *podTemplateRegistry
 }
 
 // NewProxy creates a new proxy
@@ -175,6 +191,31 @@ func (p *Proxy) SetDivert(divertedNamespace string) {
 	p.proxyHandler.SetDivert(divertedNamespace)
 }
 
+// SetDryRun sets if the mutating requests must be validated by the API server without being persisted
+func (p *Proxy) SetDryRun(dryRun bool) {
+	p.proxyHandler.SetDryRun(dryRun)
+}
+
+// GetDryRunResources returns the resources that would have been modified by the requests done in dry-run mode
+func (p *Proxy) GetDryRunResources() []DryRunResource {
+	return p.proxyHandler.dryRun.getResources()
+}
+
+// SetPodTemplates sets the rules to find the pod template of custom workloads
+func (p *Proxy) SetPodTemplates(rules []model.PodTemplateRule) {
+	p.proxyHandler.SetPodTemplates(rules)
+}
+
+// GetAuditEntries returns the mutating requests done through the proxy
+func (p *Proxy) GetAuditEntries() []AuditEntry {
+	return p.proxyHandler.audit.getEntries()
+}
+
+// GetInventoryChanges returns the objects created and deleted through the proxy
+func (p *Proxy) GetInventoryChanges() ([]pipeline.InventoryItem, []pipeline.InventoryItem) {
+	return p.proxyHandler.inventory.getChanges()
+}
+
 func (ph *proxyHandler) getProxyHandler(token string, clusterConfig *rest.Config) (http.Handler, error) {
 	// By default we don't disable HTTP/2
 	trans, err := newProtocolTransport(clusterConfig, false)
@@ -225,31 +266,60 @@ func (ph *proxyHandler) getProxyHandler(token string, clusterConfig *rest.Config
 			reverseProxy.Transport = t
 		}
 
+		var rr *resourceRequest
+		if isMutating(r.Method) {
+			rr = parseResourceRequest(r.URL.Path)
+			if ph.DryRun {
+				if rr != nil && rr.isConnect() {
+					writeDryRunForbidden(rw, rr)
+					return
+				}
+				setDryRun(r)
+			}
+		}
+
 		// Modify all resources updated or created to include the label.
 		if r.Method == "PUT" || r.Method == "POST" {
 			isCreation := r.Method == "POST"
-			b, err := io.ReadAll(r.Body)
-			if err != nil {
-				oktetoLog.Infof("could not read the request body: %s", err)
-				rw.WriteHeader(500)
-				return
-			}
-			defer r.Body.Close()
-			if len(b) == 0 {
-				reverseProxy.ServeHTTP(rw, r)
+			if !rewriteBody(rw, r, func(b []byte) ([]byte, error) {
+				return ph.translateBody(b, isCreation)
+			}) {
 				return
 			}
+		}
 
-			b, err = ph.translateBody(b, isCreation)
-			if err != nil {
-				oktetoLog.Info(err)
-				rw.WriteHeader(500)
+		// Modify all resources patched to include the label. Patches to subresources don't modify the resource metadata
+		if r.Method == http.MethodPatch && rr != nil && rr.Subresource == "" {
+			patchType := getPatchType(r)
+			if !rewriteBody(rw, r, func(b []byte) ([]byte, error) {
+				return ph.translatePatch(b, rr, patchType)
+			}) {
 				return
 			}
+		}
 
-			// Needed to set the new Content-Length
-			r.ContentLength = int64(len(b))
-			r.Body = io.NopCloser(bytes.NewBuffer(b))
+		// Keep track of the mutations done to the cluster
+		if rr != nil && !rr.isReview() && !rr.isConnect() {
+			start := time.Now()
+			rec := newResponseRecorder(rw)
+			reverseProxy.ServeHTTP(rec, r)
+			obj := rec.getObject()
+			entry := newAuditEntry(r.Method, rr, obj, rec.status, start)
+			entry.DryRun = ph.DryRun
+			ph.audit.record(entry)
+			if rec.isSuccess() && rr.Subresource == "" {
+				oktetoLog.ResourceApplied(getResourceAppliedData(entry))
+			}
+			if ph.DryRun && rec.isSuccess() {
+				ph.dryRun.record(newDryRunResource(r.Method, rr, obj))
+			}
+			if !ph.DryRun && rec.isSuccess() {
+				ph.inventory.record(r.Method, rr, obj, rec.status, ph.Name)
+			}
+			if r.Method == http.MethodPatch && rr.Subresource == "" && rec.isSuccess() && getPatchType(r) == types.JSONPatchType {
+				ph.fixJSONPatchedObject(r.Context(), trans, destinationURL, r, rr, rec.getBody())
+			}
+			return
 		}
 
 		// Redirect request to the k8s server (based on the transport HTTP generated from the config)
@@ -260,6 +330,31 @@ func (ph *proxyHandler) getProxyHandler(token string, clusterConfig *rest.Config
 
 }
 
+// rewriteBody replaces the body of the request with its translation.
+// It returns false if the request has already been answered because of an error
+func rewriteBody(rw http.ResponseWriter, r *http.Request, translate func([]byte) ([]byte, error)) bool {
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		oktetoLog.Infof("could not read the request body: %s", err)
+		rw.WriteHeader(500)
+		return false
+	}
+	defer r.Body.Close()
+	if len(b) != 0 {
+		b, err = translate(b)
+		if err != nil {
+			oktetoLog.Info(err)
+			rw.WriteHeader(500)
+			return false
+		}
+	}
+
+	// Needed to set the new Content-Length
+	r.ContentLength = int64(len(b))
+	r.Body = io.NopCloser(bytes.NewBuffer(b))
+	return true
+}
+
 func (ph *proxyHandler) SetName(name string) {
 	ph.Name = name
 }
@@ -268,6 +363,14 @@ func (ph *proxyHandler) SetDivert(divertedNamespace string) {
 	ph.DivertedNamespace = divertedNamespace
 }
 
+func (ph *proxyHandler) SetDryRun(dryRun bool) {
+	ph.DryRun = dryRun
+}
+
+func (ph *proxyHandler) SetPodTemplates(rules []model.PodTemplateRule) {
+	ph.podTemplates = newPodTemplateRegistry(rules)
+}
+
 func (ph *proxyHandler) translateBody(b []byte, isCreation bool) ([]byte, error) {
 	var body map[string]json.RawMessage
 	if err := json.Unmarshal(b, &body); err != nil {
@@ -285,6 +388,13 @@ func (ph *proxyHandler) translateBody(b []byte, isCreation bool) ([]byte, error)
 		return nil, nil
 	}
 
+	if path := ph.podTemplates.getCustomPathByKind(typeMeta.APIVersion, typeMeta.Kind); path != nil {
+		if err := ph.translateCustomPodTemplate(body, path); err != nil {
+			return nil, err
+		}
+		return json.Marshal(body)
+	}
+
 	switch typeMeta.Kind {
 	case "Deployment":
 		if err := ph.translateDeploymentSpec(body); err != nil {
@@ -314,6 +424,12 @@ func (ph *proxyHandler) translateBody(b []byte, isCreation bool) ([]byte, error)
 		if err := ph.translateReplicaSetSpec(body); err != nil {
 			return nil, err
 		}
+	default:
+		if path := ph.podTemplates.getPathByKind(typeMeta.APIVersion, typeMeta.Kind); path != nil {
+			if err := ph.translateCustomPodTemplate(body, path); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	return json.Marshal(body)
@@ -467,6 +583,42 @@ func (ph *proxyHandler) translateReplicaSetSpec(body map[string]json.RawMessage)
 	return nil
 }
 
+// translateCustomPodTemplate sets the deployed-by label and divert in the pod template of a custom workload
+func (ph *proxyHandler) translateCustomPodTemplate(body map[string]json.RawMessage, path []string) error {
+	field, ok := body[path[0]]
+	if !ok {
+		return nil
+	}
+	parent, err := decodeObject(field)
+	if err != nil {
+		oktetoLog.Infof("error unmarshalling %s on proxy: %s", path[0], err.Error())
+		return nil
+	}
+	template := parent
+	if len(path) > 1 {
+		var found bool
+		template, found, err = unstructured.NestedMap(parent, path[1:]...)
+		if err != nil || !found {
+			return nil
+		}
+	}
+	if err := ph.translatePartialPodTemplate(template, true); err != nil {
+		oktetoLog.Infof("error translating pod template on proxy: %s", err.Error())
+		return nil
+	}
+	if len(path) > 1 {
+		if err := unstructured.SetNestedMap(parent, template, path[1:]...); err != nil {
+			return fmt.Errorf("could not process pod template: %w", err)
+		}
+	}
+	fieldAsByte, err := json.Marshal(parent)
+	if err != nil {
+		return fmt.Errorf("could not process %s: %w", path[0], err)
+	}
+	body[path[0]] = fieldAsByte
+	return nil
+}
+
 func (ph *proxyHandler) applyDivert(podSpec *apiv1.PodSpec) {
 	if ph.DivertedNamespace == "" {
 		return
@@ -0,0 +1,211 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	oktetoLog "github.com/okteto/okteto/pkg/log"
+	"github.com/okteto/okteto/pkg/model"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const contentTypeHeader = "Content-Type"
+
+// getPatchType returns the patch type of a PATCH request based on its content type
+func getPatchType(r *http.Request) types.PatchType {
+	contentType := strings.TrimSpace(strings.Split(r.Header.Get(contentTypeHeader), ";")[0])
+	return types.PatchType(contentType)
+}
+
+// translatePatch modifies the body of a PATCH request to include the deployed-by label and divert configuration.
+// JSON patches can't be safely extended, they are handled once the patch is applied by fixJSONPatchedObject
+func (ph *proxyHandler) translatePatch(b []byte, rr *resourceRequest, patchType types.PatchType) ([]byte, error) {
+	switch patchType {
+	case types.StrategicMergePatchType, types.MergePatchType:
+		return ph.translatePartialObject(b, ph.podTemplates.getPathByResource(rr.Group, rr.Resource), false)
+	case types.ApplyPatchType:
+		// apply configurations are sent as YAML but JSON is also valid YAML
+		jsonBody, err := k8syaml.ToJSON(b)
+		if err != nil {
+			oktetoLog.Infof("error converting apply patch to json on proxy: %s", err.Error())
+			return b, nil
+		}
+		return ph.translatePartialObject(jsonBody, ph.podTemplates.getPathByResource(rr.Group, rr.Resource), true)
+	default:
+		return b, nil
+	}
+}
+
+// translatePartialObject sets the deployed-by label and the divert configuration in a merge patch or an apply configuration.
+// Only fields already present in a merge patch are modified to avoid overwriting lists the patch is not changing
+func (ph *proxyHandler) translatePartialObject(b []byte, templatePath []string, isApply bool) ([]byte, error) {
+	obj, err := decodeObject(b)
+	if err != nil {
+		oktetoLog.Infof("error unmarshalling patch on proxy: %s", err.Error())
+		return b, nil
+	}
+	if obj == nil {
+		return b, nil
+	}
+
+	if err := unstructured.SetNestedField(obj, ph.Name, "metadata", "labels", model.DeployedByLabel); err != nil {
+		oktetoLog.Infof("error setting labels in patch on proxy: %s", err.Error())
+		return b, nil
+	}
+
+	if templatePath != nil {
+		if template, ok, _ := unstructured.NestedMap(obj, templatePath...); ok && template != nil {
+			if err := ph.translatePartialPodTemplate(template, isApply); err != nil {
+				oktetoLog.Infof("error translating pod template in patch on proxy: %s", err.Error())
+				return b, nil
+			}
+			if err := unstructured.SetNestedMap(obj, template, templatePath...); err != nil {
+				return nil, fmt.Errorf("could not process patch's pod template: %w", err)
+			}
+		}
+	}
+
+	result, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("could not process patch: %w", err)
+	}
+	return result, nil
+}
+
+func (ph *proxyHandler) translatePartialPodTemplate(template map[string]interface{}, isApply bool) error {
+	if err := unstructured.SetNestedField(template, ph.Name, "metadata", "labels", model.DeployedByLabel); err != nil {
+		return err
+	}
+	if ph.DivertedNamespace == "" {
+		return nil
+	}
+
+	searches, found, err := unstructured.NestedStringSlice(template, "spec", "dnsConfig", "searches")
+	if err != nil {
+		return err
+	}
+	if !found && !isApply {
+		return nil
+	}
+	if _, hasSpec := template["spec"]; !hasSpec {
+		return nil
+	}
+	return unstructured.SetNestedStringSlice(template, addDivertSearch(searches, ph.DivertedNamespace), "spec", "dnsConfig", "searches")
+}
+
+// decodeObject decodes a JSON object keeping numbers as json.Number so they are not modified when encoded again
+func decodeObject(b []byte) (map[string]interface{}, error) {
+	var obj map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	if err := decoder.Decode(&obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// joinPath returns a new path with the fields appended to the base path
+func joinPath(base []string, fields ...string) []string {
+	result := make([]string, 0, len(base)+len(fields))
+	result = append(result, base...)
+	return append(result, fields...)
+}
+
+// addDivertSearch prepends the search domain of the diverted namespace if it's not already present
+func addDivertSearch(searches []string, divertedNamespace string) []string {
+	divertSearch := fmt.Sprintf("%s.svc.cluster.local", divertedNamespace)
+	for _, s := range searches {
+		if s == divertSearch {
+			return searches
+		}
+	}
+	return append([]string{divertSearch}, searches...)
+}
+
+// getJSONPatchFix returns a merge patch with the labels and divert configuration missing in an object modified by a JSON patch
+func (ph *proxyHandler) getJSONPatchFix(b []byte, rr *resourceRequest) []byte {
+	obj, err := decodeObject(b)
+	if err != nil || obj == nil {
+		return nil
+	}
+
+	fix := map[string]interface{}{}
+	if value, _, _ := unstructured.NestedString(obj, "metadata", "labels", model.DeployedByLabel); value != ph.Name {
+		unstructured.SetNestedField(fix, ph.Name, "metadata", "labels", model.DeployedByLabel)
+	}
+
+	if templatePath := ph.podTemplates.getPathByResource(rr.Group, rr.Resource); templatePath != nil {
+		labelPath := joinPath(templatePath, "metadata", "labels", model.DeployedByLabel)
+		if value, _, _ := unstructured.NestedString(obj, labelPath...); value != ph.Name {
+			unstructured.SetNestedField(fix, ph.Name, labelPath...)
+		}
+		if ph.DivertedNamespace != "" {
+			searchesPath := joinPath(templatePath, "spec", "dnsConfig", "searches")
+			searches, _, _ := unstructured.NestedStringSlice(obj, searchesPath...)
+			if withDivert := addDivertSearch(searches, ph.DivertedNamespace); len(withDivert) != len(searches) {
+				unstructured.SetNestedStringSlice(fix, withDivert, searchesPath...)
+			}
+		}
+	}
+
+	if len(fix) == 0 {
+		return nil
+	}
+	result, err := json.Marshal(fix)
+	if err != nil {
+		return nil
+	}
+	return result
+}
+
+// fixJSONPatchedObject sends a merge patch to add the labels and divert configuration a JSON patch didn't include
+func (ph *proxyHandler) fixJSONPatchedObject(ctx context.Context, trans http.RoundTripper, destinationURL *url.URL, r *http.Request, rr *resourceRequest, object []byte) {
+	fix := ph.getJSONPatchFix(object, rr)
+	if fix == nil {
+		return
+	}
+
+	fixURL := *destinationURL
+	fixURL.Path = r.URL.Path
+	fixURL.RawQuery = r.URL.RawQuery
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, fixURL.String(), bytes.NewReader(fix))
+	if err != nil {
+		oktetoLog.Infof("could not create patch request: %s", err)
+		return
+	}
+	req.Header = r.Header.Clone()
+	req.Header.Set(contentTypeHeader, string(types.MergePatchType))
+	req.Header.Del("Accept-Encoding")
+
+	resp, err := trans.RoundTrip(req)
+	if err != nil {
+		oktetoLog.Infof("could not label resource %s/%s: %s", rr.Resource, rr.Name, err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		oktetoLog.Infof("could not label resource %s/%s: %s", rr.Resource, rr.Name, string(body))
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// resourceRequest represents the kubernetes resource targeted by a request done to the proxy
+type resourceRequest struct {
+	Group       string
+	Version     string
+	Resource    string
+	Subresource string
+	Namespace   string
+	Name        string
+}
+
+// connectSubresources are the subresources that open a connection with a running pod
+var connectSubresources = map[string]bool{
+	"exec":        true,
+	"attach":      true,
+	"portforward": true,
+	"proxy":       true,
+}
+
+// reviewResources are virtual resources that are created to ask the API server but never persisted
+var reviewResources = map[string]bool{
+	"selfsubjectaccessreviews":  true,
+	"selfsubjectrulesreviews":   true,
+	"subjectaccessreviews":      true,
+	"localsubjectaccessreviews": true,
+	"tokenreviews":              true,
+}
+
+// parseResourceRequest parses a kubernetes API path like
+// /api/v1/namespaces/{namespace}/{resource}/{name}/{subresource} or
+// /apis/{group}/{version}/namespaces/{namespace}/{resource}/{name}/{subresource}
+// It returns nil if the path doesn't target a resource
+func parseResourceRequest(path string) *resourceRequest {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	rr := &resourceRequest{}
+	switch {
+	case len(parts) >= 3 && parts[0] == "api":
+		rr.Version = parts[1]
+		parts = parts[2:]
+	case len(parts) >= 4 && parts[0] == "apis":
+		rr.Group = parts[1]
+		rr.Version = parts[2]
+		parts = parts[3:]
+	default:
+		return nil
+	}
+
+	// namespaces are a resource themselves: /api/v1/namespaces/{name}
+	if len(parts) >= 3 && parts[0] == "namespaces" {
+		rr.Namespace = parts[1]
+		parts = parts[2:]
+	}
+
+	rr.Resource = parts[0]
+	if len(parts) > 1 {
+		rr.Name = parts[1]
+	}
+	if len(parts) > 2 {
+		rr.Subresource = parts[2]
+	}
+	return rr
+}
+
+// isMutating returns if the request method modifies resources in the cluster
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// isConnect returns if the request opens a connection to a pod instead of modifying a resource
+func (rr *resourceRequest) isConnect() bool {
+	return connectSubresources[rr.Subresource]
+}
+
+// isReview returns if the request targets a resource that is never persisted
+func (rr *resourceRequest) isReview() bool {
+	return reviewResources[rr.Resource]
+}
+
+// getAction returns the action a mutating method performs over a resource
+func getAction(method string) string {
+	switch method {
+	case http.MethodPost:
+		return "create"
+	case http.MethodPut:
+		return "update"
+	case http.MethodPatch:
+		return "patch"
+	case http.MethodDelete:
+		return "delete"
+	default:
+		return strings.ToLower(method)
+	}
+}
+
+// responseObject represents the identity of the object returned by the API server
+type responseObject struct {
+	metav1.TypeMeta
+	Metadata metav1.ObjectMeta `json:"metadata"`
+}
+
+// responseRecorder wraps a http.ResponseWriter to keep the status code and body returned by the API server
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func newResponseRecorder(rw http.ResponseWriter) *responseRecorder {
+	return &responseRecorder{
+		ResponseWriter: rw,
+		status:         http.StatusOK,
+	}
+}
+
+// WriteHeader keeps the status code before writing it
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write keeps a copy of the body before writing it
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher so the reverse proxy can stream responses
+func (r *responseRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker for upgraded connections
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	return h.Hijack()
+}
+
+// isSuccess returns if the API server accepted the request
+func (r *responseRecorder) isSuccess() bool {
+	return r.status >= 200 && r.status < 300
+}
+
+// getBody returns the decoded body returned by the API server
+func (r *responseRecorder) getBody() []byte {
+	b := r.body.Bytes()
+	if r.Header().Get("Content-Encoding") != "gzip" {
+		return b
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil
+	}
+	defer gz.Close()
+	b, err = io.ReadAll(gz)
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+// getObject returns the identity of the object returned by the API server, if any
+func (r *responseRecorder) getObject() *responseObject {
+	obj := &responseObject{}
+	if err := json.Unmarshal(r.getBody(), obj); err != nil {
+		return nil
+	}
+	return obj
+}
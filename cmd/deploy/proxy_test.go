@@ -2,9 +2,19 @@ package deploy
 
 import (
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 
+	"github.com/okteto/okteto/pkg/cmd/pipeline"
+	"github.com/okteto/okteto/pkg/model"
 	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
 )
 
 var (
@@ -77,3 +87,317 @@ func Test_TranslateInvalidResourceSpec(t *testing.T) {
 		"spec": []byte(`{"schedule": 1}`),
 	}))
 }
+
+func Test_parseResourceRequest(t *testing.T) {
+	var tests = []struct {
+		name     string
+		path     string
+		expected *resourceRequest
+	}{
+		{
+			name:     "non resource path",
+			path:     "/version",
+			expected: nil,
+		},
+		{
+			name: "core namespaced collection",
+			path: "/api/v1/namespaces/test/secrets",
+			expected: &resourceRequest{
+				Version:   "v1",
+				Resource:  "secrets",
+				Namespace: "test",
+			},
+		},
+		{
+			name: "namespace",
+			path: "/api/v1/namespaces/test",
+			expected: &resourceRequest{
+				Version:  "v1",
+				Resource: "namespaces",
+				Name:     "test",
+			},
+		},
+		{
+			name: "group namespaced resource",
+			path: "/apis/apps/v1/namespaces/test/deployments/api",
+			expected: &resourceRequest{
+				Group:     "apps",
+				Version:   "v1",
+				Resource:  "deployments",
+				Namespace: "test",
+				Name:      "api",
+			},
+		},
+		{
+			name: "subresource",
+			path: "/api/v1/namespaces/test/pods/api-123/exec",
+			expected: &resourceRequest{
+				Version:     "v1",
+				Resource:    "pods",
+				Namespace:   "test",
+				Name:        "api-123",
+				Subresource: "exec",
+			},
+		},
+		{
+			name: "cluster scoped resource",
+			path: "/apis/rbac.authorization.k8s.io/v1/clusterroles/reader",
+			expected: &resourceRequest{
+				Group:    "rbac.authorization.k8s.io",
+				Version:  "v1",
+				Resource: "clusterroles",
+				Name:     "reader",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseResourceRequest(tt.path))
+		})
+	}
+}
+
+func Test_DryRunProxyHandler(t *testing.T) {
+	var receivedQuery url.Values
+	cluster := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		receivedQuery = r.URL.Query()
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(http.StatusCreated)
+		rw.Write([]byte(`{"kind":"ConfigMap","apiVersion":"v1","metadata":{"name":"cfg","namespace":"test"}}`))
+	}))
+	defer cluster.Close()
+
+	dryRunHandler := &proxyHandler{Name: "movies", DryRun: true}
+	handler, err := dryRunHandler.getProxyHandler("token", &rest.Config{
+		Host:            cluster.URL,
+		TLSClientConfig: rest.TLSClientConfig{Insecure: true},
+	})
+	assert.NoError(t, err)
+
+	body := `{"kind":"ConfigMap","apiVersion":"v1","metadata":{"name":"cfg"}}`
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/namespaces/test/configmaps", strings.NewReader(body))
+	r.Header.Set("Authorization", "Bearer token")
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, r)
+	assert.Equal(t, http.StatusCreated, rw.Code)
+	assert.Equal(t, "All", receivedQuery.Get(dryRunQueryParam))
+
+	r = httptest.NewRequest(http.MethodPost, "/api/v1/namespaces/test/pods/api/exec", nil)
+	r.Header.Set("Authorization", "Bearer token")
+	rw = httptest.NewRecorder()
+	handler.ServeHTTP(rw, r)
+	assert.Equal(t, http.StatusForbidden, rw.Code)
+
+	expected := []DryRunResource{
+		{
+			Action:    "create",
+			Kind:      "ConfigMap",
+			Namespace: "test",
+			Name:      "cfg",
+		},
+	}
+	assert.Equal(t, expected, dryRunHandler.dryRun.getResources())
+
+	// the exec request is not a mutation and it's rejected before reaching the cluster
+	auditEntries := dryRunHandler.audit.getEntries()
+	assert.Len(t, auditEntries, 1)
+	assert.Equal(t, "ConfigMap", auditEntries[0].Kind)
+	assert.Equal(t, http.StatusCreated, auditEntries[0].StatusCode)
+	assert.True(t, auditEntries[0].DryRun)
+}
+
+func Test_translatePatch(t *testing.T) {
+	divertHandler := &proxyHandler{Name: "movies", DivertedNamespace: "staging"}
+	var tests = []struct {
+		name      string
+		body      string
+		group     string
+		resource  string
+		patchType types.PatchType
+		expected  string
+	}{
+		{
+			name:      "strategic merge patch without pod template",
+			body:      `{"spec":{"replicas":2}}`,
+			group:     "apps",
+			resource:  "deployments",
+			patchType: types.StrategicMergePatchType,
+			expected:  `{"metadata":{"labels":{"dev.okteto.com/deployed-by":"movies"}},"spec":{"replicas":2}}`,
+		},
+		{
+			name:      "strategic merge patch with pod template",
+			body:      `{"spec":{"template":{"spec":{"containers":[{"name":"api","image":"api:2"}]}}}}`,
+			group:     "apps",
+			resource:  "deployments",
+			patchType: types.StrategicMergePatchType,
+			expected:  `{"metadata":{"labels":{"dev.okteto.com/deployed-by":"movies"}},"spec":{"template":{"metadata":{"labels":{"dev.okteto.com/deployed-by":"movies"}},"spec":{"containers":[{"image":"api:2","name":"api"}]}}}}`,
+		},
+		{
+			name:      "merge patch with dns searches",
+			body:      `{"spec":{"jobTemplate":{"spec":{"template":{"spec":{"dnsConfig":{"searches":["custom"]}}}}}}}`,
+			group:     "batch",
+			resource:  "cronjobs",
+			patchType: types.MergePatchType,
+			expected:  `{"metadata":{"labels":{"dev.okteto.com/deployed-by":"movies"}},"spec":{"jobTemplate":{"spec":{"template":{"metadata":{"labels":{"dev.okteto.com/deployed-by":"movies"}},"spec":{"dnsConfig":{"searches":["staging.svc.cluster.local","custom"]}}}}}}}`,
+		},
+		{
+			name:      "apply patch in yaml",
+			body:      "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: api\nspec:\n  replicas: 1\n  template:\n    spec:\n      containers:\n      - name: api\n",
+			group:     "apps",
+			resource:  "deployments",
+			patchType: types.ApplyPatchType,
+			expected:  `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"labels":{"dev.okteto.com/deployed-by":"movies"},"name":"api"},"spec":{"replicas":1,"template":{"metadata":{"labels":{"dev.okteto.com/deployed-by":"movies"}},"spec":{"containers":[{"name":"api"}],"dnsConfig":{"searches":["staging.svc.cluster.local"]}}}}}`,
+		},
+		{
+			name:      "json patch is not modified",
+			body:      `[{"op":"replace","path":"/spec/replicas","value":3}]`,
+			group:     "apps",
+			resource:  "deployments",
+			patchType: types.JSONPatchType,
+			expected:  `[{"op":"replace","path":"/spec/replicas","value":3}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := divertHandler.translatePatch([]byte(tt.body), &resourceRequest{Group: tt.group, Resource: tt.resource}, tt.patchType)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(result))
+		})
+	}
+}
+
+func Test_getJSONPatchFix(t *testing.T) {
+	divertHandler := &proxyHandler{Name: "movies", DivertedNamespace: "staging"}
+	rr := &resourceRequest{Group: "apps", Resource: "deployments"}
+
+	labeled := `{"metadata":{"labels":{"dev.okteto.com/deployed-by":"movies"}},"spec":{"template":{"metadata":{"labels":{"dev.okteto.com/deployed-by":"movies"}},"spec":{"dnsConfig":{"searches":["staging.svc.cluster.local"]}}}}}`
+	assert.Nil(t, divertHandler.getJSONPatchFix([]byte(labeled), rr))
+
+	unlabeled := `{"metadata":{"name":"api"},"spec":{"template":{"spec":{"containers":[]}}}}`
+	expected := `{"metadata":{"labels":{"dev.okteto.com/deployed-by":"movies"}},"spec":{"template":{"metadata":{"labels":{"dev.okteto.com/deployed-by":"movies"}},"spec":{"dnsConfig":{"searches":["staging.svc.cluster.local"]}}}}}`
+	assert.Equal(t, expected, string(divertHandler.getJSONPatchFix([]byte(unlabeled), rr)))
+}
+
+func Test_TranslateCustomPodTemplate(t *testing.T) {
+	customHandler := &proxyHandler{Name: "movies", DivertedNamespace: "staging"}
+	customHandler.SetPodTemplates([]model.PodTemplateRule{
+		{
+			APIVersion: "keda.sh/v1alpha1",
+			Kind:       "ScaledJob",
+			Path:       "spec.jobTargetRef.template",
+		},
+	})
+
+	body := `{"apiVersion":"keda.sh/v1alpha1","kind":"ScaledJob","metadata":{"name":"worker"},"spec":{"jobTargetRef":{"template":{"spec":{"containers":[{"name":"worker"}]}}}}}`
+	result, err := customHandler.translateBody([]byte(body), true)
+	assert.NoError(t, err)
+
+	var obj map[string]interface{}
+	assert.NoError(t, json.Unmarshal(result, &obj))
+	label, _, _ := unstructured.NestedString(obj, "spec", "jobTargetRef", "template", "metadata", "labels", model.DeployedByLabel)
+	assert.Equal(t, "movies", label)
+	searches, _, _ := unstructured.NestedStringSlice(obj, "spec", "jobTargetRef", "template", "spec", "dnsConfig", "searches")
+	assert.Equal(t, []string{"staging.svc.cluster.local"}, searches)
+
+	// patches are matched by the resource guessed from the kind
+	assert.Equal(t, []string{"spec", "jobTargetRef", "template"}, customHandler.podTemplates.getPathByResource("keda.sh", "scaledjobs"))
+	assert.Nil(t, customHandler.podTemplates.getPathByResource("serving.knative.dev", "services"))
+}
+
+func Test_TranslateCustomPodTemplateTopLevel(t *testing.T) {
+	customHandler := &proxyHandler{Name: "movies"}
+	customHandler.SetPodTemplates([]model.PodTemplateRule{
+		{
+			APIVersion: "example.com/v1",
+			Kind:       "Worker",
+			Path:       "template",
+		},
+	})
+
+	body := `{"apiVersion":"example.com/v1","kind":"Worker","metadata":{"name":"worker"},"template":{"spec":{"activeDeadlineSeconds":9007199254740993,"containers":[{"name":"worker"}]}}}`
+	result, err := customHandler.translateBody([]byte(body), true)
+	assert.NoError(t, err)
+
+	obj, err := decodeObject(result)
+	assert.NoError(t, err)
+	label, _, _ := unstructured.NestedString(obj, "template", "metadata", "labels", model.DeployedByLabel)
+	assert.Equal(t, "movies", label)
+	// numbers are not rounded
+	assert.Contains(t, string(result), `"activeDeadlineSeconds":9007199254740993`)
+}
+
+func Test_TranslateCustomPodTemplateTakesPrecedence(t *testing.T) {
+	customHandler := &proxyHandler{Name: "movies"}
+	customHandler.SetPodTemplates([]model.PodTemplateRule{
+		{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+			Path:       "spec.workload.template",
+		},
+	})
+
+	body := `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"api"},"spec":{"workload":{"template":{"spec":{"containers":[{"name":"api"}]}}}}}`
+	result, err := customHandler.translateBody([]byte(body), true)
+	assert.NoError(t, err)
+
+	obj, err := decodeObject(result)
+	assert.NoError(t, err)
+	label, _, _ := unstructured.NestedString(obj, "spec", "workload", "template", "metadata", "labels", model.DeployedByLabel)
+	assert.Equal(t, "movies", label)
+}
+
+func Test_inventoryRecorder(t *testing.T) {
+	deployment := &responseObject{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		Metadata: metav1.ObjectMeta{Name: "api", Namespace: "test", UID: "1"},
+	}
+	clusterRole := &responseObject{
+		TypeMeta: metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole"},
+		Metadata: metav1.ObjectMeta{Name: "api", UID: "2"},
+	}
+	job := &responseObject{
+		TypeMeta: metav1.TypeMeta{APIVersion: "batch/v1", Kind: "Job"},
+		Metadata: metav1.ObjectMeta{Name: "migrate", Namespace: "test", UID: "3"},
+	}
+	service := &responseObject{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+		Metadata: metav1.ObjectMeta{Name: "api", Namespace: "test", UID: "4", Labels: map[string]string{model.DeployedByLabel: "movies"}},
+	}
+	otherService := &responseObject{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+		Metadata: metav1.ObjectMeta{Name: "other", Namespace: "test", UID: "5", Labels: map[string]string{model.DeployedByLabel: "other"}},
+	}
+	status := &responseObject{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Status"},
+	}
+
+	i := &inventoryRecorder{}
+	i.record(http.MethodPost, parseResourceRequest("/apis/apps/v1/namespaces/test/deployments"), deployment, http.StatusCreated, "movies")
+	i.record(http.MethodPatch, parseResourceRequest("/apis/rbac.authorization.k8s.io/v1/clusterroles/api"), clusterRole, http.StatusCreated, "movies")
+	i.record(http.MethodPut, parseResourceRequest("/apis/apps/v1/namespaces/test/deployments/api"), deployment, http.StatusOK, "movies")
+	i.record(http.MethodPost, parseResourceRequest("/api/v1/namespaces"), &responseObject{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"}, Metadata: metav1.ObjectMeta{Name: "test"}}, http.StatusCreated, "movies")
+	i.record(http.MethodPost, parseResourceRequest("/api/v1/namespaces/test/pods/api/eviction"), status, http.StatusCreated, "movies")
+	i.record(http.MethodPost, parseResourceRequest("/apis/batch/v1/namespaces/test/jobs"), job, http.StatusCreated, "movies")
+	i.record(http.MethodDelete, parseResourceRequest("/apis/batch/v1/namespaces/test/jobs/migrate"), status, http.StatusOK, "movies")
+	i.record(http.MethodDelete, parseResourceRequest("/api/v1/namespaces/test/services/old"), status, http.StatusOK, "movies")
+
+	i.record(http.MethodPatch, parseResourceRequest("/api/v1/namespaces/test/services/api"), service, http.StatusOK, "movies")
+	i.record(http.MethodPut, parseResourceRequest("/api/v1/namespaces/test/services/api"), service, http.StatusOK, "movies")
+	i.record(http.MethodPatch, parseResourceRequest("/api/v1/namespaces/test/services/other"), otherService, http.StatusOK, "movies")
+	i.record(http.MethodPatch, parseResourceRequest("/api/v1/namespaces/test/services/api"), service, http.StatusConflict, "movies")
+
+	created, deleted := i.getChanges()
+	assert.Equal(t, []pipeline.InventoryItem{
+		{APIVersion: "apps/v1", Kind: "Deployment", Resource: "deployments", Namespace: "test", Name: "api", UID: "1"},
+		{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole", Resource: "clusterroles", Name: "api", UID: "2"},
+		{APIVersion: "v1", Kind: "Service", Resource: "services", Namespace: "test", Name: "api", UID: "4"},
+	}, created)
+	assert.Equal(t, []pipeline.InventoryItem{
+		{APIVersion: "batch/v1", Resource: "jobs", Namespace: "test", Name: "migrate"},
+		{APIVersion: "v1", Resource: "services", Namespace: "test", Name: "old"},
+	}, deleted)
+}
@@ -0,0 +1,171 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// readinessCheck returns if an object is ready or a description of what is pending.
+// It returns an error if the object will never be ready
+type readinessCheck func(obj runtime.Object) (bool, string, error)
+
+func isDeploymentReady(obj runtime.Object) (bool, string, error) {
+	d, ok := obj.(*appsv1.Deployment)
+	if !ok {
+		return false, "", fmt.Errorf("unexpected object %T", obj)
+	}
+	if d.Spec.Replicas != nil && *d.Spec.Replicas == 0 {
+		return true, "", nil
+	}
+	if d.Status.ReadyReplicas > 0 {
+		return true, "", nil
+	}
+	return false, fmt.Sprintf("%d/%d replicas ready", d.Status.ReadyReplicas, getReplicas(d.Spec.Replicas)), nil
+}
+
+func isStatefulSetReady(obj runtime.Object) (bool, string, error) {
+	sfs, ok := obj.(*appsv1.StatefulSet)
+	if !ok {
+		return false, "", fmt.Errorf("unexpected object %T", obj)
+	}
+	if sfs.Spec.Replicas != nil && *sfs.Spec.Replicas == 0 {
+		return true, "", nil
+	}
+	if sfs.Status.ReadyReplicas > 0 {
+		return true, "", nil
+	}
+	return false, fmt.Sprintf("%d/%d replicas ready", sfs.Status.ReadyReplicas, getReplicas(sfs.Spec.Replicas)), nil
+}
+
+func isDaemonSetReady(obj runtime.Object) (bool, string, error) {
+	ds, ok := obj.(*appsv1.DaemonSet)
+	if !ok {
+		return false, "", fmt.Errorf("unexpected object %T", obj)
+	}
+	if ds.Status.ObservedGeneration < ds.Generation {
+		return false, "waiting for the daemonset to be scheduled", nil
+	}
+	if ds.Status.NumberReady >= ds.Status.DesiredNumberScheduled {
+		return true, "", nil
+	}
+	return false, fmt.Sprintf("%d/%d pods ready", ds.Status.NumberReady, ds.Status.DesiredNumberScheduled), nil
+}
+
+func isJobReady(obj runtime.Object) (bool, string, error) {
+	job, ok := obj.(*batchv1.Job)
+	if !ok {
+		return false, "", fmt.Errorf("unexpected object %T", obj)
+	}
+	for _, c := range job.Status.Conditions {
+		if c.Status != apiv1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobComplete:
+			return true, "", nil
+		case batchv1.JobFailed:
+			return false, "", fmt.Errorf("job '%s' failed: %s", job.Name, c.Message)
+		}
+	}
+	return false, fmt.Sprintf("%d active pods, %d succeeded", job.Status.Active, job.Status.Succeeded), nil
+}
+
+// newPVCReadinessCheck returns the check of the volumes. Volumes of storage classes that delay the binding until a pod uses them
+// are considered ready, the pods using them are waited by their workload
+func newPVCReadinessCheck(waitForFirstConsumer map[string]bool) readinessCheck {
+	return func(obj runtime.Object) (bool, string, error) {
+		pvc, ok := obj.(*apiv1.PersistentVolumeClaim)
+		if !ok {
+			return false, "", fmt.Errorf("unexpected object %T", obj)
+		}
+		switch pvc.Status.Phase {
+		case apiv1.ClaimBound:
+			return true, "", nil
+		case apiv1.ClaimLost:
+			return false, "", fmt.Errorf("volume '%s' lost its underlying persistent volume", pvc.Name)
+		}
+		if pvc.Spec.StorageClassName != nil && waitForFirstConsumer[*pvc.Spec.StorageClassName] {
+			return true, "", nil
+		}
+		return false, "waiting for the volume to be bound", nil
+	}
+}
+
+// getWaitForFirstConsumerClasses returns the storage classes that delay the binding of the volumes
+func getWaitForFirstConsumerClasses(classes []storagev1.StorageClass) map[string]bool {
+	result := map[string]bool{}
+	for _, sc := range classes {
+		if sc.VolumeBindingMode != nil && *sc.VolumeBindingMode == storagev1.VolumeBindingWaitForFirstConsumer {
+			result[sc.Name] = true
+		}
+	}
+	return result
+}
+
+func isIngressReady(obj runtime.Object) (bool, string, error) {
+	switch i := obj.(type) {
+	case *networkingv1.Ingress:
+		if len(i.Status.LoadBalancer.Ingress) > 0 {
+			return true, "", nil
+		}
+	case *networkingv1beta1.Ingress:
+		if len(i.Status.LoadBalancer.Ingress) > 0 {
+			return true, "", nil
+		}
+	default:
+		return false, "", fmt.Errorf("unexpected object %T", obj)
+	}
+	return false, "waiting for an address to be assigned", nil
+}
+
+// isCustomResourceReady checks the 'Ready' condition of a custom resource.
+// Custom resources without a 'Ready' condition are considered ready
+func isCustomResourceReady(obj runtime.Object) (bool, string, error) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return false, "", fmt.Errorf("unexpected object %T", obj)
+	}
+	conditions, _, _ := unstructured.NestedSlice(u.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != "Ready" {
+			continue
+		}
+		if condition["status"] == string(apiv1.ConditionTrue) {
+			return true, "", nil
+		}
+		if message, ok := condition["message"].(string); ok && message != "" {
+			return false, message, nil
+		}
+		return false, "waiting for the 'Ready' condition", nil
+	}
+	return true, "", nil
+}
+
+func getReplicas(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
@@ -0,0 +1,93 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+
+	"github.com/okteto/okteto/pkg/cmd/pipeline"
+	"github.com/okteto/okteto/pkg/model"
+)
+
+// stepTracker keeps the commands completed by a deploy so a failed deploy can be resumed
+type stepTracker struct {
+	// previous are the commands completed by the last deploy
+	previous  []pipeline.DeployStep
+	variables []string
+	// resume is true while the commands match the commands completed by the last deploy
+	resume    bool
+	completed []pipeline.DeployStep
+}
+
+func newStepTracker(previous []pipeline.DeployStep, variables []string, resume bool) *stepTracker {
+	return &stepTracker{
+		previous:  previous,
+		variables: append([]string{}, variables...),
+		resume:    resume,
+		completed: []pipeline.DeployStep{},
+	}
+}
+
+// canSkip returns if a command completed in the last deploy with the same definition and variables.
+// Only the commands before the first one that has to be executed are skipped
+func (t *stepTracker) canSkip(index int, command model.DeployCommand) bool {
+	if t == nil || !t.resume {
+		return false
+	}
+	if index >= len(t.previous) || t.previous[index] != t.newStep(command) {
+		t.resume = false
+		return false
+	}
+	return true
+}
+
+// complete records a command that completed successfully. It's a no-op if the deploy is not tracked
+func (t *stepTracker) complete(command model.DeployCommand) {
+	if t == nil {
+		return
+	}
+	t.completed = append(t.completed, t.newStep(command))
+}
+
+func (t *stepTracker) getCompleted() []pipeline.DeployStep {
+	if t == nil {
+		return nil
+	}
+	return t.completed
+}
+
+func (t *stepTracker) newStep(command model.DeployCommand) pipeline.DeployStep {
+	return pipeline.DeployStep{
+		Name: command.Name,
+		Hash: getStepHash(command, t.variables),
+	}
+}
+
+// getStepHash returns the hash of the definition of a command and the variables of the deploy
+func getStepHash(command model.DeployCommand, variables []string) string {
+	sorted := append([]string{}, variables...)
+	sort.Strings(sorted)
+	encoded, _ := json.Marshal(struct {
+		Command   model.DeployCommand `json:"command"`
+		Variables []string            `json:"variables"`
+	}{
+		Command:   command,
+		Variables: sorted,
+	})
+	hash := sha256.Sum256(encoded)
+	return hex.EncodeToString(hash[:])
+}
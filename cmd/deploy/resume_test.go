@@ -0,0 +1,53 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"testing"
+
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_stepTracker(t *testing.T) {
+	first := model.DeployCommand{Name: "first", Command: "make first"}
+	second := model.DeployCommand{Name: "second", Command: "make second"}
+	third := model.DeployCommand{Name: "third", Command: "make third"}
+	variables := []string{"B=2", "A=1"}
+
+	previous := newStepTracker(nil, variables, false)
+	previous.complete(first)
+	previous.complete(second)
+	previous.complete(third)
+
+	// variables are hashed regardless of their order
+	tracker := newStepTracker(previous.getCompleted(), []string{"A=1", "B=2"}, true)
+	assert.True(t, tracker.canSkip(0, first))
+	assert.True(t, tracker.canSkip(1, second))
+
+	// once a command changes, the following commands are executed
+	changed := model.DeployCommand{Name: "first", Command: "make first", Image: "golang"}
+	tracker = newStepTracker(previous.getCompleted(), variables, true)
+	assert.False(t, tracker.canSkip(0, changed))
+	assert.False(t, tracker.canSkip(1, second))
+
+	// nothing is skipped without resume
+	tracker = newStepTracker(previous.getCompleted(), variables, false)
+	assert.False(t, tracker.canSkip(0, first))
+
+	var nilTracker *stepTracker
+	assert.False(t, nilTracker.canSkip(0, first))
+	nilTracker.complete(first)
+	assert.Nil(t, nilTracker.getCompleted())
+}
@@ -15,20 +15,40 @@ package deploy
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
-	"time"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/okteto/okteto/pkg/cmd/pipeline"
 	oktetoErrors "github.com/okteto/okteto/pkg/errors"
-	"github.com/okteto/okteto/pkg/k8s/deployments"
-	"github.com/okteto/okteto/pkg/k8s/statefulsets"
 	oktetoLog "github.com/okteto/okteto/pkg/log"
+	"github.com/okteto/okteto/pkg/model"
 	"github.com/okteto/okteto/pkg/okteto"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 )
 
-func (dc *DeployCommand) wait(ctx context.Context, opts *Options) error {
+// builtinAPIGroups are the kubernetes API groups whose resources are waited using their typed clients
+var builtinAPIGroups = map[string]bool{
+	"":            true,
+	"apps":        true,
+	"batch":       true,
+	"autoscaling": true,
+	"policy":      true,
+	"extensions":  true,
+}
+
+func (dc *DeployCommand) wait(ctx context.Context, opts *Options, inventory pipeline.Inventory) error {
 	oktetoLog.Spinner(fmt.Sprintf("Waiting for %s to be deployed...", opts.Name))
 	oktetoLog.StartSpinner()
 	defer oktetoLog.StopSpinner()
@@ -37,7 +57,7 @@ func (dc *DeployCommand) wait(ctx context.Context, opts *Options) error {
 	signal.Notify(stop, os.Interrupt)
 	exit := make(chan error, 1)
 	go func() {
-		exit <- dc.waitForResourcesToBeRunning(ctx, opts)
+		exit <- dc.waitForResourcesToBeRunning(ctx, opts, inventory)
 	}()
 	select {
 	case <-stop:
@@ -53,47 +73,400 @@ func (dc *DeployCommand) wait(ctx context.Context, opts *Options) error {
 	return nil
 }
 
-func (dc *DeployCommand) waitForResourcesToBeRunning(ctx context.Context, opts *Options) error {
-	ticker := time.NewTicker(5 * time.Second)
-	to := time.NewTicker(opts.Timeout)
-	c, _, err := dc.K8sClientProvider.Provide(okteto.Context().Cfg)
+func (dc *DeployCommand) waitForResourcesToBeRunning(ctx context.Context, opts *Options, inventory pipeline.Inventory) error {
+	c, restConfig, err := dc.K8sClientProvider.Provide(okteto.Context().Cfg)
 	if err != nil {
 		return err
 	}
+	var dynClient dynamic.Interface
+	if restConfig != nil {
+		dynClient, err = dynamic.NewForConfig(restConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	resources := getWatchedResources(ctx, c, dynClient, opts.Manifest.Name, opts.Manifest.Namespace, inventory)
+	err = waitForResources(ctx, resources)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return oktetoErrors.UserError{
+			E:    fmt.Errorf("'%s' deploy didn't finish after %s", opts.Manifest.Name, opts.Timeout.String()),
+			Hint: err.Error(),
+		}
+	}
+	return err
+}
+
+// watchedResource represents a kind of resource waited by 'okteto deploy --wait'
+type watchedResource struct {
+	kind  string
+	list  func(ctx context.Context) (runtime.Object, error)
+	watch func(ctx context.Context, resourceVersion string) (watch.Interface, error)
+	check readinessCheck
+}
+
+// pendingError is returned when the resources are not ready before the context is done
+type pendingError struct {
+	err     error
+	pending []string
+}
+
+func (e *pendingError) Error() string {
+	if len(e.pending) == 0 {
+		return e.err.Error()
+	}
+	return fmt.Sprintf("Resources still pending:\n    - %s", strings.Join(e.pending, "\n    - "))
+}
+
+func (e *pendingError) Unwrap() error {
+	return e.err
+}
+
+// waitForResources waits until every object of the watched resources is ready.
+// The objects are listed once and then updated by watches, that are restarted when the API server closes them
+func waitForResources(ctx context.Context, resources []watchedResource) error {
+	t := newReadinessTracker(resources)
+	versions := make([]string, len(resources))
+	for i := range resources {
+		rv, err := t.sync(ctx, i)
+		if err != nil {
+			return t.wrapErr(ctx, err)
+		}
+		versions[i] = rv
+	}
+	if t.isReady() {
+		return nil
+	}
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errCh := make(chan error, len(resources))
+	for i := range resources {
+		go func(i int) {
+			if err := t.watch(watchCtx, i, versions[i]); err != nil {
+				errCh <- err
+			}
+		}(i)
+	}
 
 	for {
 		select {
-		case <-to.C:
-			return fmt.Errorf("'%s' deploy didn't finish after %s", opts.Manifest.Name, opts.Timeout.String())
-		case <-ticker.C:
-			dList, err := pipeline.ListDeployments(ctx, opts.Manifest.Name, opts.Manifest.Namespace, c)
-			if err != nil {
-				return err
-			}
-			areAllRunning := true
-			for i := range dList {
-				d := &dList[i]
-				if !deployments.IsRunning(ctx, opts.Manifest.Namespace, d.Name, c) {
-					areAllRunning = false
-				}
+		case <-ctx.Done():
+			return t.wrapErr(ctx, ctx.Err())
+		case err := <-errCh:
+			return t.wrapErr(ctx, err)
+		case <-t.changed:
+			if t.isReady() {
+				return nil
 			}
-			if !areAllRunning {
-				continue
-			}
-			sfsList, err := pipeline.ListStatefulsets(ctx, opts.Manifest.Name, opts.Manifest.Namespace, c)
-			if err != nil {
-				return err
+		}
+	}
+}
+
+// readinessTracker keeps the objects that are not ready yet
+type readinessTracker struct {
+	resources []watchedResource
+	mu        sync.Mutex
+	pending   []map[string]string
+	changed   chan struct{}
+}
+
+func newReadinessTracker(resources []watchedResource) *readinessTracker {
+	return &readinessTracker{
+		resources: resources,
+		pending:   make([]map[string]string, len(resources)),
+		changed:   make(chan struct{}, 1),
+	}
+}
+
+// sync lists the objects of a resource and returns the resource version to start watching from
+func (t *readinessTracker) sync(ctx context.Context, idx int) (string, error) {
+	r := t.resources[idx]
+	list, err := r.list(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error listing %s: %w", r.kind, err)
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return "", err
+	}
+	listMeta, err := meta.ListAccessor(list)
+	if err != nil {
+		return "", err
+	}
+
+	pending := map[string]string{}
+	for _, item := range items {
+		name, reason, err := t.evaluate(r, item)
+		if err != nil {
+			return "", err
+		}
+		if reason != "" {
+			pending[name] = reason
+		}
+	}
+
+	t.mu.Lock()
+	t.pending[idx] = pending
+	t.mu.Unlock()
+	t.notify()
+	return listMeta.GetResourceVersion(), nil
+}
+
+// watch updates the pending objects of a resource until the context is done
+func (t *readinessTracker) watch(ctx context.Context, idx int, resourceVersion string) error {
+	r := t.resources[idx]
+	for {
+		w, err := r.watch(ctx, resourceVersion)
+		if err != nil {
+			return fmt.Errorf("error watching %s: %w", r.kind, err)
+		}
+		err = t.consume(ctx, idx, w)
+		w.Stop()
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		// the watch was closed by the API server, list again to not miss any change
+		oktetoLog.Debugf("watch of %s closed, listing again", r.kind)
+		resourceVersion, err = t.sync(ctx, idx)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (t *readinessTracker) consume(ctx context.Context, idx int, w watch.Interface) error {
+	r := t.resources[idx]
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e, ok := <-w.ResultChan():
+			if !ok {
+				return nil
 			}
-			for i := range sfsList {
-				ss := &sfsList[i]
-				if !statefulsets.IsRunning(ctx, opts.Manifest.Namespace, ss.Name, c) {
-					areAllRunning = false
+			switch e.Type {
+			case watch.Added, watch.Modified:
+				name, reason, err := t.evaluate(r, e.Object)
+				if err != nil {
+					return err
 				}
+				t.setPending(idx, name, reason)
+			case watch.Deleted:
+				if m, err := meta.Accessor(e.Object); err == nil {
+					t.setPending(idx, m.GetName(), "")
+				}
+			case watch.Error:
+				oktetoLog.Debugf("error watching %s: %s", r.kind, k8sErrors.FromObject(e.Object))
+				return nil
 			}
-			if !areAllRunning {
-				continue
-			}
-			return nil
 		}
 	}
 }
+
+// evaluate returns the name of an object and why it is pending. The reason is empty if the object is ready
+func (*readinessTracker) evaluate(r watchedResource, obj runtime.Object) (string, string, error) {
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return "", "", err
+	}
+	ready, reason, err := r.check(obj)
+	if err != nil || ready {
+		return m.GetName(), "", err
+	}
+	if reason == "" {
+		reason = "not ready"
+	}
+	return m.GetName(), reason, nil
+}
+
+func (t *readinessTracker) setPending(idx int, name, reason string) {
+	t.mu.Lock()
+	if reason == "" {
+		delete(t.pending[idx], name)
+	} else {
+		t.pending[idx][name] = reason
+	}
+	t.mu.Unlock()
+	t.notify()
+}
+
+func (t *readinessTracker) notify() {
+	select {
+	case t.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (t *readinessTracker) isReady() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, pending := range t.pending {
+		if len(pending) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// getPending returns a description of the objects that are not ready yet
+func (t *readinessTracker) getPending() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	result := []string{}
+	for idx, pending := range t.pending {
+		for name, reason := range pending {
+			result = append(result, fmt.Sprintf("%s '%s': %s", t.resources[idx].kind, name, reason))
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// wrapErr adds the pending objects to the error returned when the context is done
+func (t *readinessTracker) wrapErr(ctx context.Context, err error) error {
+	if ctx.Err() == nil {
+		return err
+	}
+	return &pendingError{err: ctx.Err(), pending: t.getPending()}
+}
+
+// getWatchedResources returns the resources deployed by a development environment that must be ready.
+// Custom resources are taken from the inventory of the deploy
+func getWatchedResources(ctx context.Context, c kubernetes.Interface, dynClient dynamic.Interface, name, ns string, inventory pipeline.Inventory) []watchedResource {
+	labelSelector := fmt.Sprintf("%s=%s", model.DeployedByLabel, name)
+	withLabel := func(rv string) metav1.ListOptions {
+		return metav1.ListOptions{LabelSelector: labelSelector, ResourceVersion: rv}
+	}
+
+	resources := []watchedResource{
+		{
+			kind: "Deployment",
+			list: func(ctx context.Context) (runtime.Object, error) {
+				return c.AppsV1().Deployments(ns).List(ctx, withLabel(""))
+			},
+			watch: func(ctx context.Context, rv string) (watch.Interface, error) {
+				return c.AppsV1().Deployments(ns).Watch(ctx, withLabel(rv))
+			},
+			check: isDeploymentReady,
+		},
+		{
+			kind: "StatefulSet",
+			list: func(ctx context.Context) (runtime.Object, error) {
+				return c.AppsV1().StatefulSets(ns).List(ctx, withLabel(""))
+			},
+			watch: func(ctx context.Context, rv string) (watch.Interface, error) {
+				return c.AppsV1().StatefulSets(ns).Watch(ctx, withLabel(rv))
+			},
+			check: isStatefulSetReady,
+		},
+		{
+			kind: "DaemonSet",
+			list: func(ctx context.Context) (runtime.Object, error) {
+				return c.AppsV1().DaemonSets(ns).List(ctx, withLabel(""))
+			},
+			watch: func(ctx context.Context, rv string) (watch.Interface, error) {
+				return c.AppsV1().DaemonSets(ns).Watch(ctx, withLabel(rv))
+			},
+			check: isDaemonSetReady,
+		},
+		{
+			// the runs of the cronjobs are not waited: they are scheduled after the deploy and the failed ones
+			// are kept by the cronjob history, so they would fail every deploy
+			kind: "Job",
+			list: func(ctx context.Context) (runtime.Object, error) {
+				return c.BatchV1().Jobs(ns).List(ctx, withLabel(""))
+			},
+			watch: func(ctx context.Context, rv string) (watch.Interface, error) {
+				return c.BatchV1().Jobs(ns).Watch(ctx, withLabel(rv))
+			},
+			check: isJobReady,
+		},
+		getPVCWatchedResource(ctx, c, labelSelector, ns),
+		getIngressWatchedResource(ctx, c, labelSelector, ns),
+	}
+
+	if dynClient == nil {
+		return resources
+	}
+	for _, item := range inventory {
+		item := item
+		gvr, err := item.GroupVersionResource()
+		if err != nil || builtinAPIGroups[gvr.Group] || strings.HasSuffix(gvr.Group, ".k8s.io") {
+			continue
+		}
+		var client dynamic.ResourceInterface = dynClient.Resource(gvr)
+		if item.Namespace != "" {
+			client = dynClient.Resource(gvr).Namespace(item.Namespace)
+		}
+		byName := func(rv string) metav1.ListOptions {
+			return metav1.ListOptions{FieldSelector: fields.OneTermEqualSelector("metadata.name", item.Name).String(), ResourceVersion: rv}
+		}
+		resources = append(resources, watchedResource{
+			kind: item.Kind,
+			list: func(ctx context.Context) (runtime.Object, error) {
+				return client.List(ctx, byName(""))
+			},
+			watch: func(ctx context.Context, rv string) (watch.Interface, error) {
+				return client.Watch(ctx, byName(rv))
+			},
+			check: isCustomResourceReady,
+		})
+	}
+	return resources
+}
+
+func getPVCWatchedResource(ctx context.Context, c kubernetes.Interface, labelSelector, ns string) watchedResource {
+	waitForFirstConsumer := map[string]bool{}
+	scList, err := c.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		oktetoLog.Infof("could not list storage classes: %s", err)
+	} else {
+		waitForFirstConsumer = getWaitForFirstConsumerClasses(scList.Items)
+	}
+
+	return watchedResource{
+		kind: "PersistentVolumeClaim",
+		list: func(ctx context.Context) (runtime.Object, error) {
+			return c.CoreV1().PersistentVolumeClaims(ns).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+		},
+		watch: func(ctx context.Context, rv string) (watch.Interface, error) {
+			return c.CoreV1().PersistentVolumeClaims(ns).Watch(ctx, metav1.ListOptions{LabelSelector: labelSelector, ResourceVersion: rv})
+		},
+		check: newPVCReadinessCheck(waitForFirstConsumer),
+	}
+}
+
+// getIngressWatchedResource returns the ingresses of the development environment. Clusters without networking.k8s.io/v1 use v1beta1
+func getIngressWatchedResource(ctx context.Context, c kubernetes.Interface, labelSelector, ns string) watchedResource {
+	r := watchedResource{
+		kind: "Ingress",
+		list: func(ctx context.Context) (runtime.Object, error) {
+			return c.NetworkingV1().Ingresses(ns).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+		},
+		watch: func(ctx context.Context, rv string) (watch.Interface, error) {
+			return c.NetworkingV1().Ingresses(ns).Watch(ctx, metav1.ListOptions{LabelSelector: labelSelector, ResourceVersion: rv})
+		},
+		check: isIngressReady,
+	}
+	if _, err := c.NetworkingV1().Ingresses(ns).List(ctx, metav1.ListOptions{LabelSelector: labelSelector, Limit: 1}); !k8sErrors.IsNotFound(err) {
+		return r
+	}
+	r.list = func(ctx context.Context) (runtime.Object, error) {
+		return c.NetworkingV1beta1().Ingresses(ns).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	}
+	r.watch = func(ctx context.Context, rv string) (watch.Interface, error) {
+		return c.NetworkingV1beta1().Ingresses(ns).Watch(ctx, metav1.ListOptions{LabelSelector: labelSelector, ResourceVersion: rv})
+	}
+	return r
+}
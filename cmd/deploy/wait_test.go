@@ -0,0 +1,260 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/okteto/okteto/pkg/cmd/pipeline"
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	dynamicFake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8sTesting "k8s.io/client-go/testing"
+)
+
+func newWaitObjectMeta(name string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      name,
+		Namespace: "test",
+		Labels: map[string]string{
+			model.DeployedByLabel: "movies",
+		},
+	}
+}
+
+func Test_waitForResources(t *testing.T) {
+	readyDeployment := &appsv1.Deployment{
+		ObjectMeta: newWaitObjectMeta("api"),
+		Status:     appsv1.DeploymentStatus{ReadyReplicas: 1},
+	}
+	completedJob := &batchv1.Job{
+		ObjectMeta: newWaitObjectMeta("migrate"),
+		Status: batchv1.JobStatus{
+			Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: apiv1.ConditionTrue}},
+		},
+	}
+	boundPVC := &apiv1.PersistentVolumeClaim{
+		ObjectMeta: newWaitObjectMeta("data"),
+		Status:     apiv1.PersistentVolumeClaimStatus{Phase: apiv1.ClaimBound},
+	}
+	pendingIngress := &networkingv1.Ingress{
+		ObjectMeta: newWaitObjectMeta("web"),
+	}
+	notDeployedJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "test"},
+	}
+	cronJob := &batchv1.CronJob{
+		ObjectMeta: newWaitObjectMeta("backup"),
+	}
+	cronJobRun := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "backup-1234",
+			Namespace:       "test",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "CronJob", Name: "backup"}},
+		},
+	}
+	failedCronJobRun := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "backup-1000",
+			Namespace:       "test",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "CronJob", Name: "backup"}},
+		},
+		Status: batchv1.JobStatus{
+			Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: apiv1.ConditionTrue, Message: "BackoffLimitExceeded"}},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		objects         []runtime.Object
+		expectedPending []string
+	}{
+		{
+			name:    "all ready",
+			objects: []runtime.Object{readyDeployment, completedJob, boundPVC, notDeployedJob},
+		},
+		{
+			name:            "pending ingress",
+			objects:         []runtime.Object{readyDeployment, pendingIngress},
+			expectedPending: []string{"Ingress 'web': waiting for an address to be assigned"},
+		},
+		{
+			name:    "running job created by a cronjob",
+			objects: []runtime.Object{readyDeployment, cronJob, cronJobRun},
+		},
+		{
+			name:    "failed job created by a cronjob before the deploy",
+			objects: []runtime.Object{readyDeployment, cronJob, failedCronJobRun},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewSimpleClientset(tt.objects...)
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			err := waitForResources(ctx, getWatchedResources(ctx, c, nil, "movies", "test", nil))
+			if tt.expectedPending == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var pErr *pendingError
+			assert.True(t, errors.As(err, &pErr))
+			assert.True(t, errors.Is(err, context.DeadlineExceeded))
+			assert.Equal(t, tt.expectedPending, pErr.pending)
+		})
+	}
+}
+
+func Test_waitForResourcesWithWatch(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: newWaitObjectMeta("api"),
+	}
+	c := fake.NewSimpleClientset(deployment)
+	fw := watch.NewFake()
+	c.PrependWatchReactor("deployments", k8sTesting.DefaultWatchReactor(fw, nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() {
+		ready := deployment.DeepCopy()
+		ready.Status.ReadyReplicas = 1
+		fw.Modify(ready)
+	}()
+
+	assert.NoError(t, waitForResources(ctx, getWatchedResources(ctx, c, nil, "movies", "test", nil)))
+}
+
+func Test_waitForResourcesWithFailedJob(t *testing.T) {
+	job := &batchv1.Job{
+		ObjectMeta: newWaitObjectMeta("migrate"),
+		Status: batchv1.JobStatus{
+			Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: apiv1.ConditionTrue, Message: "BackoffLimitExceeded"}},
+		},
+	}
+	c := fake.NewSimpleClientset(job)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := waitForResources(ctx, getWatchedResources(ctx, c, nil, "movies", "test", nil))
+	assert.EqualError(t, err, "job 'migrate' failed: BackoffLimitExceeded")
+}
+
+func Test_waitForCustomResources(t *testing.T) {
+	database := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "db.example.com/v1",
+		"kind":       "Database",
+		"metadata": map[string]interface{}{
+			"name":      "db",
+			"namespace": "test",
+		},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "False", "message": "provisioning"},
+			},
+		},
+	}}
+	dynClient := dynamicFake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		{Group: "db.example.com", Version: "v1", Resource: "databases"}: "DatabaseList",
+	}, database)
+	inventory := pipeline.Inventory{
+		{APIVersion: "db.example.com/v1", Kind: "Database", Resource: "databases", Namespace: "test", Name: "db"},
+		{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "Role", Resource: "roles", Namespace: "test", Name: "db"},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err := waitForResources(ctx, getWatchedResources(ctx, fake.NewSimpleClientset(), dynClient, "movies", "test", inventory))
+	var pErr *pendingError
+	assert.True(t, errors.As(err, &pErr))
+	assert.Equal(t, []string{"Database 'db': provisioning"}, pErr.pending)
+}
+
+func Test_readinessChecks(t *testing.T) {
+	zero := int32(0)
+	waitForFirstConsumer := storagev1.VolumeBindingWaitForFirstConsumer
+	local := "local"
+	tests := []struct {
+		name     string
+		check    readinessCheck
+		obj      runtime.Object
+		expected bool
+	}{
+		{
+			name:     "deployment scaled to zero",
+			check:    isDeploymentReady,
+			obj:      &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Replicas: &zero}},
+			expected: true,
+		},
+		{
+			name:  "statefulset without ready replicas",
+			check: isStatefulSetReady,
+			obj:   &appsv1.StatefulSet{},
+		},
+		{
+			name:  "daemonset not scheduled yet",
+			check: isDaemonSetReady,
+			obj:   &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Generation: 1}},
+		},
+		{
+			name:     "daemonset ready",
+			check:    isDaemonSetReady,
+			obj:      &appsv1.DaemonSet{Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 2, NumberReady: 2}},
+			expected: true,
+		},
+		{
+			name:  "pending pvc",
+			check: newPVCReadinessCheck(nil),
+			obj:   &apiv1.PersistentVolumeClaim{Status: apiv1.PersistentVolumeClaimStatus{Phase: apiv1.ClaimPending}},
+		},
+		{
+			name: "pending pvc waiting for first consumer",
+			check: newPVCReadinessCheck(getWaitForFirstConsumerClasses([]storagev1.StorageClass{
+				{ObjectMeta: metav1.ObjectMeta{Name: local}, VolumeBindingMode: &waitForFirstConsumer},
+			})),
+			obj: &apiv1.PersistentVolumeClaim{
+				Spec:   apiv1.PersistentVolumeClaimSpec{StorageClassName: &local},
+				Status: apiv1.PersistentVolumeClaimStatus{Phase: apiv1.ClaimPending},
+			},
+			expected: true,
+		},
+		{
+			name:     "custom resource without ready condition",
+			check:    isCustomResourceReady,
+			obj:      &unstructured.Unstructured{Object: map[string]interface{}{}},
+			expected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ready, _, err := tt.check(tt.obj)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, ready)
+		})
+	}
+}
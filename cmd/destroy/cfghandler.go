@@ -18,6 +18,7 @@ import (
 
 	"github.com/okteto/okteto/cmd/utils"
 	"github.com/okteto/okteto/pkg/cmd/pipeline"
+	oktetoErrors "github.com/okteto/okteto/pkg/errors"
 	"github.com/okteto/okteto/pkg/k8s/configmaps"
 	oktetoLog "github.com/okteto/okteto/pkg/log"
 	"github.com/okteto/okteto/pkg/model"
@@ -31,6 +32,8 @@ type configMapHandler interface {
 	translateConfigMapAndDeploy(context.Context, *pipeline.CfgData) (*apiv1.ConfigMap, error)
 	destroyConfigMap(context.Context, *apiv1.ConfigMap, string) error
 	setErrorStatus(context.Context, *apiv1.ConfigMap, *pipeline.CfgData, error) error
+	acquireLock(context.Context, pipeline.LockOptions) (*pipeline.Lock, error)
+	getConfigMap(context.Context, string, string) (*apiv1.ConfigMap, error)
 }
 
 // destroyInsideDeployConfigMapHandler is the runner used when the okteto is executed
@@ -73,6 +76,22 @@ func (ch *defaultConfigMapHandler) setErrorStatus(ctx context.Context, cfg *apiv
 	return pipeline.UpdateConfigMap(ctx, cfg, data, ch.k8sClient)
 }
 
+func (ch *defaultConfigMapHandler) acquireLock(ctx context.Context, opts pipeline.LockOptions) (*pipeline.Lock, error) {
+	return pipeline.AcquireLock(ctx, opts, ch.k8sClient)
+}
+
+// getConfigMap returns the configmap of the development environment, or nil if it isn't deployed
+func (ch *defaultConfigMapHandler) getConfigMap(ctx context.Context, name, namespace string) (*apiv1.ConfigMap, error) {
+	cfg, err := configmaps.Get(ctx, pipeline.TranslatePipelineName(name), namespace, ch.k8sClient)
+	if err != nil {
+		if oktetoErrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return cfg, nil
+}
+
 func (*destroyInsideDeployConfigMapHandler) translateConfigMapAndDeploy(_ context.Context, _ *pipeline.CfgData) (*apiv1.ConfigMap, error) {
 	return nil, nil
 }
@@ -85,3 +104,12 @@ func (*destroyInsideDeployConfigMapHandler) setErrorStatus(_ context.Context, _
 	oktetoLog.AddToBuffer(oktetoLog.InfoLevel, "Destruction failed: %s", err.Error())
 	return nil
 }
+
+// acquireLock doesn't lock the development environment, it is protected by the lock of the deploy running the destroy
+func (*destroyInsideDeployConfigMapHandler) acquireLock(_ context.Context, _ pipeline.LockOptions) (*pipeline.Lock, error) {
+	return nil, nil
+}
+
+func (*destroyInsideDeployConfigMapHandler) getConfigMap(_ context.Context, _, _ string) (*apiv1.ConfigMap, error) {
+	return nil, nil
+}
@@ -20,6 +20,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"sort"
 	"time"
 
 	"strings"
@@ -56,6 +57,10 @@ const (
 type destroyer interface {
 	DestroyWithLabel(ctx context.Context, ns string, opts namespaces.DeleteAllOptions) error
 	DestroySFSVolumes(ctx context.Context, ns string, opts namespaces.DeleteAllOptions) error
+	DestroyInventory(ctx context.Context, inventory pipeline.Inventory, opts namespaces.DeleteAllOptions) pipeline.Inventory
+	ListWithLabel(ctx context.Context, ns string, opts namespaces.DeleteAllOptions) ([]pipeline.InventoryItem, error)
+	ListSFSVolumes(ctx context.Context, ns string, opts namespaces.DeleteAllOptions) ([]string, error)
+	ListInventory(ctx context.Context, inventory pipeline.Inventory, opts namespaces.DeleteAllOptions) ([]pipeline.InventoryItem, error)
 }
 
 type secretHandler interface {
@@ -72,12 +77,23 @@ type Options struct {
 	ManifestPath        string
 	Name                string
 	Variables           []string
+	VarFiles            []string
 	Namespace           string
 	DestroyVolumes      bool
 	DestroyDependencies bool
 	ForceDestroy        bool
 	K8sContext          string
 	RunWithoutBash      bool
+	// LockTimeout is the time to wait for the lock of the development environment held by another operation
+	LockTimeout time.Duration
+	// ForceUnlock releases the lock of the development environment held by another operation
+	ForceUnlock bool
+	// CommandsTimeout is the time to run all the destroy commands of the manifest. Zero means no limit
+	CommandsTimeout time.Duration
+	// DryRun shows what would be destroyed without destroying anything
+	DryRun bool
+	// Output is the format of the dry-run report
+	Output string
 }
 
 type destroyCommand struct {
@@ -102,6 +118,15 @@ func Destroy(ctx context.Context) *cobra.Command {
 		Long:  `Destroy everything created by the 'okteto deploy' command. You can also include a 'destroy' section in your okteto manifest with a list of custom commands to be executed on destroy`,
 		Args:  utils.NoArgsAccepted("https://okteto.com/docs/reference/cli/#destroy"),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutput(options.Output); err != nil {
+				return err
+			}
+			variables, err := utils.LoadVariables(options.VarFiles, options.Variables)
+			if err != nil {
+				return err
+			}
+			options.Variables = variables
+
 			if options.ManifestPath != "" {
 				// if path is absolute, its transformed to rel from root
 				initialCWD, err := os.Getwd()
@@ -160,10 +185,12 @@ func Destroy(ctx context.Context) *cobra.Command {
 				options.Namespace = okteto.Context().Namespace
 			}
 
+			commandExecutor := executor.NewExecutor(oktetoLog.GetOutputFormat(), options.RunWithoutBash)
+			commandExecutor.SetTimeout(options.CommandsTimeout)
 			c := &destroyCommand{
 				getManifest: model.GetManifestV2,
 
-				executor:          executor.NewExecutor(oktetoLog.GetOutputFormat(), options.RunWithoutBash),
+				executor:          commandExecutor,
 				configMapHandler:  newConfigmapHandler(k8sClient),
 				nsDestroyer:       namespaces.NewNamespace(dynClient, discClient, cfg, k8sClient),
 				secrets:           secrets.NewSecrets(k8sClient),
@@ -177,6 +204,9 @@ func Destroy(ctx context.Context) *cobra.Command {
 			os.Setenv("KUBECONFIG", kubeconfigPath)
 			defer os.Remove(kubeconfigPath)
 			err = c.runDestroy(ctx, options)
+			if options.DryRun {
+				return err
+			}
 			analytics.TrackDestroy(err == nil)
 			if err == nil {
 				oktetoLog.Success("Development environment '%s' successfully destroyed", options.Name)
@@ -189,16 +219,28 @@ func Destroy(ctx context.Context) *cobra.Command {
 	cmd.Flags().StringVar(&options.Name, "name", "", "development environment name")
 	cmd.Flags().StringVarP(&options.ManifestPath, "file", "f", "", "path to the manifest file")
 	cmd.Flags().BoolVarP(&options.DestroyVolumes, "volumes", "v", false, "remove persistent volumes")
+	cmd.Flags().StringArrayVar(&options.Variables, "var", []string{}, "set a variable (can be set more than once)")
+	cmd.Flags().StringArrayVar(&options.VarFiles, "var-file", []string{}, utils.VarFileFlagUsage)
+	cmd.Flags().DurationVar(&options.CommandsTimeout, "commands-timeout", 0, "the length of time to run all the destroy commands of the manifest, zero means never. The 'timeout' field of a command limits the time to run that command")
 	cmd.Flags().BoolVarP(&options.DestroyDependencies, "dependencies", "d", false, "destroy dependencies")
 	cmd.Flags().BoolVar(&options.ForceDestroy, "force-destroy", false, "forces the development environment to be destroyed even if there is an error executing the custom destroy commands defined in the manifest")
 	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "overwrites the namespace where the development environment was deployed")
 	cmd.Flags().StringVarP(&options.K8sContext, "context", "c", "", "context where the development environment was deployed")
 	cmd.Flags().BoolVarP(&options.RunWithoutBash, "no-bash", "", false, "execute commands without bash")
+	cmd.Flags().DurationVar(&options.LockTimeout, "lock-timeout", 0, "the length of time to wait if the development environment is locked by another deploy or destroy, zero fails immediately")
+	cmd.Flags().BoolVar(&options.ForceUnlock, "force-unlock", false, "release the lock of the development environment held by another deploy or destroy")
+	cmd.Flags().BoolVar(&options.DryRun, "dry-run", false, "show the commands that would run and the resources that would be destroyed without destroying anything")
+	cmd.Flags().StringVarP(&options.Output, "output", "o", "", "output format of the dry-run. One of: ['json']")
 
 	return cmd
 }
 
 func (dc *destroyCommand) runDestroy(ctx context.Context, opts *Options) error {
+	// The variables are expanded in the manifest and used by the destroy commands
+	if err := utils.SetVariables(opts.Variables, os.Setenv); err != nil {
+		return err
+	}
+
 	// Read manifest file with the commands to be executed
 	manifest, err := dc.getManifest(opts.ManifestPath)
 	if err != nil {
@@ -238,6 +280,10 @@ func (dc *destroyCommand) runDestroy(ctx context.Context, opts *Options) error {
 		namespace = okteto.Context().Namespace
 	}
 
+	if opts.DryRun {
+		return dc.runDryRun(ctx, opts, manifest, namespace)
+	}
+
 	oktetoLog.AddToBuffer(oktetoLog.InfoLevel, "Destroying...")
 
 	data := &pipeline.CfgData{
@@ -247,6 +293,18 @@ func (dc *destroyCommand) runDestroy(ctx context.Context, opts *Options) error {
 		Filename:  opts.ManifestPathFlag,
 	}
 
+	lock, err := dc.configMapHandler.acquireLock(ctx, pipeline.LockOptions{
+		Name:      opts.Name,
+		Namespace: namespace,
+		Operation: pipeline.DestroyOperation,
+		Timeout:   opts.LockTimeout,
+		Force:     opts.ForceUnlock,
+	})
+	if err != nil {
+		return err
+	}
+	defer lock.Release(ctx)
+
 	cfg, err := dc.configMapHandler.translateConfigMapAndDeploy(ctx, data)
 	if err != nil {
 		return err
@@ -316,6 +374,45 @@ func (dc *destroyCommand) runDestroy(ctx context.Context, opts *Options) error {
 	oktetoLog.SetStage("")
 	oktetoLog.DisableMasking()
 
+	if manifest.Deploy != nil && manifest.Deploy.Kustomize != nil {
+		oktetoLog.SetStage("Destroying kustomization")
+		if err := dc.destroyKustomization(ctx, manifest.Deploy.Kustomize, namespace); err != nil {
+			oktetoLog.Infof("could not delete kustomization: %s", err)
+			if !opts.ForceDestroy {
+				if err := dc.configMapHandler.setErrorStatus(ctx, cfg, data, err); err != nil {
+					return err
+				}
+				return err
+			}
+		}
+	}
+
+	if manifest.Deploy != nil && manifest.Deploy.Divert != nil && manifest.Deploy.Divert.Namespace != namespace {
+		oktetoLog.SetStage("Destroying divert")
+		if err := dc.destroyDivert(ctx, manifest, opts.Name, namespace); err != nil {
+			oktetoLog.Infof("could not destroy divert: %s", err)
+			if !opts.ForceDestroy {
+				if err := dc.configMapHandler.setErrorStatus(ctx, cfg, data, err); err != nil {
+					return err
+				}
+				return err
+			}
+		}
+	}
+
+	if manifest.Deploy != nil && manifest.Deploy.Helm != nil {
+		oktetoLog.SetStage("Uninstalling helm release")
+		if err := dc.destroyHelmRelease(manifest.Deploy.Helm, opts.Name, namespace); err != nil {
+			oktetoLog.Infof("could not uninstall helm release: %s", err)
+			if !opts.ForceDestroy {
+				if err := dc.configMapHandler.setErrorStatus(ctx, cfg, data, err); err != nil {
+					return err
+				}
+				return err
+			}
+		}
+	}
+
 	oktetoLog.Spinner(fmt.Sprintf("Destroying development environment '%s'...", opts.Name))
 	oktetoLog.StartSpinner()
 	defer oktetoLog.StopSpinner()
@@ -355,6 +452,13 @@ func (dc *destroyCommand) runDestroy(ctx context.Context, opts *Options) error {
 		}
 	}
 
+	if err := dc.destroyInventory(ctx, opts, cfg, data, deleteOpts); err != nil {
+		if err := dc.configMapHandler.setErrorStatus(ctx, cfg, data, err); err != nil {
+			return err
+		}
+		return err
+	}
+
 	oktetoLog.Debugf("destroying resources with deployed-by label '%s'", deployedBySelector)
 	oktetoLog.SetStage(fmt.Sprintf("Destroying by label '%s'", deployedBySelector))
 	if err := dc.nsDestroyer.DestroyWithLabel(ctx, opts.Namespace, deleteOpts); err != nil {
@@ -374,27 +478,41 @@ func (dc *destroyCommand) runDestroy(ctx context.Context, opts *Options) error {
 	return commandErr
 }
 
-func (dc *destroyCommand) destroyHelmReleasesIfPresent(ctx context.Context, opts *Options, labelSelector string) error {
-	sList, err := dc.secrets.List(ctx, opts.Namespace, labelSelector)
+// destroyInventory deletes the objects created by the deploys of the development environment, including cluster-scoped objects.
+// Resources deployed before the inventory existed are still destroyed by label
+func (dc *destroyCommand) destroyInventory(ctx context.Context, opts *Options, cfg *v1.ConfigMap, data *pipeline.CfgData, deleteOpts namespaces.DeleteAllOptions) error {
+	inventory, err := pipeline.GetInventory(cfg)
 	if err != nil {
-		return err
+		oktetoLog.Infof("could not read the inventory: %s", err)
+		return nil
+	}
+	if len(inventory) == 0 {
+		return nil
 	}
 
-	oktetoLog.Debugf("checking if application installed something with helm")
-	helmReleases := map[string]bool{}
-	for _, s := range sList {
-		if s.Type == model.HelmSecretType && s.Labels[ownerLabel] == helmOwner {
-			helmReleaseName, ok := s.Labels[nameLabel]
-			if !ok {
-				continue
-			}
+	oktetoLog.SetStage("Destroying inventory")
+	leftovers := dc.nsDestroyer.DestroyInventory(ctx, inventory, deleteOpts)
+	if len(leftovers) == 0 {
+		return nil
+	}
+	for _, item := range leftovers {
+		oktetoLog.Warning("%s could not be destroyed", item.String())
+	}
+	if opts.ForceDestroy {
+		return nil
+	}
+	data.Inventory = leftovers
+	return fmt.Errorf("%d resources created by the development environment could not be destroyed", len(leftovers))
+}
 
-			helmReleases[helmReleaseName] = true
-		}
+func (dc *destroyCommand) destroyHelmReleasesIfPresent(ctx context.Context, opts *Options, labelSelector string) error {
+	helmReleases, err := dc.getHelmReleases(ctx, opts.Namespace, labelSelector)
+	if err != nil {
+		return err
 	}
 
 	// If the application to be destroyed was deployed with helm, we try to uninstall it to avoid to leave orphan release resources
-	for releaseName := range helmReleases {
+	for _, releaseName := range helmReleases {
 		oktetoLog.Debugf("uninstalling helm release '%s'", releaseName)
 		cmd := fmt.Sprintf(helmUninstallCommand, releaseName)
 		cmdInfo := model.DeployCommand{Command: cmd, Name: cmd}
@@ -410,6 +528,34 @@ func (dc *destroyCommand) destroyHelmReleasesIfPresent(ctx context.Context, opts
 	return nil
 }
 
+// getHelmReleases returns the sorted names of the helm releases installed by the application
+func (dc *destroyCommand) getHelmReleases(ctx context.Context, namespace, labelSelector string) ([]string, error) {
+	sList, err := dc.secrets.List(ctx, namespace, labelSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	oktetoLog.Debugf("checking if application installed something with helm")
+	helmReleases := map[string]bool{}
+	for _, s := range sList {
+		if s.Type == model.HelmSecretType && s.Labels[ownerLabel] == helmOwner {
+			helmReleaseName, ok := s.Labels[nameLabel]
+			if !ok {
+				continue
+			}
+
+			helmReleases[helmReleaseName] = true
+		}
+	}
+
+	result := []string{}
+	for releaseName := range helmReleases {
+		result = append(result, releaseName)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
 func getTempKubeConfigFile(name string) string {
 	tempKubeconfigFileName := fmt.Sprintf("kubeconfig-destroy-%s-%d", name, time.Now().UnixMilli())
 	return filepath.Join(config.GetOktetoHome(), tempKubeconfigFileName)
@@ -15,17 +15,23 @@ package destroy
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/okteto/okteto/cmd/utils"
 	"github.com/okteto/okteto/internal/test"
 	"github.com/okteto/okteto/pkg/cmd/pipeline"
 	"github.com/okteto/okteto/pkg/k8s/configmaps"
+	"github.com/okteto/okteto/pkg/k8s/diverts"
 	"github.com/okteto/okteto/pkg/k8s/namespaces"
 	"github.com/okteto/okteto/pkg/model"
 	"github.com/okteto/okteto/pkg/okteto"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -50,10 +56,14 @@ var fakeManifest *model.Manifest = &model.Manifest{
 }
 
 type fakeDestroyer struct {
-	destroyed        bool
-	destroyedVolumes bool
-	err              error
-	errOnVolumes     error
+	destroyed          bool
+	destroyedVolumes   bool
+	destroyedInventory pipeline.Inventory
+	leftovers          pipeline.Inventory
+	err                error
+	errOnVolumes       error
+	labeled            []pipeline.InventoryItem
+	sfsVolumes         []string
 }
 
 type fakeSecretHandler struct {
@@ -84,6 +94,43 @@ func (fd *fakeDestroyer) DestroySFSVolumes(_ context.Context, _ string, _ namesp
 	return nil
 }
 
+func (fd *fakeDestroyer) DestroyInventory(_ context.Context, inventory pipeline.Inventory, _ namespaces.DeleteAllOptions) pipeline.Inventory {
+	fd.destroyedInventory = inventory
+	return fd.leftovers
+}
+
+func (fd *fakeDestroyer) ListWithLabel(_ context.Context, _ string, opts namespaces.DeleteAllOptions) ([]pipeline.InventoryItem, error) {
+	if fd.err != nil {
+		return nil, fd.err
+	}
+	result := []pipeline.InventoryItem{}
+	for _, item := range fd.labeled {
+		if item.Kind == volumeKind && !opts.IncludeVolumes {
+			continue
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+func (fd *fakeDestroyer) ListInventory(_ context.Context, inventory pipeline.Inventory, opts namespaces.DeleteAllOptions) ([]pipeline.InventoryItem, error) {
+	result := []pipeline.InventoryItem{}
+	for _, item := range inventory {
+		if item.Kind == volumeKind && !opts.IncludeVolumes {
+			continue
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+func (fd *fakeDestroyer) ListSFSVolumes(_ context.Context, _ string, opts namespaces.DeleteAllOptions) ([]string, error) {
+	if !opts.IncludeVolumes {
+		return []string{}, nil
+	}
+	return fd.sfsVolumes, nil
+}
+
 func (fd *fakeSecretHandler) List(_ context.Context, _, _ string) ([]v1.Secret, error) {
 	if fd.err != nil {
 		return nil, fd.err
@@ -878,6 +925,49 @@ func TestDestroyWithoutForceOptionAndFailedCommands(t *testing.T) {
 	assert.NotNil(t, cfg)
 }
 
+func TestDestroyExpandsVarFileVariables(t *testing.T) {
+	ctx := context.Background()
+	okteto.CurrentStore = &okteto.OktetoContextStore{
+		Contexts: map[string]*okteto.OktetoContext{
+			"test": {
+				Namespace: "test",
+			},
+		},
+		CurrentContext: "test",
+	}
+	// restores the variable set by the destroy command
+	t.Setenv("DESTROY_TARGET", "")
+
+	varFile := filepath.Join(t.TempDir(), "vars.env")
+	require.NoError(t, os.WriteFile(varFile, []byte("DESTROY_TARGET=staging\n"), 0600))
+	variables, err := utils.LoadVariables([]string{varFile}, nil)
+	require.NoError(t, err)
+
+	executor := &fakeExecutor{}
+	k8sClientProvider := test.NewFakeK8sProvider()
+	fakeClient, _, err := k8sClientProvider.Provide(api.NewConfig())
+	if err != nil {
+		t.Fatal("could not create fake k8s client")
+	}
+	cmd := &destroyCommand{
+		getManifest: func(_ string) (*model.Manifest, error) {
+			return &model.Manifest{
+				Destroy: []model.DeployCommand{{Name: "cleanup", Command: "cleanup ${DESTROY_TARGET}"}},
+			}, nil
+		},
+		secrets:           &fakeSecretHandler{},
+		executor:          executor,
+		nsDestroyer:       &fakeDestroyer{},
+		k8sClientProvider: k8sClientProvider,
+		configMapHandler:  newConfigmapHandler(fakeClient),
+	}
+
+	err = cmd.runDestroy(ctx, &Options{Name: "test-app", Variables: variables})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []model.DeployCommand{{Name: "cleanup", Command: "cleanup staging"}}, executor.executed)
+}
+
 func TestDestroyWithForceOptionAndFailedCommands(t *testing.T) {
 	ctx := context.Background()
 	okteto.CurrentStore = &okteto.OktetoContextStore{
@@ -924,3 +1014,274 @@ func TestDestroyWithForceOptionAndFailedCommands(t *testing.T) {
 	cfg, _ := configmaps.Get(ctx, pipeline.TranslatePipelineName(opts.Name), okteto.Context().Namespace, fakeClient)
 	assert.Nil(t, cfg)
 }
+
+func TestDestroyInventory(t *testing.T) {
+	ctx := context.Background()
+	okteto.CurrentStore = &okteto.OktetoContextStore{
+		Contexts: map[string]*okteto.OktetoContext{
+			"test": {
+				Namespace: "test",
+			},
+		},
+		CurrentContext: "test",
+	}
+	inventory := pipeline.Inventory{
+		{APIVersion: "apps/v1", Kind: "Deployment", Resource: "deployments", Namespace: "test", Name: "api", UID: "1"},
+		{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole", Resource: "clusterroles", Name: "api", UID: "2"},
+	}
+	encodedInventory, err := json.Marshal(inventory)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		forceDestroy  bool
+		leftovers     pipeline.Inventory
+		expectedErr   bool
+		expectedLabel bool
+		expectedCfg   bool
+	}{
+		{
+			name:          "all destroyed",
+			expectedLabel: true,
+		},
+		{
+			name:        "with leftovers",
+			leftovers:   inventory[1:],
+			expectedErr: true,
+			expectedCfg: true,
+		},
+		{
+			name:          "with leftovers and force destroy",
+			forceDestroy:  true,
+			leftovers:     inventory[1:],
+			expectedLabel: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmap := &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      pipeline.TranslatePipelineName("test-app"),
+					Namespace: "test",
+					Labels: map[string]string{
+						model.GitDeployLabel: "true",
+					},
+				},
+				Data: map[string]string{
+					"inventory": string(encodedInventory),
+				},
+			}
+			k8sClientProvider := test.NewFakeK8sProvider(cmap)
+			fakeClient, _, err := k8sClientProvider.Provide(api.NewConfig())
+			if err != nil {
+				t.Fatal("could not create fake k8s client")
+			}
+			destroyer := &fakeDestroyer{
+				leftovers: tt.leftovers,
+			}
+			cmd := &destroyCommand{
+				getManifest:       getFakeManifest,
+				secrets:           &fakeSecretHandler{},
+				executor:          &fakeExecutor{},
+				nsDestroyer:       destroyer,
+				k8sClientProvider: k8sClientProvider,
+				configMapHandler:  newConfigmapHandler(fakeClient),
+			}
+
+			err = cmd.runDestroy(ctx, &Options{Name: "test-app", ForceDestroy: tt.forceDestroy})
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, inventory, destroyer.destroyedInventory)
+			assert.Equal(t, tt.expectedLabel, destroyer.destroyed)
+
+			cfg, _ := configmaps.Get(ctx, pipeline.TranslatePipelineName("test-app"), "test", fakeClient)
+			if !tt.expectedCfg {
+				assert.Nil(t, cfg)
+				return
+			}
+			assert.NotNil(t, cfg)
+			remaining, err := pipeline.GetInventory(cfg)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.leftovers, remaining)
+		})
+	}
+}
+
+func TestDestroyLocked(t *testing.T) {
+	ctx := context.Background()
+	okteto.CurrentStore = &okteto.OktetoContextStore{
+		Contexts: map[string]*okteto.OktetoContext{
+			"test": {
+				Namespace: "test",
+			},
+		},
+		CurrentContext: "test",
+	}
+	now := time.Now().UTC()
+	lease, err := json.Marshal(pipeline.Lease{ID: "other", Holder: "cindy@laptop", Operation: "deploy", AcquiredAt: now, RenewedAt: now, ExpiresAt: now.Add(time.Minute)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pipeline.TranslatePipelineName("test-app"),
+			Namespace: "test",
+			Labels: map[string]string{
+				model.GitDeployLabel: "true",
+			},
+		},
+		Data: map[string]string{
+			"lease": string(lease),
+		},
+	}
+	k8sClientProvider := test.NewFakeK8sProvider(cmap)
+	fakeClient, _, err := k8sClientProvider.Provide(api.NewConfig())
+	if err != nil {
+		t.Fatal("could not create fake k8s client")
+	}
+	executor := &fakeExecutor{}
+	destroyer := &fakeDestroyer{}
+	cmd := &destroyCommand{
+		getManifest:       getFakeManifest,
+		secrets:           &fakeSecretHandler{},
+		executor:          executor,
+		nsDestroyer:       destroyer,
+		k8sClientProvider: k8sClientProvider,
+		configMapHandler:  newConfigmapHandler(fakeClient),
+	}
+
+	err = cmd.runDestroy(ctx, &Options{Name: "test-app"})
+	assert.Error(t, err)
+	assert.Empty(t, executor.executed)
+	assert.False(t, destroyer.destroyed)
+
+	err = cmd.runDestroy(ctx, &Options{Name: "test-app", ForceUnlock: true})
+	assert.NoError(t, err)
+	assert.True(t, destroyer.destroyed)
+}
+
+func TestDestroyDryRun(t *testing.T) {
+	ctx := context.Background()
+	okteto.CurrentStore = &okteto.OktetoContextStore{
+		Contexts: map[string]*okteto.OktetoContext{
+			"test": {
+				Namespace: "test",
+			},
+		},
+		CurrentContext: "test",
+	}
+	secretHandler := &fakeSecretHandler{
+		secrets: []v1.Secret{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{ownerLabel: helmOwner, nameLabel: "chart"},
+				},
+				Type: model.HelmSecretType,
+			},
+		},
+	}
+	labeled := []pipeline.InventoryItem{
+		{Kind: "Deployment", Name: "web"},
+		{Kind: "Service", Name: "web"},
+		{Kind: "Deployment", Name: "api"},
+		{Kind: "PersistentVolumeClaim", Name: "data"},
+	}
+	inventory := pipeline.Inventory{
+		{APIVersion: "apps/v1", Kind: "Deployment", Resource: "deployments", Namespace: "test", Name: "web"},
+		{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole", Resource: "clusterroles", Name: "web"},
+		{APIVersion: "v1", Kind: "ConfigMap", Resource: "configmaps", Namespace: "shared", Name: "settings"},
+		{APIVersion: "v1", Kind: "PersistentVolumeClaim", Resource: "persistentvolumeclaims", Namespace: "test", Name: "data"},
+		{APIVersion: "v1", Kind: "PersistentVolumeClaim", Resource: "persistentvolumeclaims", Namespace: "test", Name: "cache"},
+	}
+	encodedInventory, err := json.Marshal(inventory)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		destroyVolumes bool
+		expected       *dryRunReport
+	}{
+		{
+			name: "without volumes",
+			expected: &dryRunReport{
+				Name:         "test-app",
+				Namespace:    "test",
+				Commands:     []string{"printenv", "ls -la", "cat /tmp/test.txt"},
+				Dependencies: []string{},
+				HelmReleases: []string{"chart"},
+				Resources: map[string][]string{
+					"Deployment": {"api", "web"},
+					"Service":    {"web"},
+				},
+				Volumes:   []string{},
+				Inventory: []pipeline.InventoryItem{inventory[1], inventory[2]},
+				Divert:    []diverts.DestroyedResource{},
+			},
+		},
+		{
+			name:           "with volumes",
+			destroyVolumes: true,
+			expected: &dryRunReport{
+				Name:         "test-app",
+				Namespace:    "test",
+				Commands:     []string{"printenv", "ls -la", "cat /tmp/test.txt"},
+				Dependencies: []string{},
+				HelmReleases: []string{"chart"},
+				Resources: map[string][]string{
+					"Deployment": {"api", "web"},
+					"Service":    {"web"},
+				},
+				Volumes:   []string{"data", "data-db-0"},
+				Inventory: []pipeline.InventoryItem{inventory[1], inventory[2], inventory[4]},
+				Divert:    []diverts.DestroyedResource{},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := &fakeExecutor{}
+			destroyer := &fakeDestroyer{labeled: labeled, sfsVolumes: []string{"data-db-0"}}
+			k8sClientProvider := test.NewFakeK8sProvider()
+			fakeClient, _, err := k8sClientProvider.Provide(api.NewConfig())
+			if err != nil {
+				t.Fatal("could not create fake k8s client")
+			}
+			cmap := &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: pipeline.TranslatePipelineName("test-app"), Namespace: "test"},
+				Data:       map[string]string{"inventory": string(encodedInventory), "status": pipeline.DeployedStatus},
+			}
+			_, err = fakeClient.CoreV1().ConfigMaps("test").Create(ctx, cmap, metav1.CreateOptions{})
+			require.NoError(t, err)
+			cmd := &destroyCommand{
+				getManifest:       getFakeManifest,
+				secrets:           secretHandler,
+				nsDestroyer:       destroyer,
+				executor:          executor,
+				k8sClientProvider: k8sClientProvider,
+				configMapHandler:  newConfigmapHandler(fakeClient),
+			}
+			opts := &Options{Name: "test-app", DestroyVolumes: tt.destroyVolumes, DryRun: true, Output: "json"}
+
+			report, err := cmd.getDryRunReport(ctx, opts, fakeManifest, "test")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, report)
+
+			err = cmd.runDestroy(ctx, opts)
+			assert.NoError(t, err)
+			assert.Empty(t, executor.executed)
+			assert.False(t, destroyer.destroyed)
+			assert.False(t, destroyer.destroyedVolumes)
+
+			// the configmap is not updated in dry-run mode
+			cmap, err = configmaps.Get(ctx, pipeline.TranslatePipelineName(opts.Name), "test", fakeClient)
+			assert.NoError(t, err)
+			assert.Equal(t, pipeline.DeployedStatus, cmap.Data["status"])
+		})
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package destroy
+
+import (
+	"context"
+
+	"github.com/okteto/okteto/pkg/k8s/diverts"
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/okteto/okteto/pkg/okteto"
+	"k8s.io/client-go/dynamic"
+)
+
+// destroyDivert deletes the divert resources that are not destroyed with the development environment
+func (dc *destroyCommand) destroyDivert(ctx context.Context, manifest *model.Manifest, name, namespace string) error {
+	driver, err := dc.getDivertDriver(manifest, name, namespace)
+	if err != nil {
+		return err
+	}
+	return driver.Destroy(ctx)
+}
+
+// listDivert returns the divert resources that destroyDivert would delete or update
+func (dc *destroyCommand) listDivert(ctx context.Context, manifest *model.Manifest, name, namespace string) ([]diverts.DestroyedResource, error) {
+	driver, err := dc.getDivertDriver(manifest, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return driver.ListDestroyed(ctx)
+}
+
+func (dc *destroyCommand) getDivertDriver(manifest *model.Manifest, name, namespace string) (diverts.Driver, error) {
+	c, cfg, err := dc.k8sClientProvider.Provide(okteto.Context().Cfg)
+	if err != nil {
+		return nil, err
+	}
+	var dynClient dynamic.Interface
+	if manifest.Deploy.Divert.GetDriver() == model.DivertIstioDriver {
+		dynClient, err = dynamic.NewForConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	m := *manifest
+	m.Name = name
+	m.Namespace = namespace
+	return diverts.NewDriver(&m, c, dynClient)
+}
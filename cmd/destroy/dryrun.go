@@ -0,0 +1,251 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package destroy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/okteto/okteto/pkg/cmd/pipeline"
+	"github.com/okteto/okteto/pkg/k8s/diverts"
+	"github.com/okteto/okteto/pkg/k8s/namespaces"
+	oktetoLog "github.com/okteto/okteto/pkg/log"
+	"github.com/okteto/okteto/pkg/model"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+const volumeKind = "PersistentVolumeClaim"
+
+// dryRunReport is the list of everything that a destroy would remove
+type dryRunReport struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Commands are the destroy commands of the manifest, in execution order
+	Commands []string `json:"commands"`
+	// Dependencies are the dependencies destroyed with the '--dependencies' flag
+	Dependencies []string `json:"dependencies"`
+	HelmReleases []string `json:"helmReleases"`
+	// Resources are the names of the resources with the deployed-by label, grouped by kind
+	Resources map[string][]string `json:"resources"`
+	// Volumes are the persistent volume claims deleted with the '--volumes' flag
+	Volumes []string `json:"volumes"`
+	// Inventory are the objects created by the deploys that are not listed in Resources or Volumes:
+	// cluster-scoped objects, objects of other namespaces and objects without the deployed-by label
+	Inventory []pipeline.InventoryItem `json:"inventory"`
+	// Divert are the divert resources of other namespaces deleted or updated by the destroy
+	Divert []diverts.DestroyedResource `json:"divert"`
+}
+
+func validateOutput(output string) error {
+	switch output {
+	case "", "json":
+		return nil
+	default:
+		return fmt.Errorf("output format is not accepted. Value must be one of: ['json']")
+	}
+}
+
+// runDryRun shows what the destroy would remove without removing anything
+func (dc *destroyCommand) runDryRun(ctx context.Context, opts *Options, manifest *model.Manifest, namespace string) error {
+	report, err := dc.getDryRunReport(ctx, opts, manifest, namespace)
+	if err != nil {
+		return err
+	}
+	return showDryRunReport(report, opts.Output)
+}
+
+func (dc *destroyCommand) getDryRunReport(ctx context.Context, opts *Options, manifest *model.Manifest, namespace string) (*dryRunReport, error) {
+	report := &dryRunReport{
+		Name:         opts.Name,
+		Namespace:    namespace,
+		Commands:     []string{},
+		Dependencies: []string{},
+		HelmReleases: []string{},
+		Resources:    map[string][]string{},
+		Volumes:      []string{},
+		Inventory:    []pipeline.InventoryItem{},
+		Divert:       []diverts.DestroyedResource{},
+	}
+
+	for _, command := range manifest.Destroy {
+		report.Commands = append(report.Commands, command.Name)
+	}
+	if opts.DestroyDependencies {
+		for depName := range manifest.Dependencies {
+			report.Dependencies = append(report.Dependencies, depName)
+		}
+		sort.Strings(report.Dependencies)
+	}
+
+	deployedByLs, err := labels.NewRequirement(
+		model.DeployedByLabel,
+		selection.Equals,
+		[]string{opts.Name},
+	)
+	if err != nil {
+		return nil, err
+	}
+	deployedBySelector := labels.NewSelector().Add(*deployedByLs).String()
+	listOpts := namespaces.DeleteAllOptions{
+		LabelSelector:  deployedBySelector,
+		IncludeVolumes: opts.DestroyVolumes,
+	}
+
+	if manifest.Deploy != nil && manifest.Deploy.Helm != nil {
+		release, err := model.ExpandEnv(manifest.Deploy.Helm.Release, false)
+		if err != nil {
+			return nil, err
+		}
+		if release == "" {
+			release = opts.Name
+		}
+		report.HelmReleases = append(report.HelmReleases, release)
+	}
+	helmReleases, err := dc.getHelmReleases(ctx, namespace, deployedBySelector)
+	if err != nil {
+		return nil, err
+	}
+	for _, release := range helmReleases {
+		if len(report.HelmReleases) > 0 && report.HelmReleases[0] == release {
+			continue
+		}
+		report.HelmReleases = append(report.HelmReleases, release)
+	}
+
+	items, err := dc.nsDestroyer.ListWithLabel(ctx, namespace, listOpts)
+	if err != nil {
+		return nil, fmt.Errorf("could not list the resources of '%s': %w", opts.Name, err)
+	}
+	for _, item := range items {
+		if item.Kind == volumeKind {
+			report.Volumes = append(report.Volumes, item.Name)
+			continue
+		}
+		report.Resources[item.Kind] = append(report.Resources[item.Kind], item.Name)
+	}
+	for kind := range report.Resources {
+		sort.Strings(report.Resources[kind])
+	}
+
+	sfsVolumes, err := dc.nsDestroyer.ListSFSVolumes(ctx, namespace, listOpts)
+	if err != nil {
+		return nil, err
+	}
+	report.Volumes = append(report.Volumes, sfsVolumes...)
+	sort.Strings(report.Volumes)
+
+	cfg, err := dc.configMapHandler.getConfigMap(ctx, opts.Name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	inventory, err := pipeline.GetInventory(cfg)
+	if err != nil {
+		return nil, err
+	}
+	inventoryItems, err := dc.nsDestroyer.ListInventory(ctx, inventory, listOpts)
+	if err != nil {
+		return nil, fmt.Errorf("could not list the inventory of '%s': %w", opts.Name, err)
+	}
+	labeled := map[string]bool{}
+	for _, item := range items {
+		labeled[getDryRunKey(item.Kind, namespace, item.Name)] = true
+	}
+	for _, volume := range sfsVolumes {
+		labeled[getDryRunKey(volumeKind, namespace, volume)] = true
+	}
+	for _, item := range inventoryItems {
+		if !labeled[getDryRunKey(item.Kind, item.Namespace, item.Name)] {
+			report.Inventory = append(report.Inventory, item)
+		}
+	}
+
+	if manifest.Deploy != nil && manifest.Deploy.Divert != nil && manifest.Deploy.Divert.Namespace != namespace {
+		divert, err := dc.listDivert(ctx, manifest, opts.Name, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("could not list the divert resources of '%s': %w", opts.Name, err)
+		}
+		report.Divert = append(report.Divert, divert...)
+	}
+
+	return report, nil
+}
+
+func getDryRunKey(kind, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+}
+
+func showDryRunReport(report *dryRunReport, output string) error {
+	if output == "json" {
+		bytes, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		oktetoLog.Println(string(bytes))
+		return nil
+	}
+
+	oktetoLog.Information("Destroying '%s' would remove:", report.Name)
+	w := tabwriter.NewWriter(os.Stdout, 1, 1, 2, ' ', 0)
+	fmt.Fprintf(w, "Kind\tName\n")
+	for _, command := range report.Commands {
+		fmt.Fprintf(w, "Command\t%s\n", command)
+	}
+	for _, dep := range report.Dependencies {
+		fmt.Fprintf(w, "Dependency\t%s\n", dep)
+	}
+	for _, release := range report.HelmReleases {
+		fmt.Fprintf(w, "HelmRelease\t%s\n", release)
+	}
+	kinds := []string{}
+	for kind := range report.Resources {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		for _, name := range report.Resources[kind] {
+			fmt.Fprintf(w, "%s\t%s\n", kind, name)
+		}
+	}
+	for _, volume := range report.Volumes {
+		fmt.Fprintf(w, "%s\t%s\n", volumeKind, volume)
+	}
+	for _, item := range report.Inventory {
+		fmt.Fprintf(w, "%s\t%s\n", item.Kind, getDryRunName(item.Namespace, item.Name, report.Namespace))
+	}
+	for _, resource := range report.Divert {
+		name := getDryRunName(resource.Namespace, resource.Name, report.Namespace)
+		if resource.Action == diverts.UpdateAction {
+			name = fmt.Sprintf("%s (updated)", name)
+		}
+		fmt.Fprintf(w, "%s\t%s\n", resource.Kind, name)
+	}
+	return w.Flush()
+}
+
+// getDryRunName qualifies the names of the resources that are cluster-scoped or in other namespaces
+func getDryRunName(namespace, name, reportNamespace string) string {
+	switch namespace {
+	case reportNamespace:
+		return name
+	case "":
+		return fmt.Sprintf("%s (cluster)", name)
+	default:
+		return fmt.Sprintf("%s/%s", namespace, name)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package destroy
+
+import (
+	"context"
+
+	"github.com/okteto/okteto/pkg/cmd/helm"
+	"github.com/okteto/okteto/pkg/cmd/kustomize"
+	"github.com/okteto/okteto/pkg/k8s/kubeconfig"
+	oktetoLog "github.com/okteto/okteto/pkg/log"
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/okteto/okteto/pkg/okteto"
+	"k8s.io/client-go/dynamic"
+)
+
+// destroyHelmRelease uninstalls the release of the helm section of the manifest
+func (dc *destroyCommand) destroyHelmRelease(spec *model.HelmDeploy, name, namespace string) error {
+	release, err := model.ExpandEnv(spec.Release, false)
+	if err != nil {
+		return err
+	}
+	if release == "" {
+		release = name
+	}
+	_, cfg, err := dc.k8sClientProvider.Provide(okteto.Context().Cfg)
+	if err != nil {
+		return err
+	}
+	oktetoLog.Information("Uninstalling helm release '%s'", release)
+	return helm.Uninstall(release, namespace, kubeconfig.NewRESTClientGetter(cfg, namespace))
+}
+
+// destroyKustomization deletes the resources of the kustomization of the manifest
+func (dc *destroyCommand) destroyKustomization(ctx context.Context, spec *model.KustomizeDeploy, namespace string) error {
+	path, err := model.ExpandEnv(spec.Path, false)
+	if err != nil {
+		return err
+	}
+	objs, err := kustomize.Build(path, nil)
+	if err != nil {
+		return err
+	}
+	_, cfg, err := dc.k8sClientProvider.Provide(okteto.Context().Cfg)
+	if err != nil {
+		return err
+	}
+	dynClient, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+	mapper, err := kubeconfig.NewRESTClientGetter(cfg, namespace).ToRESTMapper()
+	if err != nil {
+		return err
+	}
+	oktetoLog.Information("Deleting kustomization '%s'", path)
+	return kustomize.Delete(ctx, objs, namespace, dynClient, mapper)
+}
@@ -195,6 +195,10 @@ func runDown(ctx context.Context, dev *model.Dev, rm bool) error {
 			return
 		}
 
+		if app.Kind() == model.DaemonSet && apps.IsDevModeOn(app) {
+			oktetoLog.Warning(apps.DaemonSetRolloutWarning, app.ObjectMeta().Name)
+		}
+
 		if err := down.Run(dev, app, trMap, true, c); err != nil {
 			exit <- err
 			return
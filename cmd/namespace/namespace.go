@@ -57,5 +57,6 @@ func Namespace(ctx context.Context) *cobra.Command {
 	cmd.AddCommand(List(ctx))
 	cmd.AddCommand(Create(ctx))
 	cmd.AddCommand(Delete(ctx))
+	cmd.AddCommand(Prune(ctx))
 	return cmd
 }
@@ -0,0 +1,139 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package namespace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	contextCMD "github.com/okteto/okteto/cmd/context"
+	"github.com/okteto/okteto/cmd/utils"
+	"github.com/okteto/okteto/pkg/k8s/namespaces"
+	oktetoLog "github.com/okteto/okteto/pkg/log"
+	"github.com/okteto/okteto/pkg/okteto"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// PruneOptions defines the options to prune a namespace
+type PruneOptions struct {
+	Namespace string
+	// OlderThan only prunes the resources created before this length of time
+	OlderThan time.Duration
+	// Yes skips the confirmation
+	Yes bool
+}
+
+// Prune removes the resources created by okteto that are no longer used
+func Prune(ctx context.Context) *cobra.Command {
+	options := &PruneOptions{}
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove the resources created by okteto that are no longer used",
+		Long: `Remove the resources created by okteto that are no longer used:
+development container clones whose original workload was deleted, okteto secrets not used by any development container, development volumes whose workload was deleted,
+and the configmaps of development environments whose resources were deleted without running 'okteto destroy'`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := contextCMD.NewContextCommand().Run(ctx, &contextCMD.ContextOptions{Namespace: options.Namespace}); err != nil {
+				return err
+			}
+			if options.Namespace == "" {
+				options.Namespace = okteto.Context().Namespace
+			}
+
+			c, _, err := okteto.GetK8sClient()
+			if err != nil {
+				return err
+			}
+			dynClient, _, err := okteto.GetDynamicClient()
+			if err != nil {
+				return err
+			}
+			return executePrune(ctx, options, c, dynClient)
+		},
+		Args: utils.NoArgsAccepted(""),
+	}
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "namespace to prune (defaults to the current namespace)")
+	cmd.Flags().DurationVar(&options.OlderThan, "older-than", 0, "only remove the resources created before this length of time, e.g. 72h")
+	cmd.Flags().BoolVarP(&options.Yes, "yes", "y", false, "remove the resources without asking for confirmation")
+	return cmd
+}
+
+func executePrune(ctx context.Context, opts *PruneOptions, c kubernetes.Interface, dynClient dynamic.Interface) error {
+	oktetoLog.Spinner(fmt.Sprintf("Looking for unused resources in namespace '%s'...", opts.Namespace))
+	oktetoLog.StartSpinner()
+	orphans, err := namespaces.ListOrphans(ctx, opts.Namespace, c, dynClient)
+	oktetoLog.StopSpinner()
+	if err != nil {
+		return err
+	}
+	orphans = filterOrphansOlderThan(orphans, opts.OlderThan, time.Now())
+	if len(orphans) == 0 {
+		oktetoLog.Success("There are no unused resources in namespace '%s'", opts.Namespace)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 1, 1, 2, ' ', 0)
+	fmt.Fprintf(w, "Kind\tName\tAge\tReason\n")
+	for _, o := range orphans {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", o.Kind, o.Name, getAge(o.CreationTimestamp), o.Reason)
+	}
+	w.Flush()
+
+	if !opts.Yes {
+		answer, err := utils.AskYesNo(fmt.Sprintf("Do you want to remove these %d resources? [y/n]: ", len(orphans)))
+		if err != nil {
+			return err
+		}
+		if !answer {
+			oktetoLog.Information("No resources were removed")
+			return nil
+		}
+	}
+
+	for _, o := range orphans {
+		if err := namespaces.DeleteOrphan(ctx, opts.Namespace, o, c, dynClient); err != nil {
+			return fmt.Errorf("failed to remove %s '%s': %w", o.Kind, o.Name, err)
+		}
+		oktetoLog.Infof("removed %s '%s'", o.Kind, o.Name)
+	}
+	oktetoLog.Success("%d unused resources removed from namespace '%s'", len(orphans), opts.Namespace)
+	return nil
+}
+
+// filterOrphansOlderThan returns the orphans created before olderThan. Zero returns all the orphans
+func filterOrphansOlderThan(orphans []namespaces.Orphan, olderThan time.Duration, now time.Time) []namespaces.Orphan {
+	if olderThan == 0 {
+		return orphans
+	}
+	result := []namespaces.Orphan{}
+	for _, o := range orphans {
+		if now.Sub(o.CreationTimestamp) >= olderThan {
+			result = append(result, o)
+		}
+	}
+	return result
+}
+
+func getAge(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return duration.HumanDuration(time.Since(t))
+}
@@ -0,0 +1,64 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package namespace
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/okteto/okteto/pkg/k8s/namespaces"
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicFake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_filterOrphansOlderThan(t *testing.T) {
+	now := time.Now()
+	orphans := []namespaces.Orphan{
+		{Kind: "Secret", Name: "new", CreationTimestamp: now.Add(-time.Hour)},
+		{Kind: "Secret", Name: "old", CreationTimestamp: now.Add(-48 * time.Hour)},
+	}
+
+	assert.Equal(t, orphans, filterOrphansOlderThan(orphans, 0, now))
+	assert.Equal(t, orphans[1:], filterOrphansOlderThan(orphans, 24*time.Hour, now))
+	assert.Empty(t, filterOrphansOlderThan(orphans, 72*time.Hour, now))
+}
+
+func Test_executePrune(t *testing.T) {
+	ctx := context.Background()
+	devLabels := map[string]string{model.DevLabel: "true"}
+	c := fake.NewSimpleClientset(
+		&apiv1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "okteto-old", Namespace: "test", Labels: devLabels, CreationTimestamp: metav1.NewTime(time.Now().Add(-48 * time.Hour))},
+		},
+		&apiv1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "okteto-new", Namespace: "test", Labels: devLabels, CreationTimestamp: metav1.NewTime(time.Now())},
+		},
+	)
+
+	dynClient := dynamicFake.NewSimpleDynamicClient(runtime.NewScheme())
+
+	err := executePrune(ctx, &PruneOptions{Namespace: "test", OlderThan: 24 * time.Hour, Yes: true}, c, dynClient)
+	assert.NoError(t, err)
+
+	sList, err := c.CoreV1().Secrets("test").List(ctx, metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, sList.Items, 1)
+	assert.Equal(t, "okteto-new", sList.Items[0].Name)
+}
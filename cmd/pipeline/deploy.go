@@ -45,6 +45,7 @@ type DeployOptions struct {
 	Timeout      time.Duration
 	File         string
 	Variables    []string
+	VarFiles     []string
 
 	// Deprecated fields
 	Filename string
@@ -57,6 +58,12 @@ func deploy(ctx context.Context) *cobra.Command {
 		Short: "Deploy an okteto pipeline",
 		Args:  utils.NoArgsAccepted("https://www.okteto.com/docs/reference/cli/#deploy-1"),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			variables, err := utils.LoadVariables(opts.VarFiles, opts.Variables)
+			if err != nil {
+				return err
+			}
+			opts.Variables = variables
+
 			ctxResource := &model.ContextResource{}
 			if err := ctxResource.UpdateNamespace(opts.Namespace); err != nil {
 				return err
@@ -90,6 +97,7 @@ func deploy(ctx context.Context) *cobra.Command {
 	cmd.Flags().BoolVarP(&opts.SkipIfExists, "skip-if-exists", "", false, "skip the pipeline deployment if the pipeline already exists in the namespace (defaults to false)")
 	cmd.Flags().DurationVarP(&opts.Timeout, "timeout", "t", (5 * time.Minute), "the length of time to wait for completion, zero means never. Any other values should contain a corresponding time unit e.g. 1s, 2m, 3h ")
 	cmd.Flags().StringArrayVarP(&opts.Variables, "var", "v", []string{}, "set a pipeline variable (can be set more than once)")
+	cmd.Flags().StringArrayVar(&opts.VarFiles, "var-file", []string{}, utils.VarFileFlagUsage)
 	cmd.Flags().StringVarP(&opts.File, "file", "f", "", "relative path within the repository to the manifest file (default to okteto-pipeline.yaml or .okteto/okteto-pipeline.yaml)")
 	cmd.Flags().StringVarP(&opts.Filename, "filename", "", "", "relative path within the repository to the manifest file (default to okteto-pipeline.yaml or .okteto/okteto-pipeline.yaml)")
 	cmd.Flags().MarkHidden("filename")
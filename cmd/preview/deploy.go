@@ -48,6 +48,7 @@ type DeployOptions struct {
 	sourceUrl  string
 	timeout    time.Duration
 	variables  []string
+	varFiles   []string
 	wait       bool
 }
 
@@ -68,6 +69,12 @@ func Deploy(ctx context.Context) *cobra.Command {
 				return err
 			}
 
+			variables, err := utils.LoadVariables(opts.varFiles, opts.variables)
+			if err != nil {
+				return err
+			}
+			opts.variables = variables
+
 			if err := contextCMD.NewContextCommand().Run(ctx, &contextCMD.ContextOptions{
 				Namespace: opts.name,
 				Show:      true,
@@ -92,6 +99,7 @@ func Deploy(ctx context.Context) *cobra.Command {
 	cmd.Flags().StringVarP(&opts.sourceUrl, "sourceUrl", "", "", "the URL of the original pull/merge request.")
 	cmd.Flags().DurationVarP(&opts.timeout, "timeout", "t", (5 * time.Minute), "the length of time to wait for completion, zero means never. Any other values should contain a corresponding time unit e.g. 1s, 2m, 3h ")
 	cmd.Flags().StringArrayVarP(&opts.variables, "var", "v", []string{}, "set a preview environment variable (can be set more than once)")
+	cmd.Flags().StringArrayVar(&opts.varFiles, "var-file", []string{}, utils.VarFileFlagUsage)
 	cmd.Flags().BoolVarP(&opts.wait, "wait", "w", false, "wait until the preview environment deployment finishes (defaults to false)")
 	cmd.Flags().StringVarP(&opts.file, "file", "f", "", "relative path within the repository to the okteto manifest (default to okteto.yaml or .okteto/okteto.yaml)")
 
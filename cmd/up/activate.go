@@ -115,6 +115,10 @@ func (up *upContext) activate() error {
 		lastPodUID = up.Pod.UID
 	}
 
+	if !up.isRetry && app.Kind() == model.DaemonSet {
+		oktetoLog.Warning(apps.DaemonSetRolloutWarning, app.ObjectMeta().Name)
+	}
+
 	if err := up.devMode(ctx, app, create); err != nil {
 		if oktetoErrors.IsTransient(err) {
 			return err
@@ -186,9 +190,14 @@ func (up *upContext) activate() error {
 			}
 
 		}
-		printDisplayContext(up)
+		if up.Multi == nil {
+			printDisplayContext(up)
+		}
 		durationActivateUp := time.Since(up.StartTime)
 		analytics.TrackDurationActivateUp(durationActivateUp)
+		if up.Dev.Watch != nil {
+			go up.startWatcher(ctx)
+		}
 		up.CommandResult <- up.runCommand(ctx, up.Dev.Command.Values)
 	}()
 
@@ -238,7 +247,7 @@ func (up *upContext) createDevContainer(ctx context.Context, app apps.App, creat
 	}
 
 	if up.Dev.PersistentVolumeEnabled() {
-		if err := volumes.CreateForDev(ctx, up.Dev, up.Client, up.Options.ManifestPath); err != nil {
+		if err := volumes.CreateForDev(ctx, up.Dev, apps.GetReference(app), up.Client, up.Options.ManifestPath); err != nil {
 			return err
 		}
 	}
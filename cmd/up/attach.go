@@ -0,0 +1,177 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package up
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/okteto/okteto/cmd/utils"
+	"github.com/okteto/okteto/pkg/config"
+	oktetoErrors "github.com/okteto/okteto/pkg/errors"
+	"github.com/okteto/okteto/pkg/k8s/apps"
+	"github.com/okteto/okteto/pkg/k8s/exec"
+	oktetoLog "github.com/okteto/okteto/pkg/log"
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/okteto/okteto/pkg/okteto"
+	"github.com/okteto/okteto/pkg/ssh"
+	"github.com/spf13/cobra"
+)
+
+// Attach opens a shell in a development container activated by another up session
+func Attach() *cobra.Command {
+	options := &sessionOptions{}
+	cmd := &cobra.Command{
+		Use:   "attach [svc]",
+		Short: "Open a shell in a development container running in the background",
+		Args:  utils.MaximumNArgsAccepted(1, "https://okteto.com/docs/reference/cli/#up"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if okteto.InDevContainer() {
+				return oktetoErrors.ErrNotInDevContainer
+			}
+
+			ctx := context.Background()
+			manifest, err := options.loadManifest(ctx)
+			if err != nil {
+				return err
+			}
+
+			devName := ""
+			if len(args) == 1 {
+				devName = args[0]
+			}
+			dev, err := utils.GetDevFromManifest(manifest, devName)
+			if err != nil {
+				if !errors.Is(err, utils.ErrNoDevSelected) {
+					return err
+				}
+				running := getRunningDevs(manifest)
+				if len(running) == 0 {
+					return errNoUpSessions
+				}
+				selector := utils.NewOktetoSelector("Select which development container to attach to:", "Development container")
+				dev, err = utils.SelectDevFromManifest(manifest, selector, running)
+				if err != nil {
+					return err
+				}
+			}
+
+			return attach(ctx, dev)
+		},
+	}
+	options.addFlags(cmd)
+	return cmd
+}
+
+// errNoUpSessions is returned when there isn't any up session running
+var errNoUpSessions = oktetoErrors.UserError{
+	E:    fmt.Errorf("there isn't any development container running"),
+	Hint: "Run 'okteto up --detach' to run a development container in the background",
+}
+
+func attach(ctx context.Context, dev *model.Dev) error {
+	if getRunningPID(dev.Namespace, dev.Name) == 0 {
+		return oktetoErrors.UserError{
+			E:    fmt.Errorf("development container '%s' isn't running", dev.Name),
+			Hint: fmt.Sprintf("Run 'okteto up %s --detach' to run it in the background", dev.Name),
+		}
+	}
+	state, err := config.GetState(dev)
+	if err != nil {
+		return err
+	}
+	if state != config.Ready {
+		return oktetoErrors.UserError{
+			E:    fmt.Errorf("development container '%s' isn't ready: %s", dev.Name, getStateMessage(state)),
+			Hint: "Run 'okteto up status' to check its progress",
+		}
+	}
+
+	oktetoLog.Spinner("Attaching to your development container...")
+	oktetoLog.StartSpinner()
+	defer oktetoLog.StopSpinner()
+
+	c, cfg, err := okteto.GetK8sClient()
+	if err != nil {
+		return err
+	}
+
+	devName := dev.Name
+	var devApp apps.App
+	if dev.Autocreate {
+		dev.Name = model.DevCloneName(dev.Name)
+		devApp, err = apps.Get(ctx, dev, dev.Namespace, c)
+		if err != nil {
+			return err
+		}
+	} else {
+		app, err := apps.Get(ctx, dev, dev.Namespace, c)
+		if err != nil {
+			return err
+		}
+		devApp = app.DevClone()
+	}
+
+	if err := devApp.Refresh(ctx, c); err != nil {
+		return err
+	}
+	pod, err := devApp.GetRunningPod(ctx, c)
+	if err != nil {
+		return err
+	}
+	container := apps.GetDevContainer(&pod.Spec, dev.Container)
+	if container == nil {
+		return fmt.Errorf("container '%s' does not exist in development container '%s'", dev.Container, devName)
+	}
+
+	if dev.PersistentSession {
+		var out bytes.Buffer
+		if err := exec.Exec(ctx, c, cfg, dev.Namespace, pod.Name, container.Name, false, strings.NewReader(""), &out, &out, []string{"sh", "-c", checkTmuxScript}); err != nil {
+			oktetoLog.Infof("failed to check the persistent session of development container '%s': %s", devName, err)
+		} else if !isTmuxInstalled(out.String()) {
+			oktetoLog.Warning(persistentSessionWarning)
+		}
+	}
+
+	cmd := getAttachCommand(dev)
+	if dev.RemoteModeEnabled() {
+		p, err := ssh.GetPort(devName)
+		if err != nil {
+			oktetoLog.Infof("failed to get the SSH port for %s: %s", devName, err)
+			return fmt.Errorf("failed to get the SSH port of development container '%s'", devName)
+		}
+		dev.RemotePort = p
+		dev.LoadRemote(ssh.GetPublicKey())
+		oktetoLog.StopSpinner()
+		return ssh.Exec(ctx, dev.Interface, dev.RemotePort, true, os.Stdin, os.Stdout, os.Stderr, cmd)
+	}
+	oktetoLog.StopSpinner()
+	return exec.Exec(ctx, c, cfg, dev.Namespace, pod.Name, container.Name, true, os.Stdin, os.Stdout, os.Stderr, cmd)
+}
+
+// getAttachCommand returns the shell to open in the development container
+func getAttachCommand(dev *model.Dev) []string {
+	shell := []string{"sh"}
+	if len(dev.Command.Values) == 1 {
+		switch dev.Command.Values[0] {
+		case "sh", "bash":
+			shell = dev.Command.Values
+		}
+	}
+	return getSessionCommand(dev, shell)
+}
@@ -0,0 +1,237 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package up
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	contextCMD "github.com/okteto/okteto/cmd/context"
+	"github.com/okteto/okteto/pkg/config"
+	oktetoErrors "github.com/okteto/okteto/pkg/errors"
+	oktetoLog "github.com/okteto/okteto/pkg/log"
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// detachedLogFile is the file where the output of a detached up session is written
+	detachedLogFile = "okteto-detached.log"
+
+	// detachedReadyTimeout is the time to wait for a detached up session to be ready
+	detachedReadyTimeout = 10 * time.Minute
+)
+
+// sessionOptions are the options of the commands that manage the up sessions
+type sessionOptions struct {
+	ManifestPath string
+	Namespace    string
+	K8sContext   string
+}
+
+func (o *sessionOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.ManifestPath, "file", "f", "", "path to the manifest file")
+	cmd.Flags().StringVarP(&o.Namespace, "namespace", "n", "", "namespace where the command is executed")
+	cmd.Flags().StringVarP(&o.K8sContext, "context", "c", "", "context where the command is executed")
+}
+
+// loadManifest loads the manifest of the up sessions
+func (o *sessionOptions) loadManifest(ctx context.Context) (*model.Manifest, error) {
+	if o.ManifestPath != "" {
+		manifestPath, err := model.UpdateCWDtoManifestPath(o.ManifestPath)
+		if err != nil {
+			return nil, err
+		}
+		o.ManifestPath = manifestPath
+	}
+	manifestOpts := contextCMD.ManifestOptions{Filename: o.ManifestPath, Namespace: o.Namespace, K8sContext: o.K8sContext}
+	return contextCMD.LoadManifestWithContext(ctx, manifestOpts)
+}
+
+// getRunningDevs returns the names of the development containers of the manifest with a running up session
+func getRunningDevs(manifest *model.Manifest) []string {
+	result := []string{}
+	names := manifest.Dev.GetDevs()
+	sort.Strings(names)
+	for _, name := range names {
+		dev := manifest.Dev[name]
+		if getRunningPID(dev.Namespace, dev.Name) != 0 {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// startDetached starts the up session in a background process and waits until the development containers are ready
+func (up *upContext) startDetached(devs []*model.Dev) error {
+	for _, dev := range devs {
+		if pid := getRunningPID(dev.Namespace, dev.Name); pid != 0 {
+			return oktetoErrors.UserError{
+				E:    fmt.Errorf("development container '%s' is already running in process %d", dev.Name, pid),
+				Hint: fmt.Sprintf("Run 'okteto up attach %s' to open a shell or 'okteto up stop %s' to stop it", dev.Name, dev.Name),
+			}
+		}
+		// a state file left by a previous session would be read as the state of the new one
+		if err := config.DeleteStateFile(dev); err != nil && !os.IsNotExist(err) {
+			oktetoLog.Infof("failed to delete state file of %s: %s", dev.Name, err)
+		}
+	}
+
+	args, err := getDetachedArgs(devs, up.Options)
+	if err != nil {
+		return err
+	}
+	bin, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get the okteto binary: %w", err)
+	}
+
+	logPath := filepath.Join(config.GetAppHome(devs[0].Namespace, devs[0].Name), detachedLogFile)
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create log file %s: %w", logPath, err)
+	}
+	defer logFile.Close()
+
+	cmd := osexec.Command(bin, args...)
+	cmd.Env = append(os.Environ(), fmt.Sprintf("%s=true", model.OktetoUpDetachedEnvVar))
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	setDetachedAttributes(cmd)
+	oktetoLog.Infof("starting detached up session: %s %s", bin, strings.Join(args, " "))
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start okteto up in the background: %w", err)
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	oktetoLog.Spinner("Activating your development container in the background...")
+	oktetoLog.StartSpinner()
+	err = waitUntilDetachedReady(devs, exited, detachedReadyTimeout)
+	oktetoLog.StopSpinner()
+	if err != nil {
+		return fmt.Errorf("%w\n    Find the output of okteto up at: %s", err, logPath)
+	}
+
+	for _, dev := range devs {
+		oktetoLog.Success("Development container '%s' is running in the background", dev.Name)
+	}
+	oktetoLog.Information("Run 'okteto up attach %s' to open a shell and 'okteto up stop' to stop it", devs[0].Name)
+	oktetoLog.Information("The output of okteto up is available at: %s", logPath)
+	return nil
+}
+
+// getDetachedArgs returns the arguments of the background okteto up process
+func getDetachedArgs(devs []*model.Dev, opts *UpOptions) ([]string, error) {
+	args := []string{"up"}
+	for _, dev := range devs {
+		args = append(args, dev.Name)
+	}
+	if opts.ManifestPath != "" {
+		manifestPath, err := filepath.Abs(opts.ManifestPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the path of the manifest: %w", err)
+		}
+		args = append(args, "--file", manifestPath)
+	}
+	if devs[0].Namespace != "" {
+		args = append(args, "--namespace", devs[0].Namespace)
+	}
+	if devs[0].Context != "" {
+		args = append(args, "--context", devs[0].Context)
+	}
+	for _, env := range opts.Envs {
+		args = append(args, "--env", env)
+	}
+	if opts.Remote > 0 {
+		args = append(args, "--remote", strconv.Itoa(opts.Remote))
+	}
+	if opts.ForcePull {
+		args = append(args, "--pull")
+	}
+	if opts.Reset {
+		args = append(args, "--reset")
+	}
+	return args, nil
+}
+
+// waitUntilDetachedReady waits until all the development containers are ready, or the background process exits
+func waitUntilDetachedReady(devs []*model.Dev, exited <-chan error, timeout time.Duration) error {
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	to := time.NewTimer(timeout)
+	defer to.Stop()
+
+	for {
+		select {
+		case err := <-exited:
+			if err != nil {
+				return fmt.Errorf("okteto up failed in the background: %w", err)
+			}
+			return errors.New("okteto up exited before the development container was ready")
+		case <-to.C:
+			return fmt.Errorf("development container wasn't ready after %s", timeout)
+		case <-ticker.C:
+			ready := true
+			for _, dev := range devs {
+				state, err := config.GetState(dev)
+				if err != nil {
+					// the background process hasn't created the state file yet
+					ready = false
+					continue
+				}
+				if state == config.Failed {
+					return fmt.Errorf("development container '%s' failed to start", dev.Name)
+				}
+				if state != config.Ready {
+					ready = false
+					if len(devs) == 1 {
+						oktetoLog.Spinner(getStateMessage(state) + "...")
+					}
+				}
+			}
+			if ready {
+				return nil
+			}
+		}
+	}
+}
+
+// isDetached returns if the up session runs in the background process started by 'okteto up --detach'
+func (up *upContext) isDetached() bool {
+	return up.Options != nil && up.Options.detached
+}
+
+// runDetachedCommand runs the command of a development container in a detached up session.
+// Interactive development containers don't run a command, the user opens a shell with 'okteto up attach'
+func (up *upContext) runDetachedCommand(ctx context.Context, cmd []string) error {
+	if up.getInteractive() {
+		oktetoLog.Println(fmt.Sprintf("Run 'okteto up attach %s' to open a shell", up.Dev.Name))
+		<-ctx.Done()
+		return nil
+	}
+
+	return up.runBackgroundCommand(ctx, cmd, os.Stdout, os.Stderr)
+}
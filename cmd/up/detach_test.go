@@ -0,0 +1,146 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package up
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/okteto/okteto/pkg/config"
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_getDetachedArgs(t *testing.T) {
+	manifestPath, err := filepath.Abs("okteto.yml")
+	assert.NoError(t, err)
+
+	var tests = []struct {
+		name     string
+		devs     []*model.Dev
+		opts     *UpOptions
+		expected []string
+	}{
+		{
+			name:     "default",
+			devs:     []*model.Dev{{Name: "api", Namespace: "ns", Context: "ctx"}},
+			opts:     &UpOptions{},
+			expected: []string{"up", "api", "--namespace", "ns", "--context", "ctx"},
+		},
+		{
+			name: "several-devs-with-options",
+			devs: []*model.Dev{{Name: "api", Namespace: "ns", Context: "ctx"}, {Name: "worker", Namespace: "ns", Context: "ctx"}},
+			opts: &UpOptions{
+				ManifestPath: "okteto.yml",
+				Envs:         []string{"A=1", "B=2"},
+				ForcePull:    true,
+				Reset:        true,
+				Deploy:       true,
+				Detach:       true,
+			},
+			expected: []string{"up", "api", "worker", "--file", manifestPath, "--namespace", "ns", "--context", "ctx", "--env", "A=1", "--env", "B=2", "--pull", "--reset"},
+		},
+		{
+			name:     "remote",
+			devs:     []*model.Dev{{Name: "api"}},
+			opts:     &UpOptions{Remote: 2222},
+			expected: []string{"up", "api", "--remote", "2222"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := getDetachedArgs(tt.devs, tt.opts)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, args)
+		})
+	}
+}
+
+func Test_waitUntilDetachedReady(t *testing.T) {
+	t.Setenv(model.OktetoFolderEnvVar, t.TempDir())
+	dev := &model.Dev{Name: "api", Namespace: "ns"}
+
+	t.Run("ready", func(t *testing.T) {
+		assert.NoError(t, config.UpdateStateFile(dev, config.Ready))
+		assert.NoError(t, waitUntilDetachedReady([]*model.Dev{dev}, make(chan error), time.Minute))
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		assert.NoError(t, config.UpdateStateFile(dev, config.Failed))
+		assert.Error(t, waitUntilDetachedReady([]*model.Dev{dev}, make(chan error), time.Minute))
+	})
+
+	t.Run("exited", func(t *testing.T) {
+		assert.NoError(t, config.DeleteStateFile(dev))
+		exited := make(chan error, 1)
+		exited <- errors.New("exit status 1")
+		assert.Error(t, waitUntilDetachedReady([]*model.Dev{dev}, exited, time.Minute))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		assert.NoError(t, config.UpdateStateFile(dev, config.Synchronizing))
+		assert.Error(t, waitUntilDetachedReady([]*model.Dev{dev}, make(chan error), time.Second))
+	})
+}
+
+func Test_getAttachCommand(t *testing.T) {
+	var tests = []struct {
+		name     string
+		command  []string
+		expected []string
+	}{
+		{name: "empty", expected: []string{"sh"}},
+		{name: "bash", command: []string{"bash"}, expected: []string{"bash"}},
+		{name: "non-interactive", command: []string{"yarn", "start"}, expected: []string{"sh"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			dev := &model.Dev{Command: model.Command{Values: tt.command}}
+			assert.Equal(t, tt.expected, getAttachCommand(dev))
+		})
+	}
+
+	dev := &model.Dev{Name: "api", PersistentSession: true, Command: model.Command{Values: []string{"bash"}}}
+	assert.Equal(t, getSessionCommand(dev, []string{"bash"}), getAttachCommand(dev))
+}
+
+func Test_printSessions(t *testing.T) {
+	t.Setenv(model.OktetoFolderEnvVar, t.TempDir())
+	manifest := &model.Manifest{
+		Dev: model.ManifestDevs{
+			"worker": {Name: "worker", Namespace: "ns"},
+			"api":    {Name: "api", Namespace: "ns"},
+		},
+	}
+	pid := startUpSession(t)
+	writePIDFile(t, "ns", "api", pid)
+	assert.NoError(t, config.UpdateStateFile(manifest.Dev["api"], config.Synchronizing))
+
+	var buf bytes.Buffer
+	printSessions(&buf, manifest)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 3)
+	assert.Equal(t, []string{"Dev", "PID", "Status"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"api", strconv.Itoa(pid), "synchronizing", "files"}, strings.Fields(lines[1]))
+	assert.Equal(t, []string{"worker", "-", "stopped"}, strings.Fields(lines[2]))
+}
@@ -17,6 +17,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
@@ -34,7 +35,7 @@ func (up *upContext) cleanCommand(ctx context.Context) {
 	in := strings.NewReader("\n")
 	var out bytes.Buffer
 
-	cmd := "cat /var/okteto/bin/version.txt; cat /proc/sys/fs/inotify/max_user_watches; /var/okteto/bin/clean >/dev/null 2>&1"
+	cmd := getCleanCommand(up.Dev)
 
 	err := exec.Exec(
 		ctx,
@@ -63,6 +64,17 @@ func (up *upContext) runCommand(ctx context.Context, cmd []string) error {
 		return err
 	}
 
+	if up.Multi != nil {
+		return up.runMultiCommand(ctx, cmd)
+	}
+
+	if up.isDetached() {
+		return up.runDetachedCommand(ctx, cmd)
+	}
+
+	up.checkPersistentSession(ctx)
+	cmd = getSessionCommand(up.Dev, cmd)
+
 	if up.Dev.RemoteModeEnabled() {
 		return ssh.Exec(ctx, up.Dev.Interface, up.Dev.RemotePort, true, os.Stdin, os.Stdout, os.Stderr, cmd)
 	}
@@ -82,6 +94,15 @@ func (up *upContext) runCommand(ctx context.Context, cmd []string) error {
 	)
 }
 
+// runBackgroundCommand runs a command in the development container without a terminal
+func (up *upContext) runBackgroundCommand(ctx context.Context, cmd []string, stdout, stderr io.Writer) error {
+	in := strings.NewReader("")
+	if up.Dev.RemoteModeEnabled() {
+		return ssh.Exec(ctx, up.Dev.Interface, up.Dev.RemotePort, false, in, stdout, stderr, cmd)
+	}
+	return exec.Exec(ctx, up.Client, up.RestConfig, up.Dev.Namespace, up.Pod.Name, up.Dev.Container, false, in, stdout, stderr, cmd)
+}
+
 func (up *upContext) checkOktetoStartError(ctx context.Context, msg string) error {
 	app, err := apps.Get(ctx, up.Dev, up.Dev.Namespace, up.Client)
 	if err != nil {
@@ -66,7 +66,7 @@ func (up *upContext) forwards(ctx context.Context) error {
 		return err
 	}
 
-	if isNeededGlobalForwarder(up.Manifest.GlobalForward) {
+	if up.startsGlobalForwards() && isNeededGlobalForwarder(up.Manifest.GlobalForward) {
 		up.GlobalForwarderStatus = make(chan error, 1)
 		go up.setGlobalForwardsIfRequiredLoop(ctx)
 	}
@@ -121,7 +121,7 @@ func (up *upContext) sshForwards(ctx context.Context) error {
 		return err
 	}
 
-	if isNeededGlobalForwarder(up.Manifest.GlobalForward) {
+	if up.startsGlobalForwards() && isNeededGlobalForwarder(up.Manifest.GlobalForward) {
 		up.GlobalForwarderStatus = make(chan error, 1)
 		go up.setGlobalForwardsIfRequiredLoop(ctx)
 	}
@@ -0,0 +1,369 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package up
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/moby/term"
+	"github.com/okteto/okteto/pkg/config"
+	oktetoErrors "github.com/okteto/okteto/pkg/errors"
+	oktetoLog "github.com/okteto/okteto/pkg/log"
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/okteto/okteto/pkg/model/forward"
+)
+
+// multiShutdownTimeout is the time to wait for all the development containers to shut down
+const multiShutdownTimeout = 5 * time.Second
+
+// multiUpContext activates several development containers in the same up session.
+// Every development container has its own upContext, the output of their commands is prefixed by their names
+type multiUpContext struct {
+	ups       []*upContext
+	stdout    *multiOutput
+	stderr    *multiOutput
+	inFd      uintptr
+	isTerm    bool
+	stateTerm *term.State
+}
+
+type devExit struct {
+	up  *upContext
+	err error
+}
+
+func newMultiUpContext(base *upContext, devs []*model.Dev) *multiUpContext {
+	m := &multiUpContext{
+		stdout:    &multiOutput{out: os.Stdout},
+		stderr:    &multiOutput{out: os.Stderr},
+		inFd:      base.inFd,
+		isTerm:    base.isTerm,
+		stateTerm: base.stateTerm,
+	}
+	for _, dev := range devs {
+		m.ups = append(m.ups, &upContext{
+			Manifest:       base.Manifest,
+			Dev:            dev,
+			Exit:           make(chan error, 1),
+			Client:         base.Client,
+			RestConfig:     base.RestConfig,
+			resetSyncthing: base.resetSyncthing,
+			StartTime:      base.StartTime,
+			Options:        base.Options,
+			Multi:          m,
+		})
+	}
+	return m
+}
+
+func (m *multiUpContext) start() error {
+	for _, up := range m.ups {
+		if err := createPIDFile(up.Dev.Namespace, up.Dev.Name); err != nil {
+			oktetoLog.Infof("failed to create pid file for %s - %s: %s", up.Dev.Namespace, up.Dev.Name, err)
+			return fmt.Errorf("couldn't create pid file for %s - %s", up.Dev.Namespace, up.Dev.Name)
+		}
+		defer cleanPIDFile(up.Dev.Namespace, up.Dev.Name)
+	}
+
+	// the progress of several development containers can't be shown by the same spinner
+	oktetoLog.DisableSpinner()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	exits := make(chan devExit, len(m.ups))
+	running := map[*upContext]bool{}
+	for _, up := range m.ups {
+		running[up] = true
+		up.trackUp()
+		go up.activateLoop()
+		go func(up *upContext) {
+			exits <- devExit{up: up, err: <-up.Exit}
+		}(up)
+	}
+	go m.showStatus(ctx)
+
+	for len(running) > 0 {
+		select {
+		case <-stop:
+			oktetoLog.Infof("CTRL+C received, starting shutdown sequence")
+			m.shutdown(running)
+			oktetoLog.Println()
+			return nil
+		case e := <-exits:
+			delete(running, e.up)
+			if e.err != nil {
+				oktetoLog.Infof("exit signal received from '%s' due to error: %s", e.up.Dev.Name, e.err)
+				m.stderr.println(e.up.Dev.Name, "development container failed, stopping the other development containers")
+				m.shutdown(running)
+				return e.err
+			}
+			m.stdout.println(e.up.Dev.Name, "development container stopped")
+		}
+	}
+	return nil
+}
+
+// shutdown runs the shutdown sequence of the running development containers at the same time
+func (m *multiUpContext) shutdown(running map[*upContext]bool) {
+	if m.isTerm {
+		if err := term.RestoreTerminal(m.inFd, m.stateTerm); err != nil {
+			oktetoLog.Infof("failed to restore terminal: %s", err.Error())
+		}
+	}
+
+	var wg sync.WaitGroup
+	for up := range running {
+		wg.Add(1)
+		go func(up *upContext) {
+			defer wg.Done()
+			up.shutdown()
+		}(up)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(multiShutdownTimeout):
+		oktetoLog.Infof("timeout waiting for the development containers to shut down")
+	}
+}
+
+// showStatus shows the state changes of every development container,
+// and the forwards of all of them the first time that all are ready
+func (m *multiUpContext) showStatus(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	states := map[string]config.UpState{}
+	forwardsShown := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		allReady := true
+		for _, up := range m.ups {
+			state, err := config.GetState(up.Dev)
+			if err != nil {
+				allReady = false
+				continue
+			}
+			if state != states[up.Dev.Name] {
+				states[up.Dev.Name] = state
+				m.stdout.println(up.Dev.Name, getStateMessage(state))
+			}
+			if state != config.Ready {
+				allReady = false
+			}
+		}
+
+		if allReady && !forwardsShown {
+			forwardsShown = true
+			m.stdout.write(getMultiForwardsTable(m.ups))
+		}
+	}
+}
+
+func getStateMessage(state config.UpState) string {
+	switch state {
+	case config.Activating:
+		return "activating development container"
+	case config.Starting:
+		return "starting development container"
+	case config.Attaching:
+		return "attaching persistent volume"
+	case config.Pulling:
+		return "pulling images"
+	case config.StartingSync:
+		return "starting file synchronization"
+	case config.Synchronizing:
+		return "synchronizing files"
+	case config.Ready:
+		return "ready"
+	case config.Failed:
+		return "failed"
+	default:
+		return string(state)
+	}
+}
+
+// getMultiForwardsTable returns the forwards of all the development containers
+func getMultiForwardsTable(ups []*upContext) []byte {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 1, 1, 2, ' ', 0)
+	fmt.Fprintf(w, "Dev\tForward\n")
+	if len(ups) > 0 {
+		for _, f := range ups[0].Manifest.GlobalForward {
+			fmt.Fprintf(w, "-\t%d -> %s:%d\n", f.Local, f.ServiceName, f.Remote)
+		}
+	}
+	for _, up := range ups {
+		for _, f := range up.Dev.Forward {
+			fmt.Fprintf(w, "%s\t%s\n", up.Dev.Name, getForwardDescription(f))
+		}
+		for _, r := range up.Dev.Reverse {
+			fmt.Fprintf(w, "%s\t%d <- %d\n", up.Dev.Name, r.Local, r.Remote)
+		}
+	}
+	w.Flush()
+	return buf.Bytes()
+}
+
+func getForwardDescription(f forward.Forward) string {
+	if f.Service {
+		return fmt.Sprintf("%d -> %s:%d", f.Local, f.ServiceName, f.Remote)
+	}
+	return fmt.Sprintf("%d -> %d", f.Local, f.Remote)
+}
+
+// validateMultiDevForwards checks that several development containers don't forward the same local port
+func validateMultiDevForwards(devs []*model.Dev) error {
+	ports := map[int]string{}
+	for _, dev := range devs {
+		localPorts := []int{}
+		for _, f := range dev.Forward {
+			localPorts = append(localPorts, f.Local)
+		}
+		if dev.RemotePort > 0 {
+			localPorts = append(localPorts, dev.RemotePort)
+		}
+		for _, port := range localPorts {
+			if other, ok := ports[port]; ok && other != dev.Name {
+				return oktetoErrors.UserError{
+					E:    fmt.Errorf("development containers '%s' and '%s' use the same local port %d", other, dev.Name, port),
+					Hint: "Update the 'forward' and 'remote' fields in your okteto manifest to use different local ports",
+				}
+			}
+			ports[port] = dev.Name
+		}
+	}
+	return nil
+}
+
+// runMultiCommand runs the command of a development container without a terminal, prefixing its output.
+// Interactive development containers don't run a command, the user opens a shell with 'okteto exec'
+func (up *upContext) runMultiCommand(ctx context.Context, cmd []string) error {
+	if up.getInteractive() {
+		shell := "sh"
+		if len(cmd) > 0 {
+			shell = strings.Join(cmd, " ")
+		}
+		msg := fmt.Sprintf("run 'okteto exec %s %s' to open a shell", up.Dev.Name, shell)
+		if up.isDetached() {
+			msg = fmt.Sprintf("run 'okteto up attach %s' to open a shell", up.Dev.Name)
+		}
+		up.Multi.stdout.println(up.Dev.Name, msg)
+		<-ctx.Done()
+		return nil
+	}
+
+	stdout := up.Multi.stdout.writer(up.Dev.Name)
+	defer stdout.flush()
+	stderr := up.Multi.stderr.writer(up.Dev.Name)
+	defer stderr.flush()
+	return up.runBackgroundCommand(ctx, cmd, stdout, stderr)
+}
+
+// startsGlobalForwards returns if the development container starts the global forwards of the manifest.
+// Only the first development container of a session starts them
+func (up *upContext) startsGlobalForwards() bool {
+	return up.Multi == nil || up.Multi.ups[0] == up
+}
+
+// multiOutput serializes the lines written by several development containers
+type multiOutput struct {
+	mu  sync.Mutex
+	out io.Writer
+	// crlf ends the lines with a carriage return, the terminal can be in raw mode while a remote shell is open
+	crlf bool
+}
+
+func (o *multiOutput) write(b []byte) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if _, err := o.out.Write(b); err != nil {
+		oktetoLog.Infof("failed to write output: %s", err)
+	}
+}
+
+func (o *multiOutput) println(name, msg string) {
+	o.write([]byte(fmt.Sprintf("%s %s\n", getDevPrefix(name), msg)))
+}
+
+func (o *multiOutput) writer(name string) *prefixWriter {
+	return &prefixWriter{output: o, prefix: getDevPrefix(name)}
+}
+
+func getDevPrefix(name string) string {
+	return oktetoLog.BlueString("[%s]", name)
+}
+
+// prefixWriter writes every line prefixed. Incomplete lines are kept until they are completed or flushed
+type prefixWriter struct {
+	output *multiOutput
+	prefix string
+	mu     sync.Mutex
+	buf    []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		w.writeLine(w.buf[:i+1])
+		w.buf = w.buf[i+1:]
+	}
+}
+
+func (w *prefixWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) == 0 {
+		return
+	}
+	w.writeLine(append(w.buf, '\n'))
+	w.buf = nil
+}
+
+func (w *prefixWriter) writeLine(line []byte) {
+	if w.output.crlf {
+		line = append(append([]byte{}, bytes.TrimRight(line, "\r\n")...), '\r', '\n')
+	}
+	w.output.write(append([]byte(w.prefix+" "), line...))
+}
@@ -0,0 +1,139 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package up
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/okteto/okteto/pkg/model/forward"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_prefixWriter(t *testing.T) {
+	var buf bytes.Buffer
+	output := &multiOutput{out: &buf}
+	w := output.writer("api")
+
+	n, err := w.Write([]byte("first line\nsecond "))
+	assert.NoError(t, err)
+	assert.Equal(t, 18, n)
+	assert.Equal(t, "[api] first line\n", buf.String())
+
+	_, err = w.Write([]byte("line\nthird"))
+	assert.NoError(t, err)
+	assert.Equal(t, "[api] first line\n[api] second line\n", buf.String())
+
+	w.flush()
+	assert.Equal(t, "[api] first line\n[api] second line\n[api] third\n", buf.String())
+
+	w.flush()
+	assert.Equal(t, "[api] first line\n[api] second line\n[api] third\n", buf.String())
+}
+
+func Test_validateMultiDevForwards(t *testing.T) {
+	var tests = []struct {
+		name      string
+		devs      []*model.Dev
+		expectErr bool
+	}{
+		{
+			name: "different-ports",
+			devs: []*model.Dev{
+				{Name: "api", Forward: []forward.Forward{{Local: 8080, Remote: 8080}}},
+				{Name: "worker", Forward: []forward.Forward{{Local: 8081, Remote: 8080}}},
+			},
+		},
+		{
+			name: "same-forward",
+			devs: []*model.Dev{
+				{Name: "api", Forward: []forward.Forward{{Local: 8080, Remote: 8080}}},
+				{Name: "worker", Forward: []forward.Forward{{Local: 8080, Remote: 8080}}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "forward-and-remote",
+			devs: []*model.Dev{
+				{Name: "api", Forward: []forward.Forward{{Local: 2222, Remote: 8080}}},
+				{Name: "worker", RemotePort: 2222},
+			},
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateMultiDevForwards(tt.devs)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_getMultiForwardsTable(t *testing.T) {
+	manifest := &model.Manifest{
+		GlobalForward: []forward.GlobalForward{{Local: 5432, Remote: 5432, ServiceName: "postgres"}},
+	}
+	ups := []*upContext{
+		{
+			Manifest: manifest,
+			Dev: &model.Dev{
+				Name:    "api",
+				Forward: []forward.Forward{{Local: 8080, Remote: 8080}},
+				Reverse: []model.Reverse{{Local: 9000, Remote: 9001}},
+			},
+		},
+		{
+			Manifest: manifest,
+			Dev: &model.Dev{
+				Name:    "worker",
+				Forward: []forward.Forward{{Local: 6379, Remote: 6379, Service: true, ServiceName: "redis"}},
+			},
+		},
+	}
+	expected := `Dev     Forward
+-       5432 -> postgres:5432
+api     8080 -> 8080
+api     9000 <- 9001
+worker  6379 -> redis:6379
+`
+	assert.Equal(t, expected, string(getMultiForwardsTable(ups)))
+}
+
+func TestUpOptionsAddArgs(t *testing.T) {
+	opts := &UpOptions{}
+	assert.NoError(t, opts.AddArgs(&cobra.Command{}, []string{"api"}))
+	assert.Equal(t, "api", opts.DevName)
+	assert.Empty(t, opts.Devs)
+
+	opts = &UpOptions{}
+	assert.NoError(t, opts.AddArgs(&cobra.Command{}, []string{"api", "worker"}))
+	assert.Equal(t, []string{"api", "worker"}, opts.Devs)
+
+	opts = &UpOptions{}
+	err := opts.AddArgs(&cobra.Command{}, []string{"api", "api"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "selected more than once")
+
+	opts = &UpOptions{Remote: 2222}
+	err = opts.AddArgs(&cobra.Command{}, []string{"api", "worker"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "'--remote'")
+}
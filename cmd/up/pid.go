@@ -18,9 +18,11 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/okteto/okteto/pkg/config"
 	oktetoLog "github.com/okteto/okteto/pkg/log"
+	"github.com/shirou/gopsutil/process"
 )
 
 // createPIDFile creates a PID file to track Up state and existence
@@ -48,3 +50,88 @@ func cleanPIDFile(ns, dpName string) {
 		oktetoLog.Infof("unable to delete PID file at %s", filePath)
 	}
 }
+
+// getPID returns the PID stored in the PID file of a development container
+func getPID(ns, dpName string) (int, error) {
+	filePath := filepath.Join(config.GetAppHome(ns, dpName), "okteto.pid")
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		return 0, fmt.Errorf("PID file at %s is corrupted", filePath)
+	}
+	return pid, nil
+}
+
+// getRunningPID returns the PID of the up session of a development container, or 0 if it isn't running
+func getRunningPID(ns, dpName string) int {
+	pid, err := getPID(ns, dpName)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			oktetoLog.Infof("failed to read PID file of %s - %s: %s", ns, dpName, err)
+		}
+		return 0
+	}
+	exists, err := process.PidExists(int32(pid))
+	if err != nil {
+		oktetoLog.Infof("failed to check if process %d exists: %s", pid, err)
+		return 0
+	}
+	if !exists {
+		return 0
+	}
+	p, err := process.NewProcess(int32(pid))
+	if err != nil {
+		oktetoLog.Infof("failed to get process %d: %s", pid, err)
+		return 0
+	}
+	if !isUpProcess(p) {
+		oktetoLog.Infof("process %d isn't an okteto up session, the PID file of %s - %s is stale", pid, ns, dpName)
+		return 0
+	}
+	return pid
+}
+
+// isUpProcess checks that a process is an okteto up session: the PID of a finished session can be reused by any other process
+func isUpProcess(p *process.Process) bool {
+	args, err := p.CmdlineSlice()
+	if err != nil {
+		oktetoLog.Infof("failed to get the command line of process %d: %s", p.Pid, err)
+		return false
+	}
+	exe, err := p.Exe()
+	if err != nil {
+		oktetoLog.Debugf("failed to get the executable of process %d: %s", p.Pid, err)
+		exe = ""
+	}
+	self, err := os.Executable()
+	if err != nil {
+		oktetoLog.Infof("failed to get the okteto executable: %s", err)
+		return false
+	}
+	return isUpCommand(exe, self, args)
+}
+
+// isUpCommand checks that the executable of a process is the okteto binary running 'up'.
+// The executable is taken from the command line when it's not available
+func isUpCommand(exe, self string, args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	if exe == "" {
+		exe = args[0]
+	}
+	// the executable of a running process is reported as deleted when the binary is upgraded
+	exe = strings.TrimSuffix(exe, " (deleted)")
+	if !strings.EqualFold(filepath.Base(exe), filepath.Base(self)) {
+		return false
+	}
+	for _, arg := range args[1:] {
+		if arg == "up" {
+			return true
+		}
+	}
+	return false
+}
@@ -15,11 +15,16 @@ package up
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/okteto/okteto/pkg/config"
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCreatePIDFile(t *testing.T) {
@@ -48,3 +53,110 @@ func TestCreatePIDFile(t *testing.T) {
 	}
 
 }
+
+func TestGetRunningPID(t *testing.T) {
+	t.Setenv(model.OktetoFolderEnvVar, t.TempDir())
+
+	assert.Equal(t, 0, getRunningPID("namespace", "api"))
+
+	// the test binary isn't running 'up', as happens when the PID of a finished session is reused
+	assert.NoError(t, createPIDFile("namespace", "api"))
+	assert.Equal(t, 0, getRunningPID("namespace", "api"))
+
+	pid := startUpSession(t)
+	writePIDFile(t, "namespace", "api", pid)
+	assert.Equal(t, pid, getRunningPID("namespace", "api"))
+
+	filePath := filepath.Join(config.GetAppHome("namespace", "api"), "okteto.pid")
+	assert.NoError(t, os.WriteFile(filePath, []byte("not-a-pid"), 0600))
+	_, err := getPID("namespace", "api")
+	assert.Error(t, err)
+	assert.Equal(t, 0, getRunningPID("namespace", "api"))
+}
+
+// startUpSession starts a process that looks like an up session: the test binary running 'up'
+func startUpSession(t *testing.T) int {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestUpSessionHelper$", "up")
+	cmd.Env = append(os.Environ(), "OKTETO_TEST_UP_SESSION=true")
+	require.NoError(t, cmd.Start())
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+	return cmd.Process.Pid
+}
+
+func writePIDFile(t *testing.T, ns, name string, pid int) {
+	t.Helper()
+	filePath := filepath.Join(config.GetAppHome(ns, name), "okteto.pid")
+	require.NoError(t, os.WriteFile(filePath, []byte(strconv.Itoa(pid)), 0600))
+}
+
+// TestUpSessionHelper is the process started by startUpSession
+func TestUpSessionHelper(t *testing.T) {
+	if os.Getenv("OKTETO_TEST_UP_SESSION") == "" {
+		return
+	}
+	time.Sleep(time.Minute)
+}
+
+func TestIsUpCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		exe      string
+		self     string
+		args     []string
+		expected bool
+	}{
+		{
+			name:     "up session",
+			exe:      "/usr/local/bin/okteto",
+			self:     "/usr/local/bin/okteto",
+			args:     []string{"okteto", "up", "api"},
+			expected: true,
+		},
+		{
+			name:     "up session with global flags",
+			exe:      "/usr/local/bin/okteto",
+			self:     "/usr/local/bin/okteto",
+			args:     []string{"okteto", "--log-level", "debug", "up"},
+			expected: true,
+		},
+		{
+			name:     "upgraded binary",
+			exe:      "/usr/local/bin/okteto (deleted)",
+			self:     "/usr/local/bin/okteto",
+			args:     []string{"okteto", "up"},
+			expected: true,
+		},
+		{
+			name:     "executable from the command line",
+			self:     "/usr/local/bin/okteto",
+			args:     []string{"/usr/local/bin/okteto", "up"},
+			expected: true,
+		},
+		{
+			name: "other okteto command",
+			exe:  "/usr/local/bin/okteto",
+			self: "/usr/local/bin/okteto",
+			args: []string{"okteto", "deploy"},
+		},
+		{
+			name: "reused pid",
+			exe:  "/usr/bin/vim",
+			self: "/usr/local/bin/okteto",
+			args: []string{"vim", "up"},
+		},
+		{
+			name: "no command line",
+			exe:  "/usr/local/bin/okteto",
+			self: "/usr/local/bin/okteto",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isUpCommand(tt.exe, tt.self, tt.args))
+		})
+	}
+}
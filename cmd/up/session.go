@@ -0,0 +1,87 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package up
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/alessio/shellescape"
+	oktetoLog "github.com/okteto/okteto/pkg/log"
+	"github.com/okteto/okteto/pkg/model"
+)
+
+const (
+	// persistentSessionScript runs a command in a tmux session of the development container.
+	// The session keeps the shell, its processes and its scrollback when the connection is lost,
+	// and reconnections attach to it instead of starting a new command. Without tmux the command runs as usual
+	persistentSessionScript = `if command -v tmux >/dev/null 2>&1; then
+  export TERM="${TERM:-xterm-256color}"
+  exec tmux new-session -A -s %[1]s %[2]s
+fi
+exec %[2]s`
+
+	// checkTmuxScript prints the path of tmux in the development container, or nothing if it isn't installed
+	checkTmuxScript = `command -v tmux || true`
+
+	persistentSessionWarning = "'persistentSession' requires tmux, but it isn't installed in your development container: your session won't survive disconnections.\n    Install tmux in the image of your development container to keep your session"
+)
+
+// getSessionName returns the name of the persistent session of a development container
+func getSessionName(dev *model.Dev) string {
+	return fmt.Sprintf("okteto-%s", dev.Name)
+}
+
+// getSessionCommand returns the command to run in a terminal of the development container,
+// wrapped in its persistent session if the development container enables it
+func getSessionCommand(dev *model.Dev, cmd []string) []string {
+	if !dev.PersistentSession {
+		return cmd
+	}
+	script := fmt.Sprintf(persistentSessionScript, shellescape.Quote(getSessionName(dev)), shellescape.QuoteCommand(cmd))
+	return []string{"sh", "-c", script}
+}
+
+// getCleanCommand returns the command that cleans the processes of previous sessions.
+// The processes of a persistent session are kept until the session exits
+func getCleanCommand(dev *model.Dev) string {
+	clean := "/var/okteto/bin/clean >/dev/null 2>&1"
+	if dev.PersistentSession {
+		clean = fmt.Sprintf("tmux has-session -t %s >/dev/null 2>&1 || %s", shellescape.Quote(getSessionName(dev)), clean)
+	}
+	return fmt.Sprintf("cat /var/okteto/bin/version.txt; cat /proc/sys/fs/inotify/max_user_watches; %s", clean)
+}
+
+// checkPersistentSession warns if the development container enables the persistent session and tmux isn't installed in its image.
+// The terminal falls back to a plain shell in that case
+func (up *upContext) checkPersistentSession(ctx context.Context) {
+	if !up.Dev.PersistentSession || up.isRetry {
+		return
+	}
+	var out bytes.Buffer
+	if err := up.runBackgroundCommand(ctx, []string{"sh", "-c", checkTmuxScript}, &out, &out); err != nil {
+		oktetoLog.Infof("failed to check the persistent session of your development container: %s", err)
+		return
+	}
+	if !isTmuxInstalled(out.String()) {
+		oktetoLog.Warning(persistentSessionWarning)
+	}
+}
+
+// isTmuxInstalled returns if tmux is installed in the development container from the output of checkTmuxScript
+func isTmuxInstalled(output string) bool {
+	return strings.TrimSpace(output) != ""
+}
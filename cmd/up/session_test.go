@@ -0,0 +1,50 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package up
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_getSessionCommand(t *testing.T) {
+	dev := &model.Dev{Name: "api"}
+	cmd := []string{"yarn", "start"}
+	assert.Equal(t, cmd, getSessionCommand(dev, cmd))
+
+	dev.PersistentSession = true
+	result := getSessionCommand(dev, cmd)
+	assert.Len(t, result, 3)
+	assert.Equal(t, []string{"sh", "-c"}, result[:2])
+	assert.Contains(t, result[2], "exec tmux new-session -A -s okteto-api yarn start")
+	// without tmux the command runs as usual
+	assert.True(t, strings.HasSuffix(result[2], "\nexec yarn start"))
+}
+
+func Test_isTmuxInstalled(t *testing.T) {
+	assert.True(t, isTmuxInstalled("/usr/bin/tmux\n"))
+	assert.False(t, isTmuxInstalled(""))
+	assert.False(t, isTmuxInstalled("\n"))
+}
+
+func Test_getCleanCommand(t *testing.T) {
+	dev := &model.Dev{Name: "api"}
+	assert.Equal(t, "cat /var/okteto/bin/version.txt; cat /proc/sys/fs/inotify/max_user_watches; /var/okteto/bin/clean >/dev/null 2>&1", getCleanCommand(dev))
+
+	dev.PersistentSession = true
+	assert.Equal(t, "cat /var/okteto/bin/version.txt; cat /proc/sys/fs/inotify/max_user_watches; tmux has-session -t okteto-api >/dev/null 2>&1 || /var/okteto/bin/clean >/dev/null 2>&1", getCleanCommand(dev))
+}
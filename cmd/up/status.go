@@ -0,0 +1,76 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package up
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/okteto/okteto/pkg/config"
+	oktetoLog "github.com/okteto/okteto/pkg/log"
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/spf13/cobra"
+)
+
+// Status shows the up sessions of the development containers of the manifest
+func Status() *cobra.Command {
+	options := &sessionOptions{}
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the development containers running in the background",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			manifest, err := options.loadManifest(ctx)
+			if err != nil {
+				return err
+			}
+
+			if len(getRunningDevs(manifest)) == 0 {
+				oktetoLog.Println("There isn't any development container running")
+				return nil
+			}
+			printSessions(os.Stdout, manifest)
+			return nil
+		},
+	}
+	options.addFlags(cmd)
+	return cmd
+}
+
+// printSessions prints the state of the up sessions of the development containers of the manifest
+func printSessions(out io.Writer, manifest *model.Manifest) {
+	w := tabwriter.NewWriter(out, 1, 1, 2, ' ', 0)
+	fmt.Fprintf(w, "Dev\tPID\tStatus\n")
+	names := manifest.Dev.GetDevs()
+	sort.Strings(names)
+	for _, name := range names {
+		dev := manifest.Dev[name]
+		pid := getRunningPID(dev.Namespace, dev.Name)
+		if pid == 0 {
+			fmt.Fprintf(w, "%s\t-\tstopped\n", name)
+			continue
+		}
+		status := "unknown"
+		if state, err := config.GetState(dev); err == nil {
+			status = getStateMessage(state)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\n", name, pid, status)
+	}
+	w.Flush()
+}
@@ -0,0 +1,152 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package up
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/okteto/okteto/cmd/utils"
+	"github.com/okteto/okteto/pkg/config"
+	oktetoLog "github.com/okteto/okteto/pkg/log"
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/shirou/gopsutil/process"
+	"github.com/spf13/cobra"
+)
+
+// stopTimeout is the time to wait for an up session to shut down before killing it
+const stopTimeout = 30 * time.Second
+
+// Stop stops the up sessions running in the background
+func Stop() *cobra.Command {
+	options := &sessionOptions{}
+	cmd := &cobra.Command{
+		Use:   "stop [svc...]",
+		Short: "Stop development containers running in the background",
+		Long: `Stop development containers running in the background.
+
+The up sessions are interrupted and killed if they don't shut down after 30 seconds.
+On Windows they are killed right away, as the sessions running in the background don't have a console to be interrupted`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			manifest, err := options.loadManifest(ctx)
+			if err != nil {
+				return err
+			}
+
+			devs := []*model.Dev{}
+			for _, name := range args {
+				dev, err := utils.GetDevFromManifest(manifest, name)
+				if err != nil {
+					return err
+				}
+				devs = append(devs, dev)
+			}
+			if len(args) == 0 {
+				for _, name := range getRunningDevs(manifest) {
+					devs = append(devs, manifest.Dev[name])
+				}
+				if len(devs) == 0 {
+					oktetoLog.Success("There isn't any development container running")
+					return nil
+				}
+			}
+
+			return stopSessions(manifest, devs)
+		},
+	}
+	options.addFlags(cmd)
+	return cmd
+}
+
+// stopSessions stops the up sessions of the development containers.
+// Development containers activated by the same up session are stopped together
+func stopSessions(manifest *model.Manifest, devs []*model.Dev) error {
+	stopped := map[int]bool{}
+	for _, dev := range devs {
+		pid := getRunningPID(dev.Namespace, dev.Name)
+		if pid == 0 {
+			oktetoLog.Success("Development container '%s' isn't running", dev.Name)
+			continue
+		}
+		if stopped[pid] {
+			continue
+		}
+
+		oktetoLog.Spinner(fmt.Sprintf("Stopping development container '%s'...", dev.Name))
+		oktetoLog.StartSpinner()
+		err := stopProcess(pid)
+		oktetoLog.StopSpinner()
+		if err != nil {
+			return err
+		}
+		stopped[pid] = true
+
+		for _, name := range manifest.Dev.GetDevs() {
+			d := manifest.Dev[name]
+			if p, err := getPID(d.Namespace, d.Name); err != nil || p != pid {
+				continue
+			}
+			// the files aren't removed if the process was killed
+			cleanPIDFile(d.Namespace, d.Name)
+			if err := config.DeleteStateFile(d); err != nil && !os.IsNotExist(err) {
+				oktetoLog.Infof("failed to delete state file of %s: %s", d.Name, err)
+			}
+			oktetoLog.Success("Development container '%s' stopped", d.Name)
+		}
+	}
+	return nil
+}
+
+// stopProcess terminates an up session, killing it if it doesn't exit before stopTimeout.
+// On Windows the detached sessions don't have a console to receive a control event, so they are always killed
+func stopProcess(pid int) error {
+	p, err := process.NewProcess(int32(pid))
+	if err != nil {
+		oktetoLog.Infof("process %d isn't running: %s", pid, err)
+		return nil
+	}
+	if !isUpProcess(p) {
+		oktetoLog.Infof("process %d isn't an okteto up session", pid)
+		return nil
+	}
+	if err := p.Terminate(); err != nil {
+		return fmt.Errorf("failed to stop process %d: %w", pid, err)
+	}
+
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	to := time.NewTimer(stopTimeout)
+	defer to.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			running, err := p.IsRunning()
+			if err != nil || !running {
+				return nil
+			}
+		case <-to.C:
+			if !isUpProcess(p) {
+				return nil
+			}
+			oktetoLog.Infof("process %d didn't stop after %s, killing it", pid, stopTimeout)
+			if err := p.Kill(); err != nil {
+				return fmt.Errorf("failed to kill process %d: %w", pid, err)
+			}
+			return nil
+		}
+	}
+}
@@ -53,6 +53,8 @@ type upContext struct {
 	stateTerm             *term.State
 	StartTime             time.Time
 	Options               *UpOptions
+	// Multi is set when the development container is activated together with others in the same session
+	Multi *multiUpContext
 }
 
 // Forwarder is an interface for the port-forwarding features
@@ -21,6 +21,7 @@ import (
 	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/moby/term"
@@ -74,15 +75,17 @@ type UpOptions struct {
 	Deploy       bool
 	ForcePull    bool
 	Reset        bool
+	Detach       bool
+	// detached is set in the background process started by the option --detach
+	detached bool
 }
 
 // Up starts a development container
 func Up() *cobra.Command {
 	upOptions := &UpOptions{}
 	cmd := &cobra.Command{
-		Use:   "up [svc]",
+		Use:   "up [svc...]",
 		Short: "Launch your development environment",
-		Args:  utils.MaximumNArgsAccepted(1, "https://okteto.com/docs/reference/cli/#up"),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if okteto.InDevContainer() {
 				return oktetoErrors.ErrNotInDevContainer
@@ -92,6 +95,11 @@ func Up() *cobra.Command {
 				return err
 			}
 
+			if os.Getenv(model.OktetoUpDetachedEnvVar) == "true" {
+				upOptions.detached = true
+				os.Unsetenv(model.OktetoUpDetachedEnvVar)
+			}
+
 			u := utils.UpgradeAvailable()
 			if len(u) > 0 {
 				warningFolder := filepath.Join(config.GetOktetoHome(), ".warnings")
@@ -262,31 +270,25 @@ func Up() *cobra.Command {
 				oktetoLog.Information("'%s' was already deployed. To redeploy run 'okteto deploy' or 'okteto up --deploy'", up.Manifest.Name)
 			}
 
-			dev, err := utils.GetDevFromManifest(oktetoManifest, upOptions.DevName)
+			devs, err := getDevsToActivate(oktetoManifest, upOptions)
 			if err != nil {
-				if !errors.Is(err, utils.ErrNoDevSelected) {
-					return err
-				}
-				selector := utils.NewOktetoSelector("Select which development container to activate:", "Development container")
-				dev, err = utils.SelectDevFromManifest(oktetoManifest, selector, oktetoManifest.Dev.GetDevs())
-				if err != nil {
-					return err
-				}
-			}
-
-			up.Dev = dev
-			if forceAutocreate {
-				// update autocreate property if needed to be forced
-				oktetoLog.Info("Setting Autocreate to true because manifest v1 and flag --deploy")
-				up.Dev.Autocreate = true
+				return err
 			}
+			dev := devs[0]
 
 			if err := setBuildEnvVars(ctx, oktetoManifest); err != nil {
 				return err
 			}
 
-			if err := loadManifestOverrides(dev, upOptions); err != nil {
-				return err
+			for _, d := range devs {
+				if forceAutocreate {
+					// update autocreate property if needed to be forced
+					oktetoLog.Info("Setting Autocreate to true because manifest v1 and flag --deploy")
+					d.Autocreate = true
+				}
+				if err := loadManifestOverrides(d, upOptions); err != nil {
+					return err
+				}
 			}
 
 			if syncthing.ShouldUpgrade() {
@@ -307,16 +309,18 @@ func Up() *cobra.Command {
 
 			oktetoLog.ConfigureFileLogger(config.GetAppHome(dev.Namespace, dev.Name), config.VersionString)
 
-			if err := checkStignoreConfiguration(dev); err != nil {
-				oktetoLog.Infof("failed to check '.stignore' configuration: %s", err.Error())
-			}
+			for _, d := range devs {
+				if err := checkStignoreConfiguration(d); err != nil {
+					oktetoLog.Infof("failed to check '.stignore' configuration: %s", err.Error())
+				}
 
-			if err := addStignoreSecrets(dev); err != nil {
-				return err
-			}
+				if err := addStignoreSecrets(d); err != nil {
+					return err
+				}
 
-			if err := addSyncFieldHash(dev); err != nil {
-				return err
+				if err := addSyncFieldHash(d); err != nil {
+					return err
+				}
 			}
 
 			if _, ok := os.LookupEnv(model.OktetoAutoDeployEnvVar); ok {
@@ -329,7 +333,21 @@ func Up() *cobra.Command {
     https://www.okteto.com/docs/reference/manifest-migration/`))
 			}
 
-			err = up.start()
+			if upOptions.Detach {
+				return up.startDetached(devs)
+			}
+
+			if upOptions.detached {
+				// the output of the background process is written to a file
+				oktetoLog.DisableSpinner()
+			}
+
+			if len(devs) > 1 {
+				err = newMultiUpContext(up, devs).start()
+			} else {
+				up.Dev = dev
+				err = up.start()
+			}
 
 			if err != nil {
 				switch err.(type) {
@@ -356,27 +374,76 @@ func Up() *cobra.Command {
 	cmd.Flags().BoolVarP(&upOptions.ForcePull, "pull", "", false, "force dev image pull")
 	cmd.Flags().MarkHidden("pull")
 	cmd.Flags().BoolVarP(&upOptions.Reset, "reset", "", false, "reset the file synchronization database")
+	cmd.Flags().BoolVarP(&upOptions.Detach, "detach", "", false, "run the development container in the background")
+
+	cmd.AddCommand(Attach())
+	cmd.AddCommand(Status())
+	cmd.AddCommand(Stop())
 	return cmd
 }
 
 // AddArgs sets the args as options and return err if it's not compatible
 func (o *UpOptions) AddArgs(cmd *cobra.Command, args []string) error {
-
-	maxV1Args := 1
 	docsURL := "https://okteto.com/docs/reference/cli/#up"
-	if len(args) > maxV1Args {
-		cmd.Help()
-		return oktetoErrors.UserError{
-			E:    fmt.Errorf("%q accepts at most %d arg(s), but received %d", cmd.CommandPath(), maxV1Args, len(args)),
-			Hint: fmt.Sprintf("Visit %s for more information.", docsURL),
-		}
-	} else if len(args) == 1 {
+	switch len(args) {
+	case 0:
+		return nil
+	case 1:
 		o.DevName = args[0]
+		return nil
 	}
 
+	seen := map[string]bool{}
+	for _, arg := range args {
+		if seen[arg] {
+			return oktetoErrors.UserError{
+				E:    fmt.Errorf("development container '%s' is selected more than once", arg),
+				Hint: fmt.Sprintf("Visit %s for more information.", docsURL),
+			}
+		}
+		seen[arg] = true
+	}
+	if o.Remote > 0 {
+		return oktetoErrors.UserError{
+			E:    fmt.Errorf("the flag '--remote' can't be used with several development containers"),
+			Hint: "Set the field 'remote' of every development container in your okteto manifest instead",
+		}
+	}
+	o.Devs = args
 	return nil
 }
 
+// getDevsToActivate returns the development containers selected by the args, or asks for one if no arg was given
+func getDevsToActivate(manifest *model.Manifest, upOptions *UpOptions) ([]*model.Dev, error) {
+	if len(upOptions.Devs) > 0 {
+		devs := []*model.Dev{}
+		for _, name := range upOptions.Devs {
+			dev, err := utils.GetDevFromManifest(manifest, name)
+			if err != nil {
+				return nil, err
+			}
+			devs = append(devs, dev)
+		}
+		if err := validateMultiDevForwards(devs); err != nil {
+			return nil, err
+		}
+		return devs, nil
+	}
+
+	dev, err := utils.GetDevFromManifest(manifest, upOptions.DevName)
+	if err != nil {
+		if !errors.Is(err, utils.ErrNoDevSelected) {
+			return nil, err
+		}
+		selector := utils.NewOktetoSelector("Select which development container to activate:", "Development container")
+		dev, err = utils.SelectDevFromManifest(manifest, selector, manifest.Dev.GetDevs())
+		if err != nil {
+			return nil, err
+		}
+	}
+	return []*model.Dev{dev}, nil
+}
+
 func LoadManifestWithInit(ctx context.Context, k8sContext, namespace, devPath string) (*model.Manifest, error) {
 	dir, err := os.Getwd()
 	if err != nil {
@@ -528,18 +595,9 @@ func (up *upContext) start() error {
 	defer cleanPIDFile(up.Dev.Namespace, up.Dev.Name)
 
 	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, os.Interrupt)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 
-	analytics.TrackUp(analytics.TrackUpMetadata{
-		IsInteractive:          up.getInteractive(),
-		IsOktetoRepository:     utils.IsOktetoRepo(),
-		IsV2:                   up.Manifest.IsV2,
-		HasDependenciesSection: up.Manifest.IsV2 && len(up.Manifest.Dependencies) > 0,
-		HasBuildSection:        up.Manifest.IsV2 && len(up.Manifest.Build) > 0,
-		HasDeploySection: (up.Manifest.IsV2 &&
-			up.Manifest.Deploy != nil &&
-			(len(up.Manifest.Deploy.Commands) > 0 || up.Manifest.Deploy.ComposeSection.ComposesInfo != nil)),
-	})
+	up.trackUp()
 
 	go up.activateLoop()
 
@@ -557,6 +615,19 @@ func (up *upContext) start() error {
 	return nil
 }
 
+func (up *upContext) trackUp() {
+	analytics.TrackUp(analytics.TrackUpMetadata{
+		IsInteractive:          up.getInteractive(),
+		IsOktetoRepository:     utils.IsOktetoRepo(),
+		IsV2:                   up.Manifest.IsV2,
+		HasDependenciesSection: up.Manifest.IsV2 && len(up.Manifest.Dependencies) > 0,
+		HasBuildSection:        up.Manifest.IsV2 && len(up.Manifest.Build) > 0,
+		HasDeploySection: (up.Manifest.IsV2 &&
+			up.Manifest.Deploy != nil &&
+			(len(up.Manifest.Deploy.Commands) > 0 || up.Manifest.Deploy.ComposeSection.ComposesInfo != nil)),
+	})
+}
+
 // activateLoop activates the development container in a retry loop
 func (up *upContext) activateLoop() {
 	isTransientError := false
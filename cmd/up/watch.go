@@ -0,0 +1,268 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package up
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alessio/shellescape"
+	"github.com/docker/docker/pkg/fileutils"
+	oktetoLog "github.com/okteto/okteto/pkg/log"
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/okteto/okteto/pkg/syncthing"
+)
+
+const (
+	// watchPollInterval is the interval to get the items synchronized by syncthing
+	watchPollInterval = 500 * time.Millisecond
+
+	// watchStopTimeout is the time to wait for the watch command to be stopped
+	watchStopTimeout = 10 * time.Second
+
+	// watchKillGracePeriod is the time in seconds the watch command has to exit before being killed
+	watchKillGracePeriod = 5
+
+	// watchRunScript runs the watch command in its own process group and saves its ID so the command and its children
+	// can be stopped by watchStopScript. The command runs in background so setsid doesn't fork, and its PID is the process group ID
+	watchRunScript = `setsid %[2]s & echo $! > %[1]s; wait $!`
+
+	// watchStopScript terminates the process group of the running watch command, and kills it if it doesn't exit within the grace period
+	watchStopScript = `[ -f %[1]s ] && pgid=$(cat %[1]s) && kill -TERM -$pgid 2>/dev/null && {
+	i=0
+	while kill -0 -$pgid 2>/dev/null && [ $i -lt %[2]d ]; do sleep 1; i=$((i+1)); done
+	kill -KILL -$pgid 2>/dev/null
+}; rm -f %[1]s`
+)
+
+// watcher runs the watch command of a development container when syncthing synchronizes files matching its paths
+type watcher struct {
+	watch   *model.Watch
+	matcher *fileutils.PatternMatcher
+	folders map[string]bool
+	stdout  *prefixWriter
+	stderr  *prefixWriter
+
+	getEvents func(ctx context.Context, since int) ([]syncthing.ItemFinishedEvent, error)
+	run       func(ctx context.Context, stdout, stderr io.Writer) error
+	stop      func(ctx context.Context) error
+}
+
+// startWatcher runs the watch command of the development container until ctx is done
+func (up *upContext) startWatcher(ctx context.Context) {
+	w, err := up.newWatcher()
+	if err != nil {
+		oktetoLog.Infof("failed to start the watcher of '%s': %s", up.Dev.Name, err)
+		return
+	}
+	changes := make(chan []string)
+	go w.poll(ctx, changes)
+	w.loop(ctx, changes)
+}
+
+func (up *upContext) newWatcher() (*watcher, error) {
+	matcher, err := fileutils.NewPatternMatcher(up.Dev.Watch.Paths)
+	if err != nil {
+		return nil, err
+	}
+
+	folders := map[string]bool{}
+	for _, f := range up.Sy.Folders {
+		folders[syncthing.GetFolderName(f)] = true
+	}
+
+	stdout, stderr := up.getWatchOutput()
+	pidFile := shellescape.Quote(fmt.Sprintf("/tmp/okteto-watch-%s.pid", up.Dev.Name))
+	runCmd := []string{"sh", "-c", fmt.Sprintf(watchRunScript, pidFile, shellescape.QuoteCommand(up.Dev.Watch.Command.Values))}
+	stopCmd := []string{"sh", "-c", fmt.Sprintf(watchStopScript, pidFile, watchKillGracePeriod)}
+
+	return &watcher{
+		watch:     up.Dev.Watch,
+		matcher:   matcher,
+		folders:   folders,
+		stdout:    stdout,
+		stderr:    stderr,
+		getEvents: up.Sy.GetItemFinishedEvents,
+		run: func(ctx context.Context, stdout, stderr io.Writer) error {
+			return up.runBackgroundCommand(ctx, runCmd, stdout, stderr)
+		},
+		stop: func(ctx context.Context) error {
+			return up.runBackgroundCommand(ctx, stopCmd, io.Discard, io.Discard)
+		},
+	}, nil
+}
+
+// getWatchOutput returns the writers of the watch command, prefixed to tell its output from the output of the development container
+func (up *upContext) getWatchOutput() (*prefixWriter, *prefixWriter) {
+	if up.Multi != nil {
+		name := fmt.Sprintf("%s:watch", up.Dev.Name)
+		return up.Multi.stdout.writer(name), up.Multi.stderr.writer(name)
+	}
+	stdout := &multiOutput{out: os.Stdout, crlf: up.isTerm}
+	stderr := &multiOutput{out: os.Stderr, crlf: up.isTerm}
+	return stdout.writer("watch"), stderr.writer("watch")
+}
+
+// poll sends the files synchronized by syncthing that match the paths of the watcher.
+// The files synchronized before the watcher started are ignored
+func (w *watcher) poll(ctx context.Context, changes chan<- []string) {
+	since := 0
+	initialized := false
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			events, err := w.getEvents(ctx, since)
+			if err != nil {
+				oktetoLog.Infof("failed to get synchronized items: %s", err)
+				continue
+			}
+			files := []string{}
+			for _, e := range events {
+				if initialized && w.matches(e) {
+					files = append(files, e.Data.Item)
+				}
+				since = e.Id
+			}
+			initialized = true
+			if len(files) == 0 {
+				continue
+			}
+			select {
+			case changes <- files:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// matches returns if a synchronized item runs the watch command
+func (w *watcher) matches(e syncthing.ItemFinishedEvent) bool {
+	if e.Data.Error != nil || e.Data.Type == "dir" || !w.folders[e.Data.Folder] {
+		return false
+	}
+	match, err := w.matcher.Matches(filepath.FromSlash(e.Data.Item))
+	if err != nil {
+		oktetoLog.Infof("failed to match '%s': %s", e.Data.Item, err)
+		return false
+	}
+	return match
+}
+
+// loop runs the watch command when files change, once no more changes arrive for the debounce time.
+// If files change while the command is running, the command is restarted or queued depending on the watch policy
+func (w *watcher) loop(ctx context.Context, changes <-chan []string) {
+	var debounce <-chan time.Time
+	var timer *time.Timer
+	changed := map[string]bool{}
+
+	running := false
+	pending := false
+	cancelRun := func() {}
+	done := make(chan error, 1)
+	start := func() {
+		files := make([]string, 0, len(changed))
+		for f := range changed {
+			files = append(files, f)
+		}
+		changed = map[string]bool{}
+		w.println(fmt.Sprintf("%s changed, running '%s'", describeChangedFiles(files), strings.Join(w.watch.Command.Values, " ")))
+
+		var runCtx context.Context
+		runCtx, cancelRun = context.WithCancel(ctx)
+		running = true
+		go func() {
+			err := w.run(runCtx, w.stdout, w.stderr)
+			w.stdout.flush()
+			w.stderr.flush()
+			done <- err
+		}()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			cancelRun()
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+
+		case files := <-changes:
+			for _, f := range files {
+				changed[f] = true
+			}
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.NewTimer(w.watch.Debounce)
+			debounce = timer.C
+
+		case <-debounce:
+			debounce = nil
+			if !running {
+				start()
+				continue
+			}
+			pending = true
+			if w.watch.Policy == model.WatchRestartPolicy {
+				w.println("files changed, restarting the command")
+				cancelRun()
+				stopCtx, cancel := context.WithTimeout(ctx, watchStopTimeout)
+				if err := w.stop(stopCtx); err != nil {
+					oktetoLog.Infof("failed to stop the watch command: %s", err)
+				}
+				cancel()
+			}
+
+		case err := <-done:
+			running = false
+			cancelRun()
+			if err != nil && ctx.Err() == nil && !pending {
+				w.println(fmt.Sprintf("command failed: %s", err))
+			}
+			if pending {
+				pending = false
+				start()
+			}
+		}
+	}
+}
+
+func (w *watcher) println(msg string) {
+	if _, err := w.stdout.Write([]byte(msg + "\n")); err != nil {
+		oktetoLog.Infof("failed to write watch output: %s", err)
+	}
+}
+
+// describeChangedFiles returns a short description of the files that changed
+func describeChangedFiles(files []string) string {
+	switch len(files) {
+	case 0:
+		return "files"
+	case 1:
+		return fmt.Sprintf("'%s'", files[0])
+	default:
+		return fmt.Sprintf("%d files", len(files))
+	}
+}
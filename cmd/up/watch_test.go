@@ -0,0 +1,255 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package up
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alessio/shellescape"
+	"github.com/docker/docker/pkg/fileutils"
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/okteto/okteto/pkg/syncthing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWatcher(t *testing.T, policy model.WatchPolicy) (*watcher, *bytes.Buffer) {
+	matcher, err := fileutils.NewPatternMatcher([]string{"**/*.go", "!vendor"})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	output := &multiOutput{out: &buf}
+	return &watcher{
+		watch: &model.Watch{
+			Command:  model.Command{Values: []string{"go", "run", "."}},
+			Debounce: 10 * time.Millisecond,
+			Policy:   policy,
+		},
+		matcher: matcher,
+		folders: map[string]bool{"okteto-1": true},
+		stdout:  output.writer("watch"),
+		stderr:  output.writer("watch"),
+	}, &buf
+}
+
+func Test_watcherMatches(t *testing.T) {
+	w, _ := newTestWatcher(t, model.WatchRestartPolicy)
+	syncErr := "permission denied"
+
+	var tests = []struct {
+		name     string
+		data     syncthing.DataItemFinishedEvent
+		expected bool
+	}{
+		{name: "match", data: syncthing.DataItemFinishedEvent{Item: "cmd/main.go", Folder: "okteto-1", Type: "file"}, expected: true},
+		{name: "no-match", data: syncthing.DataItemFinishedEvent{Item: "README.md", Folder: "okteto-1", Type: "file"}},
+		{name: "excluded", data: syncthing.DataItemFinishedEvent{Item: "vendor/lib.go", Folder: "okteto-1", Type: "file"}},
+		{name: "other-folder", data: syncthing.DataItemFinishedEvent{Item: "main.go", Folder: "okteto-2", Type: "file"}},
+		{name: "dir", data: syncthing.DataItemFinishedEvent{Item: "pkg.go", Folder: "okteto-1", Type: "dir"}},
+		{name: "error", data: syncthing.DataItemFinishedEvent{Item: "main.go", Folder: "okteto-1", Type: "file", Error: &syncErr}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, w.matches(syncthing.ItemFinishedEvent{Data: tt.data}))
+		})
+	}
+}
+
+func Test_watcherPoll(t *testing.T) {
+	w, _ := newTestWatcher(t, model.WatchRestartPolicy)
+	calls := make(chan int, 10)
+	batches := [][]syncthing.ItemFinishedEvent{
+		{{Id: 1, Data: syncthing.DataItemFinishedEvent{Item: "old.go", Folder: "okteto-1", Type: "file"}}},
+		{
+			{Id: 2, Data: syncthing.DataItemFinishedEvent{Item: "main.go", Folder: "okteto-1", Type: "file"}},
+			{Id: 3, Data: syncthing.DataItemFinishedEvent{Item: "README.md", Folder: "okteto-1", Type: "file"}},
+		},
+	}
+	w.getEvents = func(ctx context.Context, since int) ([]syncthing.ItemFinishedEvent, error) {
+		calls <- since
+		if len(batches) == 0 {
+			return nil, nil
+		}
+		result := batches[0]
+		batches = batches[1:]
+		return result, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan []string)
+	go w.poll(ctx, changes)
+
+	select {
+	case files := <-changes:
+		assert.Equal(t, []string{"main.go"}, files)
+	case <-time.After(5 * time.Second):
+		t.Fatal("changes weren't sent")
+	}
+	assert.Equal(t, 0, <-calls)
+	assert.Equal(t, 1, <-calls)
+	assert.Equal(t, 3, <-calls)
+}
+
+// fakeWatchCommand runs until it's canceled or released
+type fakeWatchCommand struct {
+	mu      sync.Mutex
+	runs    int
+	stops   int
+	started chan bool
+	release chan bool
+}
+
+func (c *fakeWatchCommand) run(ctx context.Context, stdout, _ io.Writer) error {
+	c.mu.Lock()
+	c.runs++
+	c.mu.Unlock()
+	c.started <- true
+	select {
+	case <-ctx.Done():
+	case <-c.release:
+	}
+	return nil
+}
+
+func (c *fakeWatchCommand) stop(_ context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stops++
+	return nil
+}
+
+func (c *fakeWatchCommand) counts() (int, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.runs, c.stops
+}
+
+func Test_watcherLoop(t *testing.T) {
+	var tests = []struct {
+		policy        model.WatchPolicy
+		expectedStops int
+	}{
+		{policy: model.WatchRestartPolicy, expectedStops: 1},
+		{policy: model.WatchQueuePolicy, expectedStops: 0},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(string(tt.policy), func(t *testing.T) {
+			w, buf := newTestWatcher(t, tt.policy)
+			cmd := &fakeWatchCommand{started: make(chan bool, 10), release: make(chan bool)}
+			w.run = cmd.run
+			w.stop = cmd.stop
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			changes := make(chan []string)
+			go w.loop(ctx, changes)
+
+			changes <- []string{"main.go"}
+			changes <- []string{"main.go"}
+			waitForStart(t, cmd.started)
+
+			changes <- []string{"main.go", "util.go"}
+			if tt.policy == model.WatchQueuePolicy {
+				// the queued command runs once the running one finishes
+				time.Sleep(50 * time.Millisecond)
+				runs, _ := cmd.counts()
+				assert.Equal(t, 1, runs)
+				cmd.release <- true
+			}
+			waitForStart(t, cmd.started)
+
+			runs, stops := cmd.counts()
+			assert.Equal(t, 2, runs)
+			assert.Equal(t, tt.expectedStops, stops)
+			assert.Contains(t, buf.String(), "'main.go' changed, running 'go run .'")
+			assert.Contains(t, buf.String(), "2 files changed, running 'go run .'")
+		})
+	}
+}
+
+func waitForStart(t *testing.T, started chan bool) {
+	t.Helper()
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch command didn't start")
+	}
+}
+
+func Test_prefixWriterCRLF(t *testing.T) {
+	var buf bytes.Buffer
+	output := &multiOutput{out: &buf, crlf: true}
+	w := output.writer("watch")
+
+	_, err := w.Write([]byte("first\nsecond\r\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "[watch] first\r\n[watch] second\r\n", buf.String())
+}
+
+func Test_watchScripts(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the watch scripts run in the development container")
+	}
+	if _, err := exec.LookPath("setsid"); err != nil {
+		t.Skip("setsid is not available")
+	}
+	dir := t.TempDir()
+	pidFile := shellescape.Quote(filepath.Join(dir, "watch.pid"))
+	outFile := filepath.Join(dir, "out")
+	// the command starts a child that keeps writing until it is killed
+	command := []string{"sh", "-c", fmt.Sprintf("(while true; do echo x >> %s; sleep 0.1; done) & wait", shellescape.Quote(outFile))}
+
+	run := exec.Command("sh", "-c", fmt.Sprintf(watchRunScript, pidFile, shellescape.QuoteCommand(command)))
+	require.NoError(t, run.Start())
+	done := make(chan error, 1)
+	go func() {
+		done <- run.Wait()
+	}()
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(outFile)
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond)
+
+	stop := exec.Command("sh", "-c", fmt.Sprintf(watchStopScript, pidFile, watchKillGracePeriod))
+	require.NoError(t, stop.Run())
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the watch command wasn't stopped")
+	}
+	_, err := os.Stat(filepath.Join(dir, "watch.pid"))
+	assert.True(t, os.IsNotExist(err))
+
+	// the child of the command is stopped too
+	before, err := os.ReadFile(outFile)
+	require.NoError(t, err)
+	time.Sleep(500 * time.Millisecond)
+	after, err := os.ReadFile(outFile)
+	require.NoError(t, err)
+	assert.Equal(t, len(before), len(after))
+}
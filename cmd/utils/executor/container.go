@@ -0,0 +1,175 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+
+	oktetoErrors "github.com/okteto/okteto/pkg/errors"
+	oktetoLog "github.com/okteto/okteto/pkg/log"
+	"github.com/okteto/okteto/pkg/model"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// containerRuntimes are the container runtimes supported to run commands with an image, by order of preference
+var containerRuntimes = []string{"docker", "podman"}
+
+// containerHost is the name of the host inside the container, used to reach the deploy proxy
+const containerHost = "host.docker.internal"
+
+// newContainerCommand returns the command that runs a deploy command inside a local container.
+// The container reaches the deploy proxy through the host gateway, mounts the working directory
+// and a copy of the kubeconfig of the deploy pointing to the host, and receives the variables of the deploy.
+// The cleanup function removes the copy of the kubeconfig once the command finishes
+func newContainerCommand(cmdInfo model.DeployCommand, env []string) (*exec.Cmd, func(), error) {
+	runtime, err := getContainerRuntime(os.Getenv(model.OktetoContainerRuntimeEnvVar), exec.LookPath)
+	if err != nil {
+		return nil, nil, oktetoErrors.UserError{
+			E:    fmt.Errorf("command '%s' can't be executed in image '%s': %w", cmdInfo.Name, cmdInfo.Image, err),
+			Hint: fmt.Sprintf("Install docker or podman, or set '%s' with the path to your container runtime", model.OktetoContainerRuntimeEnvVar),
+		}
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the current working directory: %w", err)
+	}
+
+	cleanup := func() {}
+	containerKubeconfig := ""
+	if kubeconfig := getEnvValue(env, model.KubeConfigEnvVar); kubeconfig != "" {
+		containerKubeconfig = kubeconfig + "-container"
+		if err := writeContainerKubeconfig(kubeconfig, containerKubeconfig); err != nil {
+			return nil, nil, fmt.Errorf("failed to write the kubeconfig of command '%s': %w", cmdInfo.Name, err)
+		}
+		cleanup = func() {
+			if err := os.Remove(containerKubeconfig); err != nil && !os.IsNotExist(err) {
+				oktetoLog.Infof("failed to remove %s: %s", containerKubeconfig, err)
+			}
+		}
+	}
+
+	user := ""
+	if uid, gid := os.Getuid(), os.Getgid(); uid >= 0 && gid >= 0 {
+		user = fmt.Sprintf("%d:%d", uid, gid)
+	}
+
+	cmd := exec.Command(runtime, getContainerArgs(cmdInfo, env, os.Environ(), cwd, user, containerKubeconfig)...)
+	// the values of the variables are passed through the environment so they are not visible in the process list
+	cmd.Env = append(os.Environ(), env...)
+	return cmd, cleanup, nil
+}
+
+// writeContainerKubeconfig writes a copy of a kubeconfig whose local servers point to the host of the container.
+// The name of the local server is kept to validate its certificate
+func writeContainerKubeconfig(src, dst string) error {
+	cfg, err := clientcmd.LoadFromFile(src)
+	if err != nil {
+		return err
+	}
+	for _, cluster := range cfg.Clusters {
+		u, err := url.Parse(cluster.Server)
+		if err != nil {
+			return fmt.Errorf("invalid server '%s': %w", cluster.Server, err)
+		}
+		switch u.Hostname() {
+		case "localhost", "127.0.0.1", "::1":
+		default:
+			continue
+		}
+		if cluster.TLSServerName == "" {
+			cluster.TLSServerName = u.Hostname()
+		}
+		if port := u.Port(); port != "" {
+			u.Host = net.JoinHostPort(containerHost, port)
+		} else {
+			u.Host = containerHost
+		}
+		cluster.Server = u.String()
+	}
+	return clientcmd.WriteToFile(*cfg, dst)
+}
+
+// getContainerRuntime returns the container runtime set by the user or the first one available
+func getContainerRuntime(userRuntime string, lookPath func(string) (string, error)) (string, error) {
+	if userRuntime != "" {
+		return lookPath(userRuntime)
+	}
+	for _, runtime := range containerRuntimes {
+		if path, err := lookPath(runtime); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no container runtime found, tried %s", strings.Join(containerRuntimes, ", "))
+}
+
+// getContainerArgs returns the arguments of the container runtime to run a deploy command.
+// The command runs as the given user so the files it creates in the working directory belong to the user,
+// and the kubeconfig of the container is mounted in the path of the kubeconfig of the deploy
+func getContainerArgs(cmdInfo model.DeployCommand, env, hostEnv []string, cwd, user, containerKubeconfig string) []string {
+	args := []string{
+		"run", "--rm", "--init",
+		"--add-host", fmt.Sprintf("%s:host-gateway", containerHost),
+		"-v", fmt.Sprintf("%s:%s", cwd, cwd),
+		"-w", cwd,
+	}
+	if user != "" {
+		args = append(args, "--user", user)
+	}
+
+	variables := map[string]bool{}
+	names := []string{}
+	addVariable := func(kv string) {
+		name := strings.SplitN(kv, "=", 2)[0]
+		if name == "" || variables[name] {
+			return
+		}
+		variables[name] = true
+		names = append(names, name)
+	}
+	// okteto variables like OKTETO_NAMESPACE or OKTETO_GIT_COMMIT are set in the host environment
+	for _, kv := range hostEnv {
+		if strings.HasPrefix(kv, "OKTETO_") {
+			addVariable(kv)
+		}
+	}
+	for _, kv := range env {
+		addVariable(kv)
+	}
+	for _, name := range names {
+		args = append(args, "-e", name)
+	}
+
+	if kubeconfig := getEnvValue(env, model.KubeConfigEnvVar); kubeconfig != "" && containerKubeconfig != "" {
+		args = append(args, "-v", fmt.Sprintf("%s:%s:ro", containerKubeconfig, kubeconfig))
+	}
+
+	args = append(args, "--entrypoint", "sh", cmdInfo.Image, "-c", cmdInfo.Command)
+	return args
+}
+
+// getEnvValue returns the last value of a variable in a list of KEY=VALUE variables
+func getEnvValue(env []string, name string) string {
+	value := ""
+	for _, kv := range env {
+		if parts := strings.SplitN(kv, "=", 2); len(parts) == 2 && parts[0] == name {
+			value = parts[1]
+		}
+	}
+	return value
+}
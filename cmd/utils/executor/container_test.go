@@ -0,0 +1,128 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func Test_getContainerRuntime(t *testing.T) {
+	tests := []struct {
+		name        string
+		userRuntime string
+		available   map[string]bool
+		expected    string
+		expectErr   bool
+	}{
+		{
+			name:      "docker preferred",
+			available: map[string]bool{"docker": true, "podman": true},
+			expected:  "/usr/bin/docker",
+		},
+		{
+			name:      "podman fallback",
+			available: map[string]bool{"podman": true},
+			expected:  "/usr/bin/podman",
+		},
+		{
+			name:        "user runtime",
+			userRuntime: "podman",
+			available:   map[string]bool{"docker": true, "podman": true},
+			expected:    "/usr/bin/podman",
+		},
+		{
+			name:      "no runtime",
+			available: map[string]bool{},
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lookPath := func(file string) (string, error) {
+				if tt.available[file] {
+					return "/usr/bin/" + file, nil
+				}
+				return "", fmt.Errorf("%s not found", file)
+			}
+			result, err := getContainerRuntime(tt.userRuntime, lookPath)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func Test_getContainerArgs(t *testing.T) {
+	cmdInfo := model.DeployCommand{
+		Name:    "helm",
+		Command: "helm upgrade --install movies chart",
+		Image:   "alpine/helm:3.10.0",
+	}
+	env := []string{
+		"VALUE=secret",
+		"KUBECONFIG=/home/okteto/.okteto/kubeconfig-deploy",
+		"OKTETO_NAMESPACE=test",
+	}
+	hostEnv := []string{
+		"PATH=/usr/bin",
+		"OKTETO_GIT_COMMIT=1234",
+		"OKTETO_NAMESPACE=other",
+	}
+
+	result := getContainerArgs(cmdInfo, env, hostEnv, "/home/okteto/movies", "1000:1000", "/home/okteto/.okteto/kubeconfig-deploy-container")
+	expected := []string{
+		"run", "--rm", "--init",
+		"--add-host", "host.docker.internal:host-gateway",
+		"-v", "/home/okteto/movies:/home/okteto/movies",
+		"-w", "/home/okteto/movies",
+		"--user", "1000:1000",
+		"-e", "OKTETO_GIT_COMMIT",
+		"-e", "OKTETO_NAMESPACE",
+		"-e", "VALUE",
+		"-e", "KUBECONFIG",
+		"-v", "/home/okteto/.okteto/kubeconfig-deploy-container:/home/okteto/.okteto/kubeconfig-deploy:ro",
+		"--entrypoint", "sh", "alpine/helm:3.10.0", "-c", "helm upgrade --install movies chart",
+	}
+	assert.Equal(t, expected, result)
+}
+
+func Test_writeContainerKubeconfig(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "kubeconfig")
+	dst := filepath.Join(dir, "kubeconfig-container")
+	cfg := clientcmdapi.NewConfig()
+	cfg.Clusters["proxy"] = &clientcmdapi.Cluster{Server: "https://localhost:51234"}
+	cfg.Clusters["remote"] = &clientcmdapi.Cluster{Server: "https://cloud.okteto.com"}
+	require.NoError(t, clientcmd.WriteToFile(*cfg, src))
+
+	require.NoError(t, writeContainerKubeconfig(src, dst))
+
+	result, err := clientcmd.LoadFromFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, "https://host.docker.internal:51234", result.Clusters["proxy"].Server)
+	assert.Equal(t, "localhost", result.Clusters["proxy"].TLSServerName)
+	assert.Equal(t, "https://cloud.okteto.com", result.Clusters["remote"].Server)
+	assert.Empty(t, result.Clusters["remote"].TLSServerName)
+}
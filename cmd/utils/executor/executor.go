@@ -14,13 +14,23 @@
 package executor
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"os/exec"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
+	oktetoErrors "github.com/okteto/okteto/pkg/errors"
 	oktetoLog "github.com/okteto/okteto/pkg/log"
 	"github.com/okteto/okteto/pkg/model"
 )
 
+// defaultKillGracePeriod is the time a command has to exit after it's interrupted before it's killed
+const defaultKillGracePeriod = 10 * time.Second
+
 // ManifestExecutor is the interface to execute a command
 type ManifestExecutor interface {
 	Execute(command model.DeployCommand, env []string) error
@@ -32,6 +42,28 @@ type Executor struct {
 	outputMode     string
 	displayer      executorDisplayer
 	runWithoutBash bool
+
+	// timeout is the time to run all the commands, starting with the first one. Zero means no limit
+	timeout  time.Duration
+	deadline time.Time
+
+	// killGracePeriod is the time a command has to exit after it's interrupted before it's killed
+	killGracePeriod time.Duration
+
+	mu sync.Mutex
+	// running is the command being executed and finished is closed when it finishes
+	running  *exec.Cmd
+	finished chan struct{}
+}
+
+// commandTimeout is the timeout that applies to a command
+type commandTimeout struct {
+	// duration is the time the command can run
+	duration time.Duration
+	// limit is the timeout configured by the user
+	limit time.Duration
+	// global is true if the timeout is the time left to run all the commands
+	global bool
 }
 
 type executorDisplayer interface {
@@ -54,34 +86,173 @@ func NewExecutor(output string, runWithoutBash bool) *Executor {
 		displayer = newTTYExecutor()
 	}
 	return &Executor{
-		outputMode:     output,
-		displayer:      displayer,
-		runWithoutBash: runWithoutBash,
+		outputMode:      output,
+		displayer:       displayer,
+		runWithoutBash:  runWithoutBash,
+		killGracePeriod: defaultKillGracePeriod,
 	}
 }
 
-// Execute executes the specified command adding `env` to the execution environment
+// SetTimeout limits the time to run all the commands, starting when the first command is executed. Zero means no limit
+func (e *Executor) SetTimeout(timeout time.Duration) {
+	e.timeout = timeout
+}
+
+// Execute executes the specified command adding `env` to the execution environment.
+// Commands with an image are executed inside a local container.
+// The command runs in its own process group: SIGINT and SIGTERM are forwarded to all its processes,
+// which are killed if they don't exit after a grace period. The same happens if the command times out
 func (e *Executor) Execute(cmdInfo model.DeployCommand, env []string) error {
+	timeout := e.getTimeout(cmdInfo, time.Now())
+	if timeout.global && timeout.duration <= 0 {
+		err := newTimeoutError(cmdInfo.Name, timeout)
+		oktetoLog.SetStageExitCode(1, err)
+		return err
+	}
 
-	cmd := exec.Command("bash", "-c", cmdInfo.Command)
-	if e.runWithoutBash {
-		cmd = exec.Command(cmdInfo.Command)
+	var cmd *exec.Cmd
+	if cmdInfo.Image != "" {
+		var err error
+		var cleanup func()
+		cmd, cleanup, err = newContainerCommand(cmdInfo, env)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+	} else {
+		cmd = exec.Command("bash", "-c", cmdInfo.Command)
+		if e.runWithoutBash {
+			cmd = exec.Command(cmdInfo.Command)
+		}
+		cmd.Env = append(os.Environ(), env...)
 	}
-	cmd.Env = append(os.Environ(), env...)
+	setProcessGroup(cmd)
 	if err := e.displayer.startCommand(cmd); err != nil {
 		return err
 	}
 
+	finished := make(chan struct{})
+	e.setRunning(cmd, finished)
+	timedOut := make(chan bool, 1)
+	watchDone := make(chan struct{})
+	go func() {
+		e.watch(cmd, timeout.duration, finished, timedOut)
+		close(watchDone)
+	}()
+
 	e.displayer.display(cmdInfo.Name)
 
 	err := cmd.Wait()
+	e.setRunning(nil, nil)
+	close(finished)
+	if <-timedOut {
+		err = newTimeoutError(cmdInfo.Name, timeout)
+	}
+	// the command is not used after Execute returns
+	<-watchDone
+	oktetoLog.SetStageExitCode(getExitCode(err), err)
 
 	e.CleanUp(err)
 	return err
 }
 
-// CleanUp cleans the execution lines
+// watch interrupts the command when okteto receives SIGINT or SIGTERM or when the timeout expires
+func (e *Executor) watch(cmd *exec.Cmd, timeout time.Duration, finished chan struct{}, timedOut chan bool) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	var timer <-chan time.Time
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		timer = t.C
+	}
+
+	select {
+	case <-finished:
+		timedOut <- false
+	case sig := <-signals:
+		oktetoLog.Infof("forwarding signal '%s' to the command", sig)
+		timedOut <- false
+		e.terminate(cmd, sig, finished)
+	case <-timer:
+		oktetoLog.Infof("command timed out after %s", timeout)
+		timedOut <- true
+		e.terminate(cmd, syscall.SIGTERM, finished)
+	}
+}
+
+// terminate sends the signal to the processes of the command and kills them if the command doesn't finish after the grace period
+func (e *Executor) terminate(cmd *exec.Cmd, sig os.Signal, finished chan struct{}) {
+	if err := signalProcessGroup(cmd, sig); err != nil {
+		oktetoLog.Infof("could not send signal '%s' to the command: %s", sig, err)
+	}
+	select {
+	case <-finished:
+	case <-time.After(e.killGracePeriod):
+		oktetoLog.Infof("command didn't exit after %s, killing it", e.killGracePeriod)
+		if err := signalProcessGroup(cmd, os.Kill); err != nil {
+			oktetoLog.Infof("could not kill the command: %s", err)
+		}
+	}
+}
+
+func (e *Executor) setRunning(cmd *exec.Cmd, finished chan struct{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.running = cmd
+	e.finished = finished
+}
+
+// getTimeout returns the timeout of the command: its own timeout or the time left to run all the commands, whatever is shorter
+func (e *Executor) getTimeout(cmdInfo model.DeployCommand, now time.Time) commandTimeout {
+	if e.timeout > 0 && e.deadline.IsZero() {
+		e.deadline = now.Add(e.timeout)
+	}
+	if e.deadline.IsZero() {
+		return commandTimeout{duration: cmdInfo.Timeout, limit: cmdInfo.Timeout}
+	}
+	left := e.deadline.Sub(now)
+	if cmdInfo.Timeout > 0 && cmdInfo.Timeout < left {
+		return commandTimeout{duration: cmdInfo.Timeout, limit: cmdInfo.Timeout}
+	}
+	return commandTimeout{duration: left, limit: e.timeout, global: true}
+}
+
+func newTimeoutError(command string, timeout commandTimeout) error {
+	if timeout.global {
+		return oktetoErrors.UserError{
+			E:    fmt.Errorf("command '%s' was stopped: the commands didn't finish in %s", command, timeout.limit),
+			Hint: "Increase the timeout with the '--commands-timeout' flag",
+		}
+	}
+	return oktetoErrors.UserError{
+		E:    fmt.Errorf("command '%s' timed out after %s", command, timeout.limit),
+		Hint: "Increase the 'timeout' of the command in your okteto manifest",
+	}
+}
+
+// getExitCode returns the exit code of a finished command
+func getExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return 1
+}
+
+// CleanUp interrupts the command being executed, if any, and cleans the execution lines
 func (e *Executor) CleanUp(err error) {
+	e.mu.Lock()
+	cmd, finished := e.running, e.finished
+	e.mu.Unlock()
+	if cmd != nil {
+		e.terminate(cmd, os.Interrupt, finished)
+	}
 	if e.displayer != nil {
 		e.displayer.cleanUp(err)
 	}
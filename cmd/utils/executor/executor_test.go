@@ -0,0 +1,85 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_getTimeout(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name          string
+		globalTimeout time.Duration
+		deadline      time.Time
+		command       model.DeployCommand
+		expected      commandTimeout
+	}{
+		{
+			name:     "no timeout",
+			command:  model.DeployCommand{Name: "echo"},
+			expected: commandTimeout{},
+		},
+		{
+			name:     "command timeout",
+			command:  model.DeployCommand{Name: "echo", Timeout: time.Minute},
+			expected: commandTimeout{duration: time.Minute, limit: time.Minute},
+		},
+		{
+			name:          "global timeout starts with the first command",
+			globalTimeout: 10 * time.Minute,
+			command:       model.DeployCommand{Name: "echo"},
+			expected:      commandTimeout{duration: 10 * time.Minute, limit: 10 * time.Minute, global: true},
+		},
+		{
+			name:          "time left is shorter than the command timeout",
+			globalTimeout: 10 * time.Minute,
+			deadline:      now.Add(time.Minute),
+			command:       model.DeployCommand{Name: "echo", Timeout: 5 * time.Minute},
+			expected:      commandTimeout{duration: time.Minute, limit: 10 * time.Minute, global: true},
+		},
+		{
+			name:          "command timeout is shorter than the time left",
+			globalTimeout: 10 * time.Minute,
+			deadline:      now.Add(8 * time.Minute),
+			command:       model.DeployCommand{Name: "echo", Timeout: 5 * time.Minute},
+			expected:      commandTimeout{duration: 5 * time.Minute, limit: 5 * time.Minute},
+		},
+		{
+			name:          "deadline exceeded",
+			globalTimeout: 10 * time.Minute,
+			deadline:      now.Add(-time.Second),
+			command:       model.DeployCommand{Name: "echo"},
+			expected:      commandTimeout{duration: -time.Second, limit: 10 * time.Minute, global: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Executor{timeout: tt.globalTimeout, deadline: tt.deadline}
+			assert.Equal(t, tt.expected, e.getTimeout(tt.command, now))
+		})
+	}
+}
+
+func Test_newTimeoutError(t *testing.T) {
+	err := newTimeoutError("helm upgrade", commandTimeout{duration: time.Minute, limit: time.Minute})
+	assert.EqualError(t, err, "command 'helm upgrade' timed out after 1m0s")
+
+	err = newTimeoutError("helm upgrade", commandTimeout{limit: 10 * time.Minute, global: true})
+	assert.EqualError(t, err, "command 'helm upgrade' was stopped: the commands didn't finish in 10m0s")
+}
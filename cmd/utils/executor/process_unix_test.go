@@ -0,0 +1,60 @@
+//go:build !windows
+// +build !windows
+
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"testing"
+	"time"
+
+	oktetoLog "github.com/okteto/okteto/pkg/log"
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteTimeoutKillsProcessGroup(t *testing.T) {
+	e := NewExecutor(oktetoLog.PlainFormat, false)
+	start := time.Now()
+	// the background process keeps the output open: the command only finishes if the whole process group is stopped
+	err := e.Execute(model.DeployCommand{Name: "sleep", Command: "sleep 30 & sleep 30", Timeout: 200 * time.Millisecond}, nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out after 200ms")
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
+
+func TestExecuteKillsAfterGracePeriod(t *testing.T) {
+	e := NewExecutor(oktetoLog.PlainFormat, false)
+	e.killGracePeriod = 100 * time.Millisecond
+	start := time.Now()
+	err := e.Execute(model.DeployCommand{Name: "trap", Command: "trap '' TERM; sleep 30", Timeout: 200 * time.Millisecond}, nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out after 200ms")
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
+
+func TestExecuteGlobalTimeout(t *testing.T) {
+	e := NewExecutor(oktetoLog.PlainFormat, false)
+	e.SetTimeout(300 * time.Millisecond)
+	assert.NoError(t, e.Execute(model.DeployCommand{Name: "echo", Command: "echo hello"}, nil))
+
+	err := e.Execute(model.DeployCommand{Name: "sleep", Command: "sleep 30"}, nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "the commands didn't finish in 300ms")
+
+	err = e.Execute(model.DeployCommand{Name: "echo", Command: "echo hello"}, nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "the commands didn't finish in 300ms")
+}
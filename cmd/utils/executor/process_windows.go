@@ -0,0 +1,30 @@
+//go:build windows
+// +build windows
+
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"os"
+	"os/exec"
+)
+
+// setProcessGroup is a no-op: windows has no process groups that can be signaled
+func setProcessGroup(_ *exec.Cmd) {}
+
+// signalProcessGroup kills the command: windows only supports sending kill to a process
+func signalProcessGroup(cmd *exec.Cmd, _ os.Signal) error {
+	return cmd.Process.Kill()
+}
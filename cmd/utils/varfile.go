@@ -0,0 +1,157 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/compose-spec/godotenv"
+	"github.com/okteto/okteto/pkg/cmd/pipeline"
+	oktetoLog "github.com/okteto/okteto/pkg/log"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// VarFileFlagUsage is the usage of the --var-file flag
+const VarFileFlagUsage = "set the variables defined in a dotenv or yaml file (can be set more than once). Variables set with --var take precedence"
+
+// fileVariable represents a variable defined in a var file
+type fileVariable struct {
+	name   string
+	value  string
+	secret bool
+}
+
+// yamlVariable is the extended format of a variable in a yaml var file:
+//
+//	DB_PASSWORD:
+//	  value: s3cr3t
+//	  secret: true
+type yamlVariable struct {
+	Value  string `yaml:"value"`
+	Secret bool   `yaml:"secret"`
+}
+
+// LoadVariables returns the variables of the var files followed by the variables of the flags in KEY=VALUE format.
+// When a variable is defined more than once the last definition wins: flags override files and every file overrides the previous ones.
+// The values of the variables flagged as secret are masked in the logs and redacted in the deploy history
+func LoadVariables(files, variables []string) ([]string, error) {
+	values := map[string]string{}
+	order := []string{}
+	set := func(name, value string) {
+		if _, ok := values[name]; !ok {
+			order = append(order, name)
+		}
+		values[name] = value
+	}
+
+	for _, file := range files {
+		fileVars, err := readVarFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range fileVars {
+			if v.secret {
+				pipeline.AddSecretVariable(v.name)
+			}
+			if (v.secret || pipeline.IsSecretVariable(v.name)) && strings.TrimSpace(v.value) != "" {
+				oktetoLog.AddMaskedWord(v.value)
+			}
+			set(v.name, v.value)
+		}
+	}
+
+	for _, v := range variables {
+		kv := strings.SplitN(v, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid variable value '%s': must follow KEY=VALUE format", v)
+		}
+		set(kv[0], kv[1])
+	}
+
+	result := make([]string, 0, len(order))
+	for _, name := range order {
+		result = append(result, fmt.Sprintf("%s=%s", name, values[name]))
+	}
+	return result, nil
+}
+
+// readVarFile reads a var file. Files with the yaml or yml extension are read as yaml, any other file as dotenv
+func readVarFile(file string) ([]fileVariable, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not read var file '%s': %w", file, err)
+	}
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yml", ".yaml":
+		return parseYAMLVarFile(file, content)
+	default:
+		return parseDotenvVarFile(file, content)
+	}
+}
+
+func parseDotenvVarFile(file string, content []byte) ([]fileVariable, error) {
+	envMap, err := godotenv.ParseWithLookup(bytes.NewReader(content), os.LookupEnv)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse var file '%s': %w", file, err)
+	}
+
+	// godotenv returns a map, keep the order of the file
+	result := []fileVariable{}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimPrefix(strings.TrimSpace(line), "export ")
+		name := strings.TrimSpace(strings.SplitN(line, "=", 2)[0])
+		value, ok := envMap[name]
+		if !ok {
+			continue
+		}
+		result = append(result, fileVariable{name: name, value: value})
+		delete(envMap, name)
+	}
+	return result, nil
+}
+
+func parseYAMLVarFile(file string, content []byte) ([]fileVariable, error) {
+	items := yaml.MapSlice{}
+	if err := yaml.UnmarshalStrict(content, &items); err != nil {
+		return nil, fmt.Errorf("could not parse var file '%s': %w", file, err)
+	}
+
+	result := []fileVariable{}
+	for _, item := range items {
+		name := fmt.Sprintf("%v", item.Key)
+		switch value := item.Value.(type) {
+		case nil:
+			result = append(result, fileVariable{name: name})
+		case yaml.MapSlice:
+			encoded, err := yaml.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse variable '%s' of var file '%s': %w", name, file, err)
+			}
+			v := yamlVariable{}
+			if err := yaml.UnmarshalStrict(encoded, &v); err != nil {
+				return nil, fmt.Errorf("could not parse variable '%s' of var file '%s': %w", name, file, err)
+			}
+			result = append(result, fileVariable{name: name, value: v.Value, secret: v.Secret})
+		case []interface{}:
+			return nil, fmt.Errorf("could not parse variable '%s' of var file '%s': lists are not supported", name, file)
+		default:
+			result = append(result, fileVariable{name: name, value: fmt.Sprintf("%v", value)})
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,153 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/okteto/okteto/pkg/cmd/pipeline"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeVarFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadVariables(t *testing.T) {
+	dotenv := writeVarFile(t, "vars.env", `# comment
+API_URL=https://api.example.com
+export REPLICAS=2
+DB_PASSWORD="s3cr3t"
+`)
+	yamlFile := writeVarFile(t, "vars.yaml", `REPLICAS: 3
+DB_CONNECTION:
+  value: postgres://db:5432
+  secret: true
+DEBUG: true
+EMPTY:
+`)
+
+	tests := []struct {
+		name      string
+		files     []string
+		variables []string
+		expected  []string
+	}{
+		{
+			name:      "only flags",
+			variables: []string{"A=1", "B=2"},
+			expected:  []string{"A=1", "B=2"},
+		},
+		{
+			name:     "dotenv file",
+			files:    []string{dotenv},
+			expected: []string{"API_URL=https://api.example.com", "REPLICAS=2", "DB_PASSWORD=s3cr3t"},
+		},
+		{
+			name:     "yaml file",
+			files:    []string{yamlFile},
+			expected: []string{"REPLICAS=3", "DB_CONNECTION=postgres://db:5432", "DEBUG=true", "EMPTY="},
+		},
+		{
+			name:      "last definition wins",
+			files:     []string{dotenv, yamlFile},
+			variables: []string{"API_URL=http://localhost"},
+			expected: []string{
+				"API_URL=http://localhost",
+				"REPLICAS=3",
+				"DB_PASSWORD=s3cr3t",
+				"DB_CONNECTION=postgres://db:5432",
+				"DEBUG=true",
+				"EMPTY=",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := LoadVariables(tt.files, tt.variables)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestLoadVariablesSecretsAreRedactedInHistory(t *testing.T) {
+	yamlFile := writeVarFile(t, "vars.yaml", `API_URL: https://api.example.com
+DB_CONNECTION:
+  value: postgres://db:5432/movies
+  secret: true
+`)
+
+	result, err := LoadVariables([]string{yamlFile}, nil)
+	assert.NoError(t, err)
+
+	expected := []pipeline.HistoryVariable{
+		{Name: "API_URL", Value: "https://api.example.com"},
+		{Name: "DB_CONNECTION", Value: pipeline.RedactedValue, Redacted: true},
+	}
+	assert.Equal(t, expected, pipeline.NewHistoryVariables(result, true))
+}
+
+func TestLoadVariablesErrors(t *testing.T) {
+	list := writeVarFile(t, "list.yml", `HOSTS:
+- a
+- b
+`)
+	unknownField := writeVarFile(t, "unknown.yaml", `DB_PASSWORD:
+  value: s3cr3t
+  masked: true
+`)
+
+	tests := []struct {
+		name        string
+		files       []string
+		variables   []string
+		expectedErr string
+	}{
+		{
+			name:        "missing file",
+			files:       []string{filepath.Join(t.TempDir(), "missing.env")},
+			expectedErr: "could not read var file",
+		},
+		{
+			name:        "list value",
+			files:       []string{list},
+			expectedErr: "lists are not supported",
+		},
+		{
+			name:        "unknown field",
+			files:       []string{unknownField},
+			expectedErr: "could not parse variable 'DB_PASSWORD'",
+		},
+		{
+			name:        "invalid flag",
+			variables:   []string{"A"},
+			expectedErr: "must follow KEY=VALUE format",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadVariables(tt.files, tt.variables)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
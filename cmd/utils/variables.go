@@ -11,7 +11,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package deploy
+package utils
 
 import (
 	"fmt"
@@ -23,7 +23,9 @@ type envVar struct {
 	value string
 }
 
-func validateAndSet(variables []string, setEnv func(key, value string) error) error {
+// SetVariables validates a list of KEY=VALUE variables and sets them with setEnv, usually os.Setenv,
+// so they are expanded in the manifest and available to its commands
+func SetVariables(variables []string, setEnv func(key, value string) error) error {
 	envVars, err := parse(variables)
 	if err != nil {
 		return err
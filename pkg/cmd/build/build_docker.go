@@ -245,6 +245,7 @@ func displayStatus(eg *errgroup.Group, response dockerTypes.ImageBuildResponse,
 
 	buf := bytes.NewBuffer(nil)
 
+	emitted := map[string]bool{}
 	writeAux := func(msg jsonmessage.JSONMessage) {
 		if msg.ID == "moby.image.id" {
 			var result dockerTypes.BuildResult
@@ -300,6 +301,7 @@ func displayStatus(eg *errgroup.Group, response dockerTypes.ImageBuildResponse,
 			})
 		}
 
+		emitBuildSteps("", &s, emitted)
 		displayCh <- &s
 	}
 
@@ -216,14 +216,17 @@ func solveBuild(ctx context.Context, c *client.Client, opt *client.SolveOpt, pro
 		return errors.Wrap(err, "build failed")
 	})
 
+	image := getExportedImage(opt)
 	eg.Go(func() error {
 		done := false
+		emitted := map[string]bool{}
 		for {
 			select {
 			case <-ctx.Done():
 				return ctx.Err()
 			case ss, ok := <-ch:
 				if ok {
+					emitBuildSteps(image, ss, emitted)
 					plainChannel <- ss
 					if progress == oktetoLog.TTYFormat {
 						ttyChannel <- ss
@@ -263,6 +266,36 @@ func solveBuild(ctx context.Context, c *client.Client, opt *client.SolveOpt, pro
 	return eg.Wait()
 }
 
+// emitBuildSteps emits a build_step event for every step completed since the last status
+func emitBuildSteps(image string, ss *client.SolveStatus, emitted map[string]bool) {
+	for _, v := range ss.Vertexes {
+		if v.Completed == nil || emitted[v.Digest.String()] {
+			continue
+		}
+		emitted[v.Digest.String()] = true
+		data := oktetoLog.BuildStepData{
+			Image:  image,
+			Step:   v.Name,
+			Cached: v.Cached,
+			Error:  v.Error,
+		}
+		if v.Started != nil {
+			data.DurationMs = v.Completed.Sub(*v.Started).Milliseconds()
+		}
+		oktetoLog.BuildStep(data)
+	}
+}
+
+// getExportedImage returns the name of the image pushed by a build
+func getExportedImage(opt *client.SolveOpt) string {
+	for _, e := range opt.Exports {
+		if name := e.Attrs["name"]; name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
 func (*buildWriter) Write(p []byte) (int, error) {
 	msg := strings.TrimSpace(string(p))
 	oktetoLog.AddToBuffer(oktetoLog.InfoLevel, msg)
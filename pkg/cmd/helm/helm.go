@@ -0,0 +1,150 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	oktetoLog "github.com/okteto/okteto/pkg/log"
+	"github.com/okteto/okteto/pkg/model"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/cli/values"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+const helmDriverEnvVar = "HELM_DRIVER"
+
+// Options defines how a chart is deployed
+type Options struct {
+	Release   string
+	Namespace string
+	// Timeout is the time to wait for the resources of the release if the chart sets 'wait'
+	Timeout time.Duration
+}
+
+// Deploy installs the chart, or upgrades the release if it already exists
+func Deploy(ctx context.Context, spec *model.HelmDeploy, opts *Options, clientGetter genericclioptions.RESTClientGetter) (*release.Release, error) {
+	cfg, err := newActionConfiguration(clientGetter, opts.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	settings := cli.New()
+	settings.SetNamespace(opts.Namespace)
+
+	valueOpts := &values.Options{
+		ValueFiles: spec.ValuesFiles,
+		Values:     getSetValues(spec.Set),
+	}
+	vals, err := valueOpts.MergeValues(getter.All(settings))
+	if err != nil {
+		return nil, fmt.Errorf("could not read the values of release '%s': %w", opts.Release, err)
+	}
+
+	history := action.NewHistory(cfg)
+	history.Max = 1
+	if _, err := history.Run(opts.Release); err != nil {
+		if !errors.Is(err, driver.ErrReleaseNotFound) {
+			return nil, fmt.Errorf("could not get the history of release '%s': %w", opts.Release, err)
+		}
+		install := action.NewInstall(cfg)
+		install.ReleaseName = opts.Release
+		install.Namespace = opts.Namespace
+		install.Wait = spec.Wait
+		install.Timeout = opts.Timeout
+		install.ChartPathOptions.RepoURL = spec.Repository
+		install.ChartPathOptions.Version = spec.Version
+		ch, err := loadChart(spec.Chart, &install.ChartPathOptions, settings)
+		if err != nil {
+			return nil, err
+		}
+		return install.RunWithContext(ctx, ch, vals)
+	}
+
+	upgrade := action.NewUpgrade(cfg)
+	upgrade.Namespace = opts.Namespace
+	upgrade.Wait = spec.Wait
+	upgrade.Timeout = opts.Timeout
+	upgrade.ChartPathOptions.RepoURL = spec.Repository
+	upgrade.ChartPathOptions.Version = spec.Version
+	ch, err := loadChart(spec.Chart, &upgrade.ChartPathOptions, settings)
+	if err != nil {
+		return nil, err
+	}
+	return upgrade.RunWithContext(ctx, opts.Release, ch, vals)
+}
+
+// Uninstall uninstalls a release. It's a no-op if the release doesn't exist
+func Uninstall(release, namespace string, clientGetter genericclioptions.RESTClientGetter) error {
+	cfg, err := newActionConfiguration(clientGetter, namespace)
+	if err != nil {
+		return err
+	}
+	uninstall := action.NewUninstall(cfg)
+	if _, err := uninstall.Run(release); err != nil {
+		if errors.Is(err, driver.ErrReleaseNotFound) {
+			oktetoLog.Infof("helm release '%s' not found", release)
+			return nil
+		}
+		return fmt.Errorf("could not uninstall release '%s': %w", release, err)
+	}
+	return nil
+}
+
+func newActionConfiguration(clientGetter genericclioptions.RESTClientGetter, namespace string) (*action.Configuration, error) {
+	helmDriver := os.Getenv(helmDriverEnvVar)
+	cfg := &action.Configuration{}
+	if err := cfg.Init(clientGetter, namespace, helmDriver, oktetoLog.Debugf); err != nil {
+		return nil, fmt.Errorf("could not initialize helm: %w", err)
+	}
+	return cfg, nil
+}
+
+// loadChart downloads the chart if needed and checks its dependencies are available
+func loadChart(name string, pathOpts *action.ChartPathOptions, settings *cli.EnvSettings) (*chart.Chart, error) {
+	chartPath, err := pathOpts.LocateChart(name, settings)
+	if err != nil {
+		return nil, fmt.Errorf("could not find chart '%s': %w", name, err)
+	}
+	ch, err := loader.Load(chartPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not load chart '%s': %w", name, err)
+	}
+	if ch.Metadata.Dependencies != nil {
+		if err := action.CheckDependencies(ch, ch.Metadata.Dependencies); err != nil {
+			return nil, fmt.Errorf("chart '%s' has missing dependencies, run 'helm dependency update %s': %w", name, chartPath, err)
+		}
+	}
+	return ch, nil
+}
+
+// getSetValues translates the set values to the helm format, sorted so the result is stable
+func getSetValues(set map[string]string) []string {
+	result := []string{}
+	for k, v := range set {
+		result = append(result, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(result)
+	return result
+}
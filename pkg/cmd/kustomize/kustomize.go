@@ -0,0 +1,164 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kustomize
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	oktetoLog "github.com/okteto/okteto/pkg/log"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const fieldManager = "okteto"
+
+// Build renders the kustomization at path. The images of the containers are replaced by the images
+// with the same name, ignoring tag and digest
+func Build(path string, images map[string]string) ([]*unstructured.Unstructured, error) {
+	k := krusty.MakeKustomizer(krusty.MakeDefaultOptions())
+	resMap, err := k.Run(filesys.MakeFsOnDisk(), path)
+	if err != nil {
+		return nil, fmt.Errorf("could not build kustomization '%s': %w", path, err)
+	}
+
+	result := []*unstructured.Unstructured{}
+	for _, r := range resMap.Resources() {
+		content, err := r.Map()
+		if err != nil {
+			return nil, fmt.Errorf("could not decode resource '%s': %w", r.CurId().String(), err)
+		}
+		obj := &unstructured.Unstructured{Object: content}
+		replaceImages(obj.Object, images)
+		result = append(result, obj)
+	}
+	return result, nil
+}
+
+// Apply creates or updates the objects using server side apply
+func Apply(ctx context.Context, objs []*unstructured.Unstructured, namespace string, c dynamic.Interface, mapper meta.RESTMapper) error {
+	for _, obj := range objs {
+		ri, err := getResourceInterface(obj, namespace, c, mapper)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(obj.Object)
+		if err != nil {
+			return fmt.Errorf("could not encode %s '%s': %w", obj.GetKind(), obj.GetName(), err)
+		}
+		force := true
+		if _, err := ri.Patch(ctx, obj.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{FieldManager: fieldManager, Force: &force}); err != nil {
+			return fmt.Errorf("could not apply %s '%s': %w", obj.GetKind(), obj.GetName(), err)
+		}
+		oktetoLog.Infof("applied %s '%s'", obj.GetKind(), obj.GetName())
+	}
+	return nil
+}
+
+// Delete deletes the objects, in reverse order. Objects that don't exist are ignored
+func Delete(ctx context.Context, objs []*unstructured.Unstructured, namespace string, c dynamic.Interface, mapper meta.RESTMapper) error {
+	for i := len(objs) - 1; i >= 0; i-- {
+		obj := objs[i]
+		ri, err := getResourceInterface(obj, namespace, c, mapper)
+		if err != nil {
+			if meta.IsNoMatchError(err) {
+				oktetoLog.Infof("skipping %s '%s': %s", obj.GetKind(), obj.GetName(), err)
+				continue
+			}
+			return err
+		}
+		if err := ri.Delete(ctx, obj.GetName(), metav1.DeleteOptions{}); err != nil {
+			if k8sErrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("could not delete %s '%s': %w", obj.GetKind(), obj.GetName(), err)
+		}
+		oktetoLog.Infof("deleted %s '%s'", obj.GetKind(), obj.GetName())
+	}
+	return nil
+}
+
+func getResourceInterface(obj *unstructured.Unstructured, namespace string, c dynamic.Interface, mapper meta.RESTMapper) (dynamic.ResourceInterface, error) {
+	gvk := obj.GroupVersionKind()
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, fmt.Errorf("could not find resource for %s '%s': %w", gvk.Kind, obj.GetName(), err)
+	}
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return c.Resource(mapping.Resource), nil
+	}
+	if obj.GetNamespace() == "" {
+		obj.SetNamespace(namespace)
+	}
+	return c.Resource(mapping.Resource).Namespace(obj.GetNamespace()), nil
+}
+
+// replaceImages walks the object replacing the image of every container or init container
+func replaceImages(obj interface{}, images map[string]string) {
+	if len(images) == 0 {
+		return
+	}
+	switch value := obj.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			if key == "containers" || key == "initContainers" {
+				replaceContainerImages(child, images)
+			}
+			replaceImages(child, images)
+		}
+	case []interface{}:
+		for _, child := range value {
+			replaceImages(child, images)
+		}
+	}
+}
+
+func replaceContainerImages(containers interface{}, images map[string]string) {
+	list, ok := containers.([]interface{})
+	if !ok {
+		return
+	}
+	for _, c := range list {
+		container, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		image, ok := container["image"].(string)
+		if !ok {
+			continue
+		}
+		if override, ok := images[getImageName(image)]; ok {
+			container["image"] = override
+		}
+	}
+}
+
+// getImageName returns the name of the image without tag or digest
+func getImageName(image string) string {
+	if i := strings.Index(image, "@"); i != -1 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i != -1 && !strings.Contains(image[i:], "/") {
+		image = image[:i]
+	}
+	return image
+}
@@ -0,0 +1,89 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kustomize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	kustomization = `resources:
+- deployment.yaml
+commonLabels:
+  app: api
+`
+	deployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: migrate
+        image: api:1.0
+      containers:
+      - name: api
+        image: api:1.0
+      - name: proxy
+        image: nginx
+`
+)
+
+func TestBuild(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "kustomization.yaml"), []byte(kustomization), 0600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "deployment.yaml"), []byte(deployment), 0600))
+
+	objs, err := Build(dir, map[string]string{"api": "okteto.dev/api:okteto"})
+	assert.NoError(t, err)
+	assert.Len(t, objs, 1)
+	assert.Equal(t, "Deployment", objs[0].GetKind())
+	assert.Equal(t, map[string]string{"app": "api"}, objs[0].GetLabels())
+
+	containers, _, _ := unstructured.NestedSlice(objs[0].Object, "spec", "template", "spec", "containers")
+	initContainers, _, _ := unstructured.NestedSlice(objs[0].Object, "spec", "template", "spec", "initContainers")
+	assert.Equal(t, "okteto.dev/api:okteto", containers[0].(map[string]interface{})["image"])
+	assert.Equal(t, "nginx", containers[1].(map[string]interface{})["image"])
+	assert.Equal(t, "okteto.dev/api:okteto", initContainers[0].(map[string]interface{})["image"])
+}
+
+func TestBuildError(t *testing.T) {
+	_, err := Build(t.TempDir(), nil)
+	assert.Error(t, err)
+}
+
+func Test_getImageName(t *testing.T) {
+	tests := []struct {
+		image    string
+		expected string
+	}{
+		{image: "api", expected: "api"},
+		{image: "api:1.0", expected: "api"},
+		{image: "registry:5000/api", expected: "registry:5000/api"},
+		{image: "registry:5000/api:1.0", expected: "registry:5000/api"},
+		{image: "api@sha256:abc", expected: "api"},
+		{image: "api:1.0@sha256:abc", expected: "api"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			assert.Equal(t, tt.expected, getImageName(tt.image))
+		})
+	}
+}
@@ -16,13 +16,21 @@ package pipeline
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/okteto/okteto/pkg/k8s/configmaps"
 	"github.com/okteto/okteto/pkg/k8s/deployments"
 	"github.com/okteto/okteto/pkg/k8s/statefulsets"
+	oktetoLog "github.com/okteto/okteto/pkg/log"
 	"github.com/okteto/okteto/pkg/model"
 	v1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -76,3 +84,104 @@ func HasDeployedSomething(ctx context.Context, name, ns string, c kubernetes.Int
 
 	return false, nil
 }
+
+// IsOrphan checks if the resources of a pipeline configmap were deleted without destroying the pipeline.
+// Pipelines being deployed or destroyed are never orphans, and a pipeline is only orphan when every object
+// of its inventory is gone and no object of any kind in the namespace is labelled as deployed by it
+func IsOrphan(ctx context.Context, cmap *apiv1.ConfigMap, c kubernetes.Interface, dynClient dynamic.Interface) (bool, error) {
+	switch cmap.Data[statusField] {
+	case ProgressingStatus, DestroyingStatus:
+		return false, nil
+	}
+	lease, err := GetLease(cmap)
+	if err != nil {
+		return false, err
+	}
+	if lease != nil && lease.ExpiresAt.After(time.Now()) {
+		return false, nil
+	}
+
+	inventory, err := GetInventory(cmap)
+	if err != nil {
+		return false, err
+	}
+	exists, err := inventoryExists(ctx, inventory, dynClient)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		return false, nil
+	}
+
+	hasDeployed, err := hasDeployedAnyKind(ctx, cmap.Data[nameField], cmap.Namespace, c.Discovery(), dynClient)
+	if err != nil {
+		return false, err
+	}
+	return !hasDeployed, nil
+}
+
+// inventoryExists checks if any object of the inventory still exists. Objects recreated with a different uid don't count
+func inventoryExists(ctx context.Context, inventory Inventory, dynClient dynamic.Interface) (bool, error) {
+	for _, item := range inventory {
+		gvr, err := item.GroupVersionResource()
+		if err != nil {
+			return false, err
+		}
+		var resource dynamic.ResourceInterface = dynClient.Resource(gvr)
+		if item.Namespace != "" {
+			resource = dynClient.Resource(gvr).Namespace(item.Namespace)
+		}
+		obj, err := resource.Get(ctx, item.Name, metav1.GetOptions{})
+		if err != nil {
+			if k8sErrors.IsNotFound(err) {
+				continue
+			}
+			return false, fmt.Errorf("error getting %s: %w", item.String(), err)
+		}
+		if item.UID == "" || string(obj.GetUID()) == item.UID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// hasDeployedAnyKind checks if there is an object of any namespaced kind labelled as deployed by the pipeline.
+// Kinds the user isn't allowed to list are skipped
+func hasDeployedAnyKind(ctx context.Context, name, ns string, discClient discovery.DiscoveryInterface, dynClient dynamic.Interface) (bool, error) {
+	resourceLists, err := discovery.ServerPreferredNamespacedResources(discClient)
+	if err != nil {
+		if !discovery.IsGroupDiscoveryFailedError(err) {
+			return false, err
+		}
+		oktetoLog.Debugf("some api groups are not available: %s", err)
+	}
+	resourceLists = discovery.FilteredBy(discovery.SupportsAllVerbs{Verbs: []string{"list"}}, resourceLists)
+
+	listOptions := metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", model.DeployedByLabel, name),
+		Limit:         1,
+	}
+	for _, resourceList := range resourceLists {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			return false, err
+		}
+		for _, r := range resourceList.APIResources {
+			if strings.Contains(r.Name, "/") {
+				continue
+			}
+			list, err := dynClient.Resource(gv.WithResource(r.Name)).Namespace(ns).List(ctx, listOptions)
+			if err != nil {
+				if k8sErrors.IsForbidden(err) || k8sErrors.IsNotFound(err) || k8sErrors.IsMethodNotSupported(err) {
+					oktetoLog.Debugf("skipping %s when looking for resources deployed by '%s': %s", r.Name, name, err)
+					continue
+				}
+				return false, err
+			}
+			if len(list.Items) > 0 {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,150 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+)
+
+const (
+	historyField = "history"
+
+	// maxHistoryEntries is the number of deploys kept in the pipeline configmap
+	maxHistoryEntries = 10
+
+	// RedactedValue replaces the value of the variables that are not stored in the history
+	RedactedValue = "******"
+)
+
+// secretVariableRegex matches the names of the variables that are not stored in the history
+var secretVariableRegex = regexp.MustCompile(`(?i)(pass|secret|token|key|credential|auth|cert|private)`)
+
+var (
+	// secretVariables are the variables flagged as secret in the var files
+	secretVariables   = map[string]bool{}
+	secretVariablesMu sync.RWMutex
+)
+
+// HistoryEntry represents a deploy of a development environment
+type HistoryEntry struct {
+	ID        int               `json:"id"`
+	Status    string            `json:"status"`
+	StartTime time.Time         `json:"startTime"`
+	EndTime   time.Time         `json:"endTime"`
+	User      string            `json:"user,omitempty"`
+	GitSHA    string            `json:"gitSHA,omitempty"`
+	Variables []HistoryVariable `json:"variables,omitempty"`
+	Commands  []HistoryCommand  `json:"commands,omitempty"`
+}
+
+// HistoryVariable represents a variable used by a deploy
+type HistoryVariable struct {
+	Name     string `json:"name"`
+	Value    string `json:"value,omitempty"`
+	Redacted bool   `json:"redacted,omitempty"`
+}
+
+// HistoryCommand represents a step executed by a deploy
+type HistoryCommand struct {
+	Name     string        `json:"name"`
+	Status   string        `json:"status"`
+	Duration time.Duration `json:"duration"`
+}
+
+// History is the list of the latest deploys of a development environment, oldest first
+type History []HistoryEntry
+
+// Duration returns the time spent by the deploy
+func (e HistoryEntry) Duration() time.Duration {
+	if e.EndTime.IsZero() {
+		return 0
+	}
+	return e.EndTime.Sub(e.StartTime)
+}
+
+// GetHistory returns the deploy history stored in the pipeline configmap
+func GetHistory(cmap *apiv1.ConfigMap) (History, error) {
+	if cmap == nil || cmap.Data[historyField] == "" {
+		return History{}, nil
+	}
+	history := History{}
+	if err := json.Unmarshal([]byte(cmap.Data[historyField]), &history); err != nil {
+		return nil, fmt.Errorf("could not decode the deploy history of '%s': %w", cmap.Name, err)
+	}
+	return history, nil
+}
+
+// Add appends a deploy to the history assigning it the next id. Only the latest deploys are kept
+func (h History) Add(entry HistoryEntry) History {
+	entry.ID = 1
+	if len(h) > 0 {
+		entry.ID = h[len(h)-1].ID + 1
+	}
+	result := append(History{}, h...)
+	result = append(result, entry)
+	if len(result) > maxHistoryEntries {
+		result = result[len(result)-maxHistoryEntries:]
+	}
+	return result
+}
+
+// Get returns the deploy with the given id
+func (h History) Get(id int) (*HistoryEntry, error) {
+	for i := range h {
+		if h[i].ID == id {
+			return &h[i], nil
+		}
+	}
+	return nil, fmt.Errorf("deploy '%d' not found in the history", id)
+}
+
+// NewHistoryVariables translates a list of KEY=VALUE variables. Only the names are stored unless storeValues is set,
+// the values of the secret variables are never stored
+func NewHistoryVariables(variables []string, storeValues bool) []HistoryVariable {
+	result := []HistoryVariable{}
+	for _, v := range variables {
+		kv := strings.SplitN(v, "=", 2)
+		variable := HistoryVariable{Name: kv[0]}
+		if len(kv) == 2 {
+			variable.Value = kv[1]
+		}
+		if !storeValues || IsSecretVariable(variable.Name) {
+			variable.Value = RedactedValue
+			variable.Redacted = true
+		}
+		result = append(result, variable)
+	}
+	return result
+}
+
+// AddSecretVariable flags a variable as secret, its value is not stored in the history
+func AddSecretVariable(name string) {
+	secretVariablesMu.Lock()
+	defer secretVariablesMu.Unlock()
+	secretVariables[name] = true
+}
+
+// IsSecretVariable returns if the value of a variable must not be stored in the history
+func IsSecretVariable(name string) bool {
+	secretVariablesMu.RLock()
+	defer secretVariablesMu.RUnlock()
+	return secretVariables[name] || secretVariableRegex.MatchString(name)
+}
@@ -0,0 +1,73 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+func Test_GetHistory(t *testing.T) {
+	history, err := GetHistory(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, History{}, history)
+
+	history, err = GetHistory(&apiv1.ConfigMap{Data: map[string]string{
+		historyField: `[{"id":3,"status":"deployed","startTime":"2022-01-01T00:00:00Z","endTime":"2022-01-01T00:01:00Z"}]`,
+	}})
+	assert.NoError(t, err)
+	assert.Len(t, history, 1)
+	assert.Equal(t, 3, history[0].ID)
+	assert.Equal(t, "1m0s", history[0].Duration().String())
+
+	_, err = GetHistory(&apiv1.ConfigMap{Data: map[string]string{historyField: `{`}})
+	assert.Error(t, err)
+}
+
+func Test_HistoryAdd(t *testing.T) {
+	history := History{}
+	for i := 0; i < maxHistoryEntries+2; i++ {
+		history = history.Add(HistoryEntry{Status: DeployedStatus})
+	}
+	assert.Len(t, history, maxHistoryEntries)
+	assert.Equal(t, 3, history[0].ID)
+	assert.Equal(t, maxHistoryEntries+2, history[len(history)-1].ID)
+
+	entry, err := history.Get(5)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, entry.ID)
+
+	_, err = history.Get(1)
+	assert.Error(t, err)
+}
+
+func Test_NewHistoryVariables(t *testing.T) {
+	variables := []string{"NAME=value", "API_TOKEN=abc", "DB_PASSWORD=a=b", "EMPTY="}
+	result := NewHistoryVariables(variables, false)
+	for _, v := range result {
+		assert.Equal(t, RedactedValue, v.Value)
+		assert.True(t, v.Redacted)
+	}
+
+	result = NewHistoryVariables(variables, true)
+	expected := []HistoryVariable{
+		{Name: "NAME", Value: "value"},
+		{Name: "API_TOKEN", Value: RedactedValue, Redacted: true},
+		{Name: "DB_PASSWORD", Value: RedactedValue, Redacted: true},
+		{Name: "EMPTY"},
+	}
+	assert.Equal(t, expected, result)
+}
@@ -0,0 +1,110 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// InventoryItem represents an object created by a deploy
+type InventoryItem struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Resource   string `json:"resource"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	UID        string `json:"uid,omitempty"`
+}
+
+// Inventory is the list of objects created by the deploys of a development environment, in creation order
+type Inventory []InventoryItem
+
+// GroupVersionResource returns the resource of the item in the API
+func (i InventoryItem) GroupVersionResource() (schema.GroupVersionResource, error) {
+	gv, err := schema.ParseGroupVersion(i.APIVersion)
+	if err != nil {
+		return schema.GroupVersionResource{}, err
+	}
+	return gv.WithResource(i.Resource), nil
+}
+
+// String returns a human readable reference to the item
+func (i InventoryItem) String() string {
+	if i.Namespace == "" {
+		return fmt.Sprintf("%s '%s'", i.Kind, i.Name)
+	}
+	return fmt.Sprintf("%s '%s/%s'", i.Kind, i.Namespace, i.Name)
+}
+
+// Matches returns if both items refer to the same object, regardless of its version and uid
+func (i InventoryItem) Matches(other InventoryItem) bool {
+	return i.key() == other.key()
+}
+
+// key identifies an object regardless of the version used to create it
+func (i InventoryItem) key() string {
+	gv, err := schema.ParseGroupVersion(i.APIVersion)
+	group := i.APIVersion
+	if err == nil {
+		group = gv.Group
+	}
+	return fmt.Sprintf("%s/%s/%s/%s", group, i.Resource, i.Namespace, i.Name)
+}
+
+// GetInventory returns the inventory stored in the pipeline configmap
+func GetInventory(cmap *apiv1.ConfigMap) (Inventory, error) {
+	if cmap == nil || cmap.Data[inventoryField] == "" {
+		return Inventory{}, nil
+	}
+	var inventory Inventory
+	if err := json.Unmarshal([]byte(cmap.Data[inventoryField]), &inventory); err != nil {
+		return nil, fmt.Errorf("could not decode the inventory of '%s': %w", cmap.Name, err)
+	}
+	return inventory, nil
+}
+
+// Update returns the inventory removing the deleted objects and adding the created ones.
+// Deletions only apply to the current inventory, objects deleted and created again are kept
+func (inv Inventory) Update(created, deleted []InventoryItem) Inventory {
+	deletedKeys := map[string]bool{}
+	for _, item := range deleted {
+		deletedKeys[item.key()] = true
+	}
+
+	result := Inventory{}
+	indexes := map[string]int{}
+	for _, item := range inv {
+		k := item.key()
+		if deletedKeys[k] {
+			continue
+		}
+		indexes[k] = len(result)
+		result = append(result, item)
+	}
+	for _, item := range created {
+		k := item.key()
+		if idx, ok := indexes[k]; ok {
+			// the object was recreated, keep the latest uid
+			result[idx] = item
+			continue
+		}
+		indexes[k] = len(result)
+		result = append(result, item)
+	}
+	return result
+}
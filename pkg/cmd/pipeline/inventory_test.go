@@ -0,0 +1,114 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+func Test_GetInventory(t *testing.T) {
+	tests := []struct {
+		name      string
+		cmap      *apiv1.ConfigMap
+		expected  Inventory
+		expectErr bool
+	}{
+		{
+			name:     "nil configmap",
+			expected: Inventory{},
+		},
+		{
+			name:     "without inventory",
+			cmap:     &apiv1.ConfigMap{Data: map[string]string{}},
+			expected: Inventory{},
+		},
+		{
+			name: "with inventory",
+			cmap: &apiv1.ConfigMap{Data: map[string]string{
+				inventoryField: `[{"apiVersion":"v1","kind":"Service","resource":"services","namespace":"test","name":"api","uid":"1"}]`,
+			}},
+			expected: Inventory{
+				{APIVersion: "v1", Kind: "Service", Resource: "services", Namespace: "test", Name: "api", UID: "1"},
+			},
+		},
+		{
+			name: "wrong inventory",
+			cmap: &apiv1.ConfigMap{Data: map[string]string{
+				inventoryField: `{`,
+			}},
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := GetInventory(tt.cmap)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func Test_InventoryUpdate(t *testing.T) {
+	service := InventoryItem{APIVersion: "v1", Kind: "Service", Resource: "services", Namespace: "test", Name: "api", UID: "1"}
+	deployment := InventoryItem{APIVersion: "apps/v1", Kind: "Deployment", Resource: "deployments", Namespace: "test", Name: "api", UID: "2"}
+	clusterRole := InventoryItem{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole", Resource: "clusterroles", Name: "api", UID: "3"}
+	recreatedDeployment := deployment
+	recreatedDeployment.UID = "4"
+	deletedDeployment := InventoryItem{APIVersion: "apps/v1beta1", Resource: "deployments", Namespace: "test", Name: "api"}
+
+	tests := []struct {
+		name      string
+		inventory Inventory
+		created   []InventoryItem
+		deleted   []InventoryItem
+		expected  Inventory
+	}{
+		{
+			name:     "empty inventory",
+			created:  []InventoryItem{service, clusterRole},
+			expected: Inventory{service, clusterRole},
+		},
+		{
+			name:      "recreated object keeps its position",
+			inventory: Inventory{deployment, service},
+			created:   []InventoryItem{recreatedDeployment, clusterRole},
+			expected:  Inventory{recreatedDeployment, service, clusterRole},
+		},
+		{
+			name:      "deleted object with other version",
+			inventory: Inventory{deployment, service},
+			deleted:   []InventoryItem{deletedDeployment},
+			expected:  Inventory{service},
+		},
+		{
+			name:      "deleted and created again",
+			inventory: Inventory{deployment, service},
+			created:   []InventoryItem{recreatedDeployment},
+			deleted:   []InventoryItem{deletedDeployment},
+			expected:  Inventory{service, recreatedDeployment},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.inventory.Update(tt.created, tt.deleted))
+		})
+	}
+}
@@ -0,0 +1,389 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/google/uuid"
+	oktetoErrors "github.com/okteto/okteto/pkg/errors"
+	"github.com/okteto/okteto/pkg/k8s/configmaps"
+	oktetoLog "github.com/okteto/okteto/pkg/log"
+	"github.com/okteto/okteto/pkg/model"
+	apiv1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	leaseField = "lease"
+
+	// DeployOperation is the operation of the lock held by a deploy
+	DeployOperation = "deploy"
+	// DestroyOperation is the operation of the lock held by a destroy
+	DestroyOperation = "destroy"
+
+	// leaseDuration is the time a lock is kept without being renewed
+	leaseDuration = time.Minute
+)
+
+var (
+	// leaseRenewPeriod is the time between heartbeats of a lock
+	leaseRenewPeriod = 20 * time.Second
+	// lockRetryPeriod is the time between attempts to acquire a lock held by another operation
+	lockRetryPeriod = 2 * time.Second
+)
+
+// Lease represents the lock of a development environment held by a deploy or destroy operation
+type Lease struct {
+	ID         string    `json:"id"`
+	Holder     string    `json:"holder"`
+	Operation  string    `json:"operation"`
+	AcquiredAt time.Time `json:"acquiredAt"`
+	RenewedAt  time.Time `json:"renewedAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+// LockOptions defines how a development environment is locked
+type LockOptions struct {
+	Name      string
+	Namespace string
+	// Operation is the command holding the lock, i.e. deploy or destroy
+	Operation string
+	// Timeout is the time to wait for a lock held by another operation. Zero fails immediately
+	Timeout time.Duration
+	// Force releases the lock held by another operation. The action lock of the Okteto backend is never released
+	Force bool
+}
+
+// LockedError is returned when the development environment is locked by another operation
+type LockedError struct {
+	Name  string
+	Lease Lease
+	// ActionLock is the pipeline action of the Okteto backend holding the development environment, if any
+	ActionLock string
+}
+
+// Error returns the error message
+func (e LockedError) Error() string {
+	if e.ActionLock != "" {
+		return fmt.Sprintf("There is a pipeline operation already running: development environment '%s' is locked by the pipeline action '%s'", e.Name, e.ActionLock)
+	}
+	return fmt.Sprintf("development environment '%s' is locked by '%s' running '%s' since %s ago (the lock expires in %s)",
+		e.Name,
+		e.Lease.Holder,
+		e.Lease.Operation,
+		duration.HumanDuration(time.Since(e.Lease.AcquiredAt)),
+		duration.HumanDuration(time.Until(e.Lease.ExpiresAt)),
+	)
+}
+
+// Lock is a lease held over the pipeline configmap of a development environment. It is renewed until released
+type Lock struct {
+	name      string
+	namespace string
+	lease     Lease
+	c         kubernetes.Interface
+	stop      context.CancelFunc
+	done      chan struct{}
+}
+
+// AcquireLock locks a development environment waiting up to the timeout if it's locked by another operation.
+// Destroying a development environment that has never been deployed doesn't lock it and returns a nil lock
+func AcquireLock(ctx context.Context, opts LockOptions, c kubernetes.Interface) (*Lock, error) {
+	lease := newLease(opts.Operation)
+	deadline := time.Now().Add(opts.Timeout)
+	force := opts.Force
+	waiting := false
+	for {
+		held, err := tryAcquireLock(ctx, opts.Name, opts.Namespace, lease, force, c)
+		if err != nil {
+			if errors.Is(err, errNotDeployed) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		if held == nil {
+			break
+		}
+		lockedErr := *held
+		lockedErr.Name = opts.Name
+		if opts.Timeout == 0 || time.Now().After(deadline) {
+			hint := "Use '--lock-timeout' to wait for the lock to be released or '--force-unlock' to release it"
+			if lockedErr.ActionLock != "" {
+				hint = "Use '--lock-timeout' to wait for the pipeline action to finish"
+			}
+			return nil, oktetoErrors.UserError{
+				E:    lockedErr,
+				Hint: hint,
+			}
+		}
+		if !waiting {
+			oktetoLog.Information("%s. Waiting for the lock to be released...", lockedErr.Error())
+			waiting = true
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockRetryPeriod):
+		}
+	}
+
+	heartbeatCtx, stop := context.WithCancel(context.Background())
+	l := &Lock{
+		name:      opts.Name,
+		namespace: opts.Namespace,
+		lease:     lease,
+		c:         c,
+		stop:      stop,
+		done:      make(chan struct{}),
+	}
+	go l.heartbeat(heartbeatCtx)
+	return l, nil
+}
+
+// errNotDeployed is returned when destroying a development environment without pipeline configmap
+var errNotDeployed = errors.New("development environment not deployed")
+
+// tryAcquireLock sets the lease in the pipeline configmap. It returns the lock of the other operation if the development environment is locked.
+// The action lock set by the pipeline actions of the Okteto backend is owned by the backend: it's never released by the CLI
+func tryAcquireLock(ctx context.Context, name, namespace string, lease Lease, force bool, c kubernetes.Interface) (*LockedError, error) {
+	var held *LockedError
+	err := retry.OnError(retry.DefaultRetry, isLockRetriable, func() error {
+		held = nil
+		cmap, err := configmaps.Get(ctx, TranslatePipelineName(name), namespace, c)
+		if err != nil {
+			if !oktetoErrors.IsNotFound(err) {
+				return err
+			}
+			if lease.Operation == DestroyOperation {
+				return errNotDeployed
+			}
+			return configmaps.Create(ctx, translateLockConfigMap(name, namespace, lease), namespace, c)
+		}
+
+		if action := getActionLock(cmap); action != "" {
+			held = &LockedError{ActionLock: action}
+			return nil
+		}
+
+		current, err := GetLease(cmap)
+		if err != nil {
+			oktetoLog.Infof("ignoring invalid lease: %s", err)
+		}
+		if current != nil && current.ID != lease.ID && time.Now().Before(current.ExpiresAt) {
+			if !force {
+				held = &LockedError{Lease: *current}
+				return nil
+			}
+			oktetoLog.Warning("Releasing the lock held by '%s' running '%s'", current.Holder, current.Operation)
+		}
+		return setLease(ctx, cmap, &lease, c)
+	})
+	if err != nil {
+		if errors.Is(err, errNotDeployed) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("could not lock development environment '%s': %w", name, err)
+	}
+	return held, nil
+}
+
+func isLockRetriable(err error) bool {
+	return k8sErrors.IsConflict(err) || k8sErrors.IsAlreadyExists(err)
+}
+
+// Release stops renewing the lease and removes it from the pipeline configmap if it's still held
+func (l *Lock) Release(ctx context.Context) {
+	if l == nil {
+		return
+	}
+	l.stop()
+	<-l.done
+
+	err := retry.OnError(retry.DefaultRetry, isLeasePatchRetriable, func() error {
+		cmap, err := configmaps.Get(ctx, TranslatePipelineName(l.name), l.namespace, l.c)
+		if err != nil {
+			if oktetoErrors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		current, _ := GetLease(cmap)
+		if current == nil || current.ID != l.lease.ID {
+			return nil
+		}
+		return patchLease(ctx, cmap, nil, l.c)
+	})
+	if err != nil {
+		oktetoLog.Infof("could not release the lock of '%s': %s", l.name, err)
+	}
+}
+
+// heartbeat renews the lease until the lock is released
+func (l *Lock) heartbeat(ctx context.Context) {
+	defer close(l.done)
+	ticker := time.NewTicker(leaseRenewPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := l.renew(ctx); err != nil {
+				if errors.Is(err, errLockLost) {
+					oktetoLog.Warning("The lock of development environment '%s' was released by another operation", l.name)
+					return
+				}
+				oktetoLog.Infof("could not renew the lock of '%s': %s", l.name, err)
+			}
+		}
+	}
+}
+
+var errLockLost = errors.New("lock lost")
+
+// renew extends the expiration of the lease. Only the lease is patched, so the renewal doesn't conflict
+// with the updates of the pipeline configmap done by the operation holding the lock
+func (l *Lock) renew(ctx context.Context) error {
+	return retry.OnError(retry.DefaultRetry, isLeasePatchRetriable, func() error {
+		cmap, err := configmaps.Get(ctx, TranslatePipelineName(l.name), l.namespace, l.c)
+		if err != nil {
+			return err
+		}
+		current, _ := GetLease(cmap)
+		if current == nil || current.ID != l.lease.ID {
+			return errLockLost
+		}
+		renewed := l.lease
+		now := time.Now().UTC()
+		renewed.RenewedAt = now
+		renewed.ExpiresAt = now.Add(leaseDuration)
+		if err := patchLease(ctx, cmap, &renewed, l.c); err != nil {
+			return err
+		}
+		l.lease = renewed
+		return nil
+	})
+}
+
+// leasePatchOperation is a JSON patch operation over the lease of the pipeline configmap
+type leasePatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value string `json:"value,omitempty"`
+}
+
+// patchLease replaces the lease of the configmap, or removes it if nil. The patch fails if the lease
+// was modified after reading the configmap, but not if any other field was
+func patchLease(ctx context.Context, cmap *apiv1.ConfigMap, lease *Lease, c kubernetes.Interface) error {
+	path := fmt.Sprintf("/data/%s", leaseField)
+	ops := []leasePatchOperation{{Op: "test", Path: path, Value: cmap.Data[leaseField]}}
+	if lease == nil {
+		ops = append(ops, leasePatchOperation{Op: "remove", Path: path})
+	} else {
+		encoded, err := json.Marshal(lease)
+		if err != nil {
+			return err
+		}
+		ops = append(ops, leasePatchOperation{Op: "replace", Path: path, Value: string(encoded)})
+	}
+	payload, err := json.Marshal(ops)
+	if err != nil {
+		return err
+	}
+	_, err = c.CoreV1().ConfigMaps(cmap.Namespace).Patch(ctx, cmap.Name, types.JSONPatchType, payload, metav1.PatchOptions{})
+	return err
+}
+
+// isLeasePatchRetriable returns if the lease must be read again: the API server rejects a patch whose test fails as invalid
+func isLeasePatchRetriable(err error) bool {
+	return k8sErrors.IsConflict(err) || k8sErrors.IsInvalid(err)
+}
+
+// GetLease returns the lease stored in the pipeline configmap, nil if the development environment is not locked
+func GetLease(cmap *apiv1.ConfigMap) (*Lease, error) {
+	if cmap == nil || cmap.Data[leaseField] == "" {
+		return nil, nil
+	}
+	lease := &Lease{}
+	if err := json.Unmarshal([]byte(cmap.Data[leaseField]), lease); err != nil {
+		return nil, fmt.Errorf("could not decode the lease of '%s': %w", cmap.Name, err)
+	}
+	return lease, nil
+}
+
+// setLease updates the lease of the configmap. The update fails with a conflict if the configmap was modified after reading it
+func setLease(ctx context.Context, cmap *apiv1.ConfigMap, lease *Lease, c kubernetes.Interface) error {
+	if cmap.Data == nil {
+		cmap.Data = map[string]string{}
+	}
+	if lease == nil {
+		delete(cmap.Data, leaseField)
+	} else {
+		encoded, err := json.Marshal(lease)
+		if err != nil {
+			return err
+		}
+		cmap.Data[leaseField] = string(encoded)
+	}
+	_, err := c.CoreV1().ConfigMaps(cmap.Namespace).Update(ctx, cmap, metav1.UpdateOptions{})
+	return err
+}
+
+func newLease(operation string) Lease {
+	now := time.Now().UTC()
+	return Lease{
+		ID:         uuid.New().String(),
+		Holder:     getLockHolder(),
+		Operation:  operation,
+		AcquiredAt: now,
+		RenewedAt:  now,
+		ExpiresAt:  now.Add(leaseDuration),
+	}
+}
+
+// getLockHolder returns a human readable identity of the lock holder
+func getLockHolder() string {
+	if actionName := os.Getenv(model.OktetoActionNameEnvVar); actionName != "" {
+		return fmt.Sprintf("pipeline action %s", actionName)
+	}
+	username := "unknown"
+	if u, err := user.Current(); err == nil {
+		username = u.Username
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s@%s", username, hostname)
+}
+
+// translateLockConfigMap returns the pipeline configmap of a development environment that has never been deployed
+func translateLockConfigMap(name, namespace string, lease Lease) *apiv1.ConfigMap {
+	cmap := translateConfigMapSandBox(&CfgData{Name: name, Namespace: namespace})
+	encoded, _ := json.Marshal(lease)
+	cmap.Data[leaseField] = string(encoded)
+	return cmap
+}
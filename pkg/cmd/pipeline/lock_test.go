@@ -0,0 +1,271 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	oktetoErrors "github.com/okteto/okteto/pkg/errors"
+	"github.com/okteto/okteto/pkg/k8s/configmaps"
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8sTesting "k8s.io/client-go/testing"
+)
+
+func newLockedConfigMap(t *testing.T, lease Lease) *apiv1.ConfigMap {
+	encoded, err := json.Marshal(lease)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &apiv1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      TranslatePipelineName("movies"),
+			Namespace: "test",
+			Labels:    map[string]string{model.GitDeployLabel: "true"},
+		},
+		Data: map[string]string{
+			nameField:  "movies",
+			leaseField: string(encoded),
+		},
+	}
+}
+
+func Test_AcquireAndReleaseLock(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewSimpleClientset()
+	opts := LockOptions{Name: "movies", Namespace: "test", Operation: "deploy"}
+
+	lock, err := AcquireLock(ctx, opts, c)
+	assert.NoError(t, err)
+
+	cmap, err := configmaps.Get(ctx, TranslatePipelineName("movies"), "test", c)
+	assert.NoError(t, err)
+	lease, err := GetLease(cmap)
+	assert.NoError(t, err)
+	assert.Equal(t, "deploy", lease.Operation)
+	assert.Equal(t, "true", cmap.Labels[model.GitDeployLabel])
+
+	_, err = AcquireLock(ctx, LockOptions{Name: "movies", Namespace: "test", Operation: "destroy"}, c)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "development environment 'movies' is locked by")
+	}
+
+	lock.Release(ctx)
+	cmap, err = configmaps.Get(ctx, TranslatePipelineName("movies"), "test", c)
+	assert.NoError(t, err)
+	lease, err = GetLease(cmap)
+	assert.NoError(t, err)
+	assert.Nil(t, lease)
+
+	lock, err = AcquireLock(ctx, LockOptions{Name: "movies", Namespace: "test", Operation: "destroy"}, c)
+	assert.NoError(t, err)
+	lock.Release(ctx)
+}
+
+func Test_AcquireLockHeldByOtherOperation(t *testing.T) {
+	now := time.Now().UTC()
+	active := Lease{ID: "other", Holder: "cindy@laptop", Operation: "deploy", AcquiredAt: now, RenewedAt: now, ExpiresAt: now.Add(time.Minute)}
+	expired := Lease{ID: "other", Holder: "cindy@laptop", Operation: "deploy", AcquiredAt: now.Add(-time.Hour), RenewedAt: now.Add(-time.Hour), ExpiresAt: now.Add(-time.Minute)}
+
+	tests := []struct {
+		name        string
+		lease       Lease
+		force       bool
+		expectedErr bool
+	}{
+		{
+			name:        "active lease",
+			lease:       active,
+			expectedErr: true,
+		},
+		{
+			name:  "active lease with force",
+			lease: active,
+			force: true,
+		},
+		{
+			name:  "expired lease",
+			lease: expired,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := fake.NewSimpleClientset(newLockedConfigMap(t, tt.lease))
+
+			lock, err := AcquireLock(ctx, LockOptions{Name: "movies", Namespace: "test", Operation: "destroy", Force: tt.force}, c)
+			if tt.expectedErr {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), "locked by 'cindy@laptop' running 'deploy'")
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "destroy", lock.lease.Operation)
+			lock.Release(ctx)
+		})
+	}
+}
+
+func Test_AcquireLockWaitsForRelease(t *testing.T) {
+	prevRetryPeriod := lockRetryPeriod
+	lockRetryPeriod = 10 * time.Millisecond
+	defer func() {
+		lockRetryPeriod = prevRetryPeriod
+	}()
+
+	ctx := context.Background()
+	c := fake.NewSimpleClientset()
+	held, err := AcquireLock(ctx, LockOptions{Name: "movies", Namespace: "test", Operation: "deploy"}, c)
+	assert.NoError(t, err)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		held.Release(ctx)
+	}()
+
+	lock, err := AcquireLock(ctx, LockOptions{Name: "movies", Namespace: "test", Operation: "destroy", Timeout: 5 * time.Second}, c)
+	assert.NoError(t, err)
+	lock.Release(ctx)
+}
+
+func Test_RenewLock(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewSimpleClientset()
+	lock, err := AcquireLock(ctx, LockOptions{Name: "movies", Namespace: "test", Operation: "deploy"}, c)
+	assert.NoError(t, err)
+	defer lock.Release(ctx)
+
+	previousExpiration := lock.lease.ExpiresAt
+	time.Sleep(time.Millisecond)
+	assert.NoError(t, lock.renew(ctx))
+	assert.True(t, lock.lease.ExpiresAt.After(previousExpiration))
+
+	_, err = AcquireLock(ctx, LockOptions{Name: "movies", Namespace: "test", Operation: "deploy", Force: true}, c)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, lock.renew(ctx), errLockLost)
+}
+
+func Test_AcquireLockToDestroyNotDeployed(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewSimpleClientset()
+
+	lock, err := AcquireLock(ctx, LockOptions{Name: "movies", Namespace: "test", Operation: DestroyOperation}, c)
+	assert.NoError(t, err)
+	assert.Nil(t, lock)
+	lock.Release(ctx)
+
+	_, err = configmaps.Get(ctx, TranslatePipelineName("movies"), "test", c)
+	assert.True(t, oktetoErrors.IsNotFound(err))
+}
+
+func Test_AcquireLockWithActionLock(t *testing.T) {
+	prevRetryPeriod := lockRetryPeriod
+	lockRetryPeriod = 10 * time.Millisecond
+	defer func() {
+		lockRetryPeriod = prevRetryPeriod
+	}()
+
+	ctx := context.Background()
+	cmap := &apiv1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      TranslatePipelineName("movies"),
+			Namespace: "test",
+			Labels:    map[string]string{model.GitDeployLabel: "true"},
+		},
+		Data: map[string]string{nameField: "movies", actionLockField: "backend-action"},
+	}
+	c := fake.NewSimpleClientset(cmap)
+
+	// the action lock of the backend is held even when forcing the unlock
+	_, err := AcquireLock(ctx, LockOptions{Name: "movies", Namespace: "test", Operation: DeployOperation, Force: true}, c)
+	assert.ErrorContains(t, err, "There is a pipeline operation already running")
+	assert.ErrorContains(t, err, "backend-action")
+
+	// waits for the action to release the configmap
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		released := cmap.DeepCopy()
+		delete(released.Data, actionLockField)
+		_, err := c.CoreV1().ConfigMaps("test").Update(ctx, released, metav1.UpdateOptions{})
+		assert.NoError(t, err)
+	}()
+	lock, err := AcquireLock(ctx, LockOptions{Name: "movies", Namespace: "test", Operation: DeployOperation, Timeout: 5 * time.Second}, c)
+	assert.NoError(t, err)
+	assert.NotNil(t, lock)
+	lock.Release(ctx)
+
+	// the action running the deploy isn't locked by its own action lock, and the lock is kept
+	t.Setenv(model.OktetoActionNameEnvVar, "backend-action")
+	_, err = c.CoreV1().ConfigMaps("test").Update(ctx, cmap, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	lock, err = AcquireLock(ctx, LockOptions{Name: "movies", Namespace: "test", Operation: DeployOperation}, c)
+	assert.NoError(t, err)
+	defer lock.Release(ctx)
+	_, err = TranslateConfigMapAndDeploy(ctx, &CfgData{Name: "movies", Namespace: "test", Status: ProgressingStatus}, c)
+	assert.NoError(t, err)
+	current, err := configmaps.Get(ctx, TranslatePipelineName("movies"), "test", c)
+	assert.NoError(t, err)
+	assert.Equal(t, "backend-action", current.Data[actionLockField])
+}
+
+func Test_TranslateConfigMapAndDeployWithActionLock(t *testing.T) {
+	ctx := context.Background()
+	cmap := &apiv1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      TranslatePipelineName("movies"),
+			Namespace: "test",
+		},
+		Data: map[string]string{nameField: "movies", actionLockField: "backend-action"},
+	}
+	c := fake.NewSimpleClientset(cmap)
+
+	_, err := TranslateConfigMapAndDeploy(ctx, &CfgData{Name: "movies", Namespace: "test", Status: ProgressingStatus}, c)
+	assert.EqualError(t, err, "There is a pipeline operation already running")
+}
+
+func Test_RenewLockOnlyPatchesTheLease(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewSimpleClientset()
+	lock, err := AcquireLock(ctx, LockOptions{Name: "movies", Namespace: "test", Operation: DeployOperation}, c)
+	assert.NoError(t, err)
+	defer lock.Release(ctx)
+
+	_, err = TranslateConfigMapAndDeploy(ctx, &CfgData{Name: "movies", Namespace: "test", Status: ProgressingStatus}, c)
+	assert.NoError(t, err)
+	assert.NoError(t, lock.renew(ctx))
+
+	for _, action := range c.Actions() {
+		if action.GetVerb() != "patch" {
+			continue
+		}
+		patch := action.(k8sTesting.PatchAction)
+		assert.Equal(t, types.JSONPatchType, patch.GetPatchType())
+		assert.Contains(t, string(patch.GetPatch()), `"path":"/data/lease"`)
+		assert.NotContains(t, string(patch.GetPatch()), statusField)
+	}
+	cmap, err := configmaps.Get(ctx, TranslatePipelineName("movies"), "test", c)
+	assert.NoError(t, err)
+	assert.Equal(t, ProgressingStatus, cmap.Data[statusField])
+	lease, err := GetLease(cmap)
+	assert.NoError(t, err)
+	assert.Equal(t, lock.lease.ExpiresAt.Unix(), lease.ExpiresAt.Unix())
+}
@@ -0,0 +1,42 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+
+	apiv1 "k8s.io/api/core/v1"
+)
+
+const stepsField = "steps"
+
+// DeployStep represents a deploy command that completed successfully
+type DeployStep struct {
+	Name string `json:"name"`
+	// Hash identifies the definition of the command and the variables used to run it
+	Hash string `json:"hash"`
+}
+
+// GetDeploySteps returns the commands completed by the last deploy, in order
+func GetDeploySteps(cmap *apiv1.ConfigMap) ([]DeployStep, error) {
+	if cmap == nil || cmap.Data[stepsField] == "" {
+		return []DeployStep{}, nil
+	}
+	steps := []DeployStep{}
+	if err := json.Unmarshal([]byte(cmap.Data[stepsField]), &steps); err != nil {
+		return nil, fmt.Errorf("could not decode the deploy steps of '%s': %w", cmap.Name, err)
+	}
+	return steps, nil
+}
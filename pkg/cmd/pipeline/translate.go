@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
@@ -30,9 +31,9 @@ import (
 	oktetoLog "github.com/okteto/okteto/pkg/log"
 	"github.com/okteto/okteto/pkg/model"
 	apiv1 "k8s.io/api/core/v1"
-	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
 )
 
 const (
@@ -46,6 +47,8 @@ const (
 	iconField       = "icon"
 	actionLockField = "actionLock"
 	actionNameField = "actionName"
+	inventoryField  = "inventory"
+	auditField      = "audit"
 
 	actionDefaultName = "cli"
 
@@ -82,30 +85,47 @@ type CfgData struct {
 	Filename   string
 	Manifest   []byte
 	Icon       string
+	// AuditSummary summarizes the kubernetes mutations done by the last deploy
+	AuditSummary *AuditSummary
+	// Inventory is the list of objects created by the deploys
+	Inventory Inventory
+	// History is the list of the latest deploys
+	History History
+	// Steps is the list of commands completed by the last deploy
+	Steps []DeployStep
 }
 
-// TranslateConfigMapAndDeploy translates the app into a configMap
+// AuditSummary represents the summary of the kubernetes mutations done by a deploy
+type AuditSummary struct {
+	Total   int            `json:"total"`
+	Failed  int            `json:"failed"`
+	Actions map[string]int `json:"actions"`
+}
+
+// TranslateConfigMapAndDeploy translates the app into a configMap.
+// The update is retried if the configmap was modified meanwhile, i.e. the lease was renewed
 func TranslateConfigMapAndDeploy(ctx context.Context, data *CfgData, c kubernetes.Interface) (*apiv1.ConfigMap, error) {
-	cmap, err := configmaps.Get(ctx, TranslatePipelineName(data.Name), data.Namespace, c)
-	if err != nil {
-		if !oktetoErrors.IsNotFound(err) {
-			return nil, err
-		}
-		cmap = translateConfigMapSandBox(data)
-		err := configmaps.Create(ctx, cmap, cmap.Namespace, c)
+	var cmap *apiv1.ConfigMap
+	err := retry.OnError(retry.DefaultRetry, isLockRetriable, func() error {
+		var err error
+		cmap, err = configmaps.Get(ctx, TranslatePipelineName(data.Name), data.Namespace, c)
 		if err != nil {
-			if k8sErrors.IsAlreadyExists(err) {
-				return nil, errors.New("There is a pipeline operation already running")
+			if !oktetoErrors.IsNotFound(err) {
+				return err
+			}
+			cmap = translateConfigMapSandBox(data)
+			if err := configmaps.Create(ctx, cmap, cmap.Namespace, c); err != nil {
+				return err
 			}
-			return nil, err
 		}
-	}
 
-	if err := updateCmap(cmap, data); err != nil {
-		return nil, err
-	}
-	if err := configmaps.Deploy(ctx, cmap, cmap.Namespace, c); err != nil {
-		if k8sErrors.IsConflict(err) {
+		if err := updateCmap(cmap, data); err != nil {
+			return err
+		}
+		return configmaps.Deploy(ctx, cmap, cmap.Namespace, c)
+	})
+	if err != nil {
+		if isLockRetriable(err) {
 			return nil, errors.New("There is a pipeline operation already running")
 		}
 		return nil, err
@@ -119,16 +139,19 @@ func SetOutput(cfg *apiv1.ConfigMap, output string) *apiv1.ConfigMap {
 	return cfg
 }
 
-// UpdateConfigMap updates the configmaps fields
+// UpdateConfigMap updates the configmaps fields. The update is retried if the lease of the configmap was renewed meanwhile
 func UpdateConfigMap(ctx context.Context, cmap *apiv1.ConfigMap, data *CfgData, c kubernetes.Interface) error {
-	cmap, err := configmaps.Get(ctx, cmap.Name, cmap.Namespace, c)
-	if err != nil {
-		return err
-	}
-	if err := updateCmap(cmap, data); err != nil {
-		return err
-	}
-	return configmaps.Deploy(ctx, cmap, cmap.Namespace, c)
+	name, namespace := cmap.Name, cmap.Namespace
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cmap, err := configmaps.Get(ctx, name, namespace, c)
+		if err != nil {
+			return err
+		}
+		if err := updateCmap(cmap, data); err != nil {
+			return err
+		}
+		return configmaps.Deploy(ctx, cmap, cmap.Namespace, c)
+	})
 }
 
 // TranslatePipelineName translate the name into the pipeline name
@@ -194,14 +217,31 @@ func translateConfigMapSandBox(data *CfgData) *apiv1.ConfigMap {
 	return cmap
 }
 
-func updateCmap(cmap *apiv1.ConfigMap, data *CfgData) error {
+// getActionName returns the name of the pipeline action running the command
+func getActionName() string {
 	actionName := os.Getenv(model.OktetoActionNameEnvVar)
 	if actionName == "" {
 		actionName = actionDefaultName
 	}
-	if val, ok := cmap.Data[actionLockField]; ok && val != actionName {
+	return actionName
+}
+
+// getActionLock returns the pipeline action of the Okteto backend holding the configmap, or empty if it's not held by another action
+func getActionLock(cmap *apiv1.ConfigMap) string {
+	if val, ok := cmap.Data[actionLockField]; ok && val != "" && val != getActionName() {
+		return val
+	}
+	return ""
+}
+
+func updateCmap(cmap *apiv1.ConfigMap, data *CfgData) error {
+	actionName := getActionName()
+	if getActionLock(cmap) != "" {
 		return errors.New("There is a pipeline operation already running")
 	}
+	if cmap.ObjectMeta.Labels == nil {
+		cmap.ObjectMeta.Labels = map[string]string{}
+	}
 	cmap.ObjectMeta.Labels[model.GitDeployLabel] = "true"
 	cmap.Data[nameField] = data.Name
 	cmap.Data[statusField] = data.Status
@@ -220,6 +260,38 @@ func updateCmap(cmap *apiv1.ConfigMap, data *CfgData) error {
 		cmap.Data[branchField] = data.Branch
 	}
 
+	if data.AuditSummary != nil {
+		summary, err := json.Marshal(data.AuditSummary)
+		if err != nil {
+			return fmt.Errorf("could not encode audit summary: %w", err)
+		}
+		cmap.Data[auditField] = string(summary)
+	}
+
+	if data.Inventory != nil {
+		inventory, err := json.Marshal(data.Inventory)
+		if err != nil {
+			return fmt.Errorf("could not encode inventory: %w", err)
+		}
+		cmap.Data[inventoryField] = string(inventory)
+	}
+
+	if data.History != nil {
+		history, err := json.Marshal(data.History)
+		if err != nil {
+			return fmt.Errorf("could not encode deploy history: %w", err)
+		}
+		cmap.Data[historyField] = string(history)
+	}
+
+	if data.Steps != nil {
+		steps, err := json.Marshal(data.Steps)
+		if err != nil {
+			return fmt.Errorf("could not encode deploy steps: %w", err)
+		}
+		cmap.Data[stepsField] = string(steps)
+	}
+
 	output := oktetoLog.GetOutputBuffer()
 	outputData := translateOutput(output)
 	cmap.Data[outputField] = base64.StdEncoding.EncodeToString([]byte(outputData))
@@ -15,14 +15,19 @@ package pipeline
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/okteto/okteto/pkg/model"
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
 	apiv1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes/fake"
+	k8sTesting "k8s.io/client-go/testing"
 )
 
 func Test_translateConfigMap(t *testing.T) {
@@ -76,6 +81,32 @@ func Test_translateConfigMap(t *testing.T) {
 	}
 }
 
+func Test_TranslateConfigMapAndDeployRetriesOnConflict(t *testing.T) {
+	ctx := context.Background()
+	cmap := &apiv1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      TranslatePipelineName("movies"),
+			Namespace: "test",
+			Labels:    map[string]string{model.GitDeployLabel: "true"},
+		},
+		Data: map[string]string{statusField: DeployedStatus},
+	}
+	c := fake.NewSimpleClientset(cmap)
+	conflicts := 1
+	c.PrependReactor("update", "configmaps", func(action k8sTesting.Action) (bool, runtime.Object, error) {
+		if conflicts == 0 {
+			return false, nil, nil
+		}
+		conflicts--
+		return true, nil, k8sErrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, cmap.Name, errors.New("the lease was renewed"))
+	})
+
+	cfg, err := TranslateConfigMapAndDeploy(ctx, &CfgData{Name: "movies", Namespace: "test", Status: ProgressingStatus}, c)
+	assert.NoError(t, err)
+	assert.Equal(t, ProgressingStatus, cfg.Data[statusField])
+	assert.Equal(t, 0, conflicts)
+}
+
 func Test_AddDevAnnotations(t *testing.T) {
 	ctx := context.Background()
 	d := &appsv1.Deployment{
@@ -0,0 +1,181 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apps
+
+import (
+	"context"
+	"fmt"
+
+	oktetoErrors "github.com/okteto/okteto/pkg/errors"
+	"github.com/okteto/okteto/pkg/k8s/cronjobs"
+	"github.com/okteto/okteto/pkg/k8s/pods"
+	oktetoLog "github.com/okteto/okteto/pkg/log"
+	"github.com/okteto/okteto/pkg/model"
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/pointer"
+)
+
+// CronJobApp is a cronjob in development mode. The original cronjob is suspended and the development container
+// runs in a job created from the job template of the cronjob
+type CronJobApp struct {
+	kind string
+	cj   *batchv1.CronJob
+}
+
+func NewCronJobApp(cj *batchv1.CronJob) *CronJobApp {
+	return &CronJobApp{kind: model.CronJob, cj: cj}
+}
+
+func (i *CronJobApp) Kind() string {
+	return i.kind
+}
+
+func (i *CronJobApp) ObjectMeta() metav1.ObjectMeta {
+	if i.cj.ObjectMeta.Annotations == nil {
+		i.cj.ObjectMeta.Annotations = map[string]string{}
+	}
+	if i.cj.ObjectMeta.Labels == nil {
+		i.cj.ObjectMeta.Labels = map[string]string{}
+	}
+	return i.cj.ObjectMeta
+}
+
+// Replicas returns 0 if the cronjob is suspended
+func (i *CronJobApp) Replicas() int32 {
+	if i.cj.Spec.Suspend != nil && *i.cj.Spec.Suspend {
+		return 0
+	}
+	return 1
+}
+
+// SetReplicas suspends the cronjob if n is 0
+func (i *CronJobApp) SetReplicas(n int32) {
+	i.cj.Spec.Suspend = pointer.BoolPtr(n == 0)
+}
+
+func (i *CronJobApp) TemplateObjectMeta() metav1.ObjectMeta {
+	template := &i.cj.Spec.JobTemplate.Spec.Template
+	if template.ObjectMeta.Annotations == nil {
+		template.ObjectMeta.Annotations = map[string]string{}
+	}
+	if template.ObjectMeta.Labels == nil {
+		template.ObjectMeta.Labels = map[string]string{}
+	}
+	return template.ObjectMeta
+}
+
+func (i *CronJobApp) PodSpec() *apiv1.PodSpec {
+	return &i.cj.Spec.JobTemplate.Spec.Template.Spec
+}
+
+// DevClone returns a job created from the job template of the cronjob
+func (i *CronJobApp) DevClone() App {
+	return newDevJob(i.cj.ObjectMeta, i.cj.UID, &i.cj.Spec.JobTemplate.Spec)
+}
+
+func (*CronJobApp) CheckConditionErrors(_ *model.Dev) error {
+	return nil
+}
+
+func (i *CronJobApp) GetRunningPod(ctx context.Context, c kubernetes.Interface) (*apiv1.Pod, error) {
+	for _, ref := range i.cj.Status.Active {
+		pod, err := pods.GetPodByOwner(ctx, i.cj.Namespace, ref.UID, c)
+		if err == nil {
+			return pod, nil
+		}
+		if !oktetoErrors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	return nil, oktetoErrors.ErrNotFound
+}
+
+func (*CronJobApp) RestoreOriginal() error {
+	return nil
+}
+
+func (i *CronJobApp) Refresh(ctx context.Context, c kubernetes.Interface) error {
+	cj, err := cronjobs.Get(ctx, i.cj.Name, i.cj.Namespace, c)
+	if err == nil {
+		i.cj = cj
+	}
+	return err
+}
+
+func (i *CronJobApp) Watch(ctx context.Context, result chan error, c kubernetes.Interface) {
+	optsWatch := metav1.ListOptions{
+		Watch:         true,
+		FieldSelector: fmt.Sprintf("metadata.name=%s", i.cj.Name),
+	}
+
+	watcher, err := c.BatchV1().CronJobs(i.cj.Namespace).Watch(ctx, optsWatch)
+	if err != nil {
+		result <- err
+		return
+	}
+
+	for {
+		select {
+		case e := <-watcher.ResultChan():
+			oktetoLog.Debugf("Received cronjob '%s' event: %s", i.cj.Name, e)
+			if e.Object == nil {
+				oktetoLog.Debugf("Recreating cronjob '%s' watcher", i.cj.Name)
+				watcher, err = c.BatchV1().CronJobs(i.cj.Namespace).Watch(ctx, optsWatch)
+				if err != nil {
+					result <- err
+					return
+				}
+				continue
+			}
+			switch e.Type {
+			case watch.Deleted:
+				result <- oktetoErrors.ErrDeleteToApp
+				return
+			case watch.Modified:
+				cj, ok := e.Object.(*batchv1.CronJob)
+				if !ok {
+					oktetoLog.Debugf("Failed to parse cronjob event: %s", e)
+					continue
+				}
+				if cj.Generation != i.cj.Generation {
+					result <- oktetoErrors.ErrApplyToApp
+					return
+				}
+			}
+		case err := <-ctx.Done():
+			oktetoLog.Debugf("call to up.applyToApp cancelled: %v", err)
+			return
+		}
+	}
+}
+
+func (i *CronJobApp) Deploy(ctx context.Context, c kubernetes.Interface) error {
+	cj, err := cronjobs.Update(ctx, i.cj, c)
+	if err == nil {
+		i.cj = cj
+	}
+	return err
+}
+
+func (i *CronJobApp) PatchAnnotations(ctx context.Context, c kubernetes.Interface) error {
+	return cronjobs.PatchAnnotations(ctx, i.cj, c)
+}
+
+func (i *CronJobApp) Destroy(ctx context.Context, c kubernetes.Interface) error {
+	return cronjobs.Destroy(ctx, i.cj.Name, i.cj.Namespace, c)
+}
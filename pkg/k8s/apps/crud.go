@@ -19,15 +19,23 @@ import (
 	"time"
 
 	oktetoErrors "github.com/okteto/okteto/pkg/errors"
+	"github.com/okteto/okteto/pkg/k8s/cronjobs"
+	"github.com/okteto/okteto/pkg/k8s/daemonsets"
 	"github.com/okteto/okteto/pkg/k8s/deployments"
+	"github.com/okteto/okteto/pkg/k8s/jobs"
 	"github.com/okteto/okteto/pkg/k8s/statefulsets"
 	oktetoLog "github.com/okteto/okteto/pkg/log"
 	"github.com/okteto/okteto/pkg/model"
 	apiv1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/kubernetes"
 )
 
 func Get(ctx context.Context, dev *model.Dev, namespace string, c kubernetes.Interface) (App, error) {
+	if dev.Workload != nil {
+		return getCustomApp(ctx, dev, namespace)
+	}
+
 	d, err := deployments.GetByDev(ctx, dev, namespace, c)
 
 	if err == nil {
@@ -39,13 +47,42 @@ func Get(ctx context.Context, dev *model.Dev, namespace string, c kubernetes.Int
 	}
 
 	sfs, err := statefulsets.GetByDev(ctx, dev, namespace, c)
-	if err != nil {
-		if oktetoErrors.IsNotFound(err) {
-			return nil, fmt.Errorf("the application '%s' referred by your okteto manifest doesn't exist", dev.Name)
-		}
+	if err == nil {
+		return &StatefulSetApp{sfs: sfs}, nil
+	}
+	if !oktetoErrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	ds, err := daemonsets.GetByDev(ctx, dev, namespace, c)
+	if err == nil {
+		return getDaemonSetApp(ctx, ds, c)
+	}
+	if !isNotFoundOrForbidden(err) {
 		return nil, err
 	}
-	return &StatefulSetApp{sfs: sfs}, nil
+
+	job, err := jobs.GetByDev(ctx, dev, namespace, c)
+	if err == nil {
+		return NewJobApp(job), nil
+	}
+	if !isNotFoundOrForbidden(err) {
+		return nil, err
+	}
+
+	cj, err := cronjobs.GetByDev(ctx, dev, namespace, c)
+	if err == nil {
+		return NewCronJobApp(cj), nil
+	}
+	if isNotFoundOrForbidden(err) {
+		return nil, fmt.Errorf("the application '%s' referred by your okteto manifest doesn't exist", dev.Name)
+	}
+	return nil, err
+}
+
+// isNotFoundOrForbidden returns if a kind can be skipped when looking for an app: users might not have permissions on all kinds
+func isNotFoundOrForbidden(err error) bool {
+	return oktetoErrors.IsNotFound(err) || k8sErrors.IsForbidden(err)
 }
 
 // IsDevModeOn returns if a statefulset is in devmode
@@ -0,0 +1,452 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	oktetoErrors "github.com/okteto/okteto/pkg/errors"
+	oktetoLog "github.com/okteto/okteto/pkg/log"
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/okteto/okteto/pkg/okteto"
+	apiv1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	defaultWorkloadTemplatePath = "spec.template"
+	defaultWorkloadReplicasPath = "spec.replicas"
+)
+
+// getDynamicClient returns the dynamic client used by custom apps
+var getDynamicClient = func() (dynamic.Interface, error) {
+	c, _, err := okteto.GetDynamicClient()
+	return c, err
+}
+
+// CustomApp is a custom resource with an embedded pod template, e.g. an Argo Rollout.
+// The metadata, pod template and replicas are decoded from the unstructured object and written back on Deploy
+type CustomApp struct {
+	kind         string
+	gvr          schema.GroupVersionResource
+	templatePath []string
+	replicasPath []string
+	obj          *unstructured.Unstructured
+	meta         metav1.ObjectMeta
+	template     apiv1.PodTemplateSpec
+	replicas     int32
+	// hasReplicas is false if the custom resource doesn't define the replicas field
+	hasReplicas bool
+	client      dynamic.Interface
+}
+
+// workloadRule is the location of the pod template and replicas of a custom resource
+type workloadRule struct {
+	gvr          schema.GroupVersionResource
+	templatePath []string
+	replicasPath []string
+}
+
+func newWorkloadRule(w *model.PodTemplateRule) (*workloadRule, error) {
+	gv, err := schema.ParseGroupVersion(w.APIVersion)
+	if err != nil {
+		return nil, fmt.Errorf("'workload.apiVersion' is not valid: %w", err)
+	}
+	gvr := gv.WithResource(w.Resource)
+	if w.Resource == "" {
+		gvr, _ = meta.UnsafeGuessKindToResource(gv.WithKind(w.Kind))
+	}
+	templatePath := w.Path
+	if templatePath == "" {
+		templatePath = defaultWorkloadTemplatePath
+	}
+	replicasPath := w.ReplicasPath
+	if replicasPath == "" {
+		replicasPath = defaultWorkloadReplicasPath
+	}
+	return &workloadRule{
+		gvr:          gvr,
+		templatePath: splitWorkloadPath(templatePath),
+		replicasPath: splitWorkloadPath(replicasPath),
+	}, nil
+}
+
+// NewCustomApp returns the app of a custom resource
+func NewCustomApp(obj *unstructured.Unstructured, w *model.PodTemplateRule, c dynamic.Interface) (*CustomApp, error) {
+	rule, err := newWorkloadRule(w)
+	if err != nil {
+		return nil, err
+	}
+	return newCustomApp(obj, rule, c)
+}
+
+func newCustomApp(obj *unstructured.Unstructured, rule *workloadRule, c dynamic.Interface) (*CustomApp, error) {
+	app := &CustomApp{
+		kind:         obj.GetKind(),
+		gvr:          rule.gvr,
+		templatePath: rule.templatePath,
+		replicasPath: rule.replicasPath,
+		client:       c,
+	}
+	if err := app.decode(obj); err != nil {
+		return nil, err
+	}
+	return app, nil
+}
+
+// getCustomApp returns the custom resource of a development container, by name or by labels
+func getCustomApp(ctx context.Context, dev *model.Dev, namespace string) (*CustomApp, error) {
+	rule, err := newWorkloadRule(dev.Workload)
+	if err != nil {
+		return nil, err
+	}
+	c, err := getDynamicClient()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(dev.Selector) == 0 {
+		obj, err := c.Resource(rule.gvr).Namespace(namespace).Get(ctx, dev.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return newCustomApp(obj, rule, c)
+	}
+
+	list, err := c.Resource(rule.gvr).Namespace(namespace).List(ctx, metav1.ListOptions{LabelSelector: dev.LabelsSelector()})
+	if err != nil {
+		return nil, err
+	}
+	valid := []*unstructured.Unstructured{}
+	for i := range list.Items {
+		if list.Items[i].GetLabels()[model.DevCloneLabel] == "" {
+			valid = append(valid, &list.Items[i])
+		}
+	}
+	if len(valid) == 0 {
+		return nil, oktetoErrors.ErrNotFound
+	}
+	if len(valid) > 1 {
+		return nil, fmt.Errorf("found '%d' %s for labels '%s' instead of 1", len(valid), rule.gvr.Resource, dev.LabelsSelector())
+	}
+	return newCustomApp(valid[0], rule, c)
+}
+
+func (i *CustomApp) Kind() string {
+	return i.kind
+}
+
+func (i *CustomApp) ObjectMeta() metav1.ObjectMeta {
+	if i.meta.Annotations == nil {
+		i.meta.Annotations = map[string]string{}
+	}
+	if i.meta.Labels == nil {
+		i.meta.Labels = map[string]string{}
+	}
+	return i.meta
+}
+
+func (i *CustomApp) Replicas() int32 {
+	return i.replicas
+}
+
+func (i *CustomApp) SetReplicas(n int32) {
+	i.replicas = n
+}
+
+func (i *CustomApp) TemplateObjectMeta() metav1.ObjectMeta {
+	if i.template.ObjectMeta.Annotations == nil {
+		i.template.ObjectMeta.Annotations = map[string]string{}
+	}
+	if i.template.ObjectMeta.Labels == nil {
+		i.template.ObjectMeta.Labels = map[string]string{}
+	}
+	return i.template.ObjectMeta
+}
+
+func (i *CustomApp) PodSpec() *apiv1.PodSpec {
+	return &i.template.Spec
+}
+
+// DevClone returns a copy of the custom resource, the controller of the custom resource creates the development container
+func (i *CustomApp) DevClone() App {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	obj.SetAPIVersion(i.obj.GetAPIVersion())
+	obj.SetKind(i.obj.GetKind())
+	if spec, ok := i.obj.Object["spec"]; ok {
+		obj.Object["spec"] = runtime.DeepCopyJSONValue(spec)
+	}
+
+	clone := &CustomApp{
+		kind:         i.kind,
+		gvr:          i.gvr,
+		templatePath: i.templatePath,
+		replicasPath: i.replicasPath,
+		obj:          obj,
+		meta: metav1.ObjectMeta{
+			Name:        model.DevCloneName(i.meta.Name),
+			Namespace:   i.meta.Namespace,
+			Labels:      map[string]string{},
+			Annotations: map[string]string{},
+		},
+		template:    *i.template.DeepCopy(),
+		replicas:    i.replicas,
+		hasReplicas: i.hasReplicas,
+		client:      i.client,
+	}
+	clone.meta.Labels[model.DevCloneLabel] = string(i.meta.UID)
+	for k, v := range i.meta.Labels {
+		clone.meta.Labels[k] = v
+	}
+	for k, v := range i.meta.Annotations {
+		clone.meta.Annotations[k] = v
+	}
+	return clone
+}
+
+func (*CustomApp) CheckConditionErrors(_ *model.Dev) error {
+	return nil
+}
+
+// GetRunningPod returns a pod of the custom resource. Some controllers, like Argo Rollouts, own their pods through replicasets
+func (i *CustomApp) GetRunningPod(ctx context.Context, c kubernetes.Interface) (*apiv1.Pod, error) {
+	selector := metav1.FormatLabelSelector(&metav1.LabelSelector{MatchLabels: i.template.Labels})
+	podList, err := c.CoreV1().Pods(i.meta.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	for j := range podList.Items {
+		pod := &podList.Items[j]
+		if pod.DeletionTimestamp != nil || pod.Status.Phase == apiv1.PodFailed || pod.Status.Phase == apiv1.PodSucceeded {
+			continue
+		}
+		isOwned, err := i.isOwnerOf(ctx, pod.OwnerReferences, c)
+		if err != nil {
+			return nil, err
+		}
+		if isOwned {
+			return pod, nil
+		}
+	}
+	return nil, oktetoErrors.ErrNotFound
+}
+
+func (i *CustomApp) isOwnerOf(ctx context.Context, refs []metav1.OwnerReference, c kubernetes.Interface) (bool, error) {
+	for _, ref := range refs {
+		if ref.UID == i.meta.UID {
+			return true, nil
+		}
+		if ref.Kind != "ReplicaSet" {
+			continue
+		}
+		rs, err := c.AppsV1().ReplicaSets(i.meta.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			if k8sErrors.IsNotFound(err) {
+				continue
+			}
+			return false, err
+		}
+		for _, rsRef := range rs.OwnerReferences {
+			if rsRef.UID == i.meta.UID {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (*CustomApp) RestoreOriginal() error {
+	return nil
+}
+
+func (i *CustomApp) Refresh(ctx context.Context, _ kubernetes.Interface) error {
+	obj, err := i.client.Resource(i.gvr).Namespace(i.meta.Namespace).Get(ctx, i.meta.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	return i.decode(obj)
+}
+
+func (i *CustomApp) Watch(ctx context.Context, result chan error, _ kubernetes.Interface) {
+	optsWatch := metav1.ListOptions{
+		Watch:         true,
+		FieldSelector: fmt.Sprintf("metadata.name=%s", i.meta.Name),
+	}
+
+	watcher, err := i.client.Resource(i.gvr).Namespace(i.meta.Namespace).Watch(ctx, optsWatch)
+	if err != nil {
+		result <- err
+		return
+	}
+
+	for {
+		select {
+		case e := <-watcher.ResultChan():
+			oktetoLog.Debugf("Received %s '%s' event: %s", i.kind, i.meta.Name, e)
+			if e.Object == nil {
+				oktetoLog.Debugf("Recreating %s '%s' watcher", i.kind, i.meta.Name)
+				watcher, err = i.client.Resource(i.gvr).Namespace(i.meta.Namespace).Watch(ctx, optsWatch)
+				if err != nil {
+					result <- err
+					return
+				}
+				continue
+			}
+			switch e.Type {
+			case watch.Deleted:
+				result <- oktetoErrors.ErrDeleteToApp
+				return
+			case watch.Modified:
+				obj, ok := e.Object.(*unstructured.Unstructured)
+				if !ok {
+					oktetoLog.Debugf("Failed to parse %s event: %s", i.kind, e)
+					continue
+				}
+				if obj.GetGeneration() != i.meta.Generation {
+					result <- oktetoErrors.ErrApplyToApp
+					return
+				}
+			}
+		case err := <-ctx.Done():
+			oktetoLog.Debugf("call to up.applyToApp cancelled: %v", err)
+			return
+		}
+	}
+}
+
+// Deploy creates or updates the custom resource with the metadata, pod template and replicas of the app
+func (i *CustomApp) Deploy(ctx context.Context, _ kubernetes.Interface) error {
+	obj, err := i.encode()
+	if err != nil {
+		return err
+	}
+	resource := i.client.Resource(i.gvr).Namespace(i.meta.Namespace)
+	current, err := resource.Get(ctx, i.meta.Name, metav1.GetOptions{})
+	if err != nil {
+		if !k8sErrors.IsNotFound(err) {
+			return fmt.Errorf("error getting %s '%s': %w", i.kind, i.meta.Name, err)
+		}
+		obj.SetResourceVersion("")
+		obj, err = resource.Create(ctx, obj, metav1.CreateOptions{})
+	} else {
+		obj.SetResourceVersion(current.GetResourceVersion())
+		obj, err = resource.Update(ctx, obj, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return err
+	}
+	return i.decode(obj)
+}
+
+func (i *CustomApp) PatchAnnotations(ctx context.Context, _ kubernetes.Interface) error {
+	payload := []map[string]interface{}{
+		{
+			"op":    "replace",
+			"path":  "/metadata/annotations",
+			"value": i.meta.Annotations,
+		},
+	}
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = i.client.Resource(i.gvr).Namespace(i.meta.Namespace).Patch(ctx, i.meta.Name, types.JSONPatchType, payloadBytes, metav1.PatchOptions{})
+	return err
+}
+
+func (i *CustomApp) Destroy(ctx context.Context, _ kubernetes.Interface) error {
+	oktetoLog.Infof("deleting %s '%s'", i.kind, i.meta.Name)
+	err := i.client.Resource(i.gvr).Namespace(i.meta.Namespace).Delete(ctx, i.meta.Name, metav1.DeleteOptions{})
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return fmt.Errorf("error deleting %s '%s': %w", i.kind, i.meta.Name, err)
+	}
+	return nil
+}
+
+// decode loads the metadata, pod template and replicas of an unstructured object
+func (i *CustomApp) decode(obj *unstructured.Unstructured) error {
+	metadata, _, err := unstructured.NestedMap(obj.Object, "metadata")
+	if err != nil {
+		return fmt.Errorf("%s '%s' has an invalid metadata: %w", obj.GetKind(), obj.GetName(), err)
+	}
+	m := metav1.ObjectMeta{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(metadata, &m); err != nil {
+		return fmt.Errorf("%s '%s' has an invalid metadata: %w", obj.GetKind(), obj.GetName(), err)
+	}
+
+	template, found, err := unstructured.NestedMap(obj.Object, i.templatePath...)
+	if err != nil || !found {
+		return fmt.Errorf("%s '%s' has no pod template in '%s'", obj.GetKind(), obj.GetName(), strings.Join(i.templatePath, "."))
+	}
+	t := apiv1.PodTemplateSpec{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(template, &t); err != nil {
+		return fmt.Errorf("%s '%s' has an invalid pod template: %w", obj.GetKind(), obj.GetName(), err)
+	}
+
+	replicas, found, err := unstructured.NestedInt64(obj.Object, i.replicasPath...)
+	if err != nil {
+		return fmt.Errorf("%s '%s' has invalid replicas in '%s': %w", obj.GetKind(), obj.GetName(), strings.Join(i.replicasPath, "."), err)
+	}
+	if !found {
+		replicas = 1
+	}
+
+	i.obj = obj
+	i.meta = m
+	i.template = t
+	i.replicas = int32(replicas)
+	i.hasReplicas = found
+	return nil
+}
+
+// encode returns the unstructured object with the metadata, pod template and replicas of the app
+func (i *CustomApp) encode() (*unstructured.Unstructured, error) {
+	obj := i.obj.DeepCopy()
+	obj.SetName(i.meta.Name)
+	obj.SetNamespace(i.meta.Namespace)
+	obj.SetLabels(i.meta.Labels)
+	obj.SetAnnotations(i.meta.Annotations)
+	unstructured.RemoveNestedField(obj.Object, "status")
+
+	template, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&i.template)
+	if err != nil {
+		return nil, err
+	}
+	if err := unstructured.SetNestedMap(obj.Object, template, i.templatePath...); err != nil {
+		return nil, err
+	}
+	if !i.hasReplicas && i.replicas == 1 {
+		return obj, nil
+	}
+	if err := unstructured.SetNestedField(obj.Object, int64(i.replicas), i.replicasPath...); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func splitWorkloadPath(path string) []string {
+	return strings.Split(strings.Trim(path, "."), ".")
+}
@@ -0,0 +1,179 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apps
+
+import (
+	"context"
+	"testing"
+
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicFake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var rolloutGVR = schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "rollouts"}
+
+func newFakeRolloutClient(objs ...runtime.Object) dynamic.Interface {
+	return dynamicFake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		rolloutGVR: "RolloutList",
+	}, objs...)
+}
+
+func newRollout(name string, replicas int64) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "argoproj.io/v1alpha1",
+			"kind":       "Rollout",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "test",
+				"uid":       "rollout-uid",
+				"labels":    map[string]interface{}{"app": name},
+			},
+			"spec": map[string]interface{}{
+				"replicas": replicas,
+				"strategy": map[string]interface{}{
+					"canary": map[string]interface{}{},
+				},
+				"template": map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"labels": map[string]interface{}{"app": name},
+					},
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{"name": "api", "image": "api:1.0"},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestGetCustomApp(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeRolloutClient(newRollout("api", 3))
+	originalGetDynamicClient := getDynamicClient
+	defer func() { getDynamicClient = originalGetDynamicClient }()
+	getDynamicClient = func() (dynamic.Interface, error) { return client, nil }
+
+	dev := &model.Dev{
+		Name:      "api",
+		Namespace: "test",
+		Workload:  &model.PodTemplateRule{APIVersion: "argoproj.io/v1alpha1", Kind: "Rollout"},
+	}
+	app, err := Get(ctx, dev, "test", fake.NewSimpleClientset())
+	assert.NoError(t, err)
+	assert.Equal(t, "Rollout", app.Kind())
+	assert.Equal(t, int32(3), app.Replicas())
+	assert.Equal(t, "api:1.0", app.PodSpec().Containers[0].Image)
+
+	dev.Selector = model.Selector{"app": "api"}
+	app, err = Get(ctx, dev, "test", fake.NewSimpleClientset())
+	assert.NoError(t, err)
+	assert.Equal(t, "api", app.ObjectMeta().Name)
+
+	dev.Selector = nil
+	dev.Workload.Path = "spec.podTemplate"
+	_, err = Get(ctx, dev, "test", fake.NewSimpleClientset())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "has no pod template in 'spec.podTemplate'")
+}
+
+func TestCustomAppDevMode(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeRolloutClient(newRollout("api", 3))
+	rule := &model.PodTemplateRule{APIVersion: "argoproj.io/v1alpha1", Kind: "Rollout"}
+	obj, err := client.Resource(rolloutGVR).Namespace("test").Get(ctx, "api", metav1.GetOptions{})
+	assert.NoError(t, err)
+	app, err := NewCustomApp(obj, rule, client)
+	assert.NoError(t, err)
+
+	dev := &model.Dev{Name: "api", Namespace: "test", Metadata: &model.Metadata{}}
+	tr := &Translation{MainDev: dev, Dev: dev, App: app, Rules: []*model.TranslationRule{{Container: "api", Image: "okteto/dev"}}}
+	assert.NoError(t, tr.translate())
+	assert.NoError(t, tr.DevApp.Deploy(ctx, nil))
+	assert.NoError(t, tr.App.Deploy(ctx, nil))
+
+	original, err := client.Resource(rolloutGVR).Namespace("test").Get(ctx, "api", metav1.GetOptions{})
+	assert.NoError(t, err)
+	replicas, _, _ := unstructured.NestedInt64(original.Object, "spec", "replicas")
+	assert.Equal(t, int64(0), replicas)
+	assert.Equal(t, "true", original.GetLabels()[model.DevLabel])
+	assert.Equal(t, "3", original.GetAnnotations()[model.AppReplicasAnnotation])
+
+	clone, err := client.Resource(rolloutGVR).Namespace("test").Get(ctx, model.DevCloneName("api"), metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "rollout-uid", clone.GetLabels()[model.DevCloneLabel])
+	replicas, _, _ = unstructured.NestedInt64(clone.Object, "spec", "replicas")
+	assert.Equal(t, int64(1), replicas)
+	_, found, _ := unstructured.NestedMap(clone.Object, "spec", "strategy", "canary")
+	assert.True(t, found)
+	containers, _, _ := unstructured.NestedSlice(clone.Object, "spec", "template", "spec", "containers")
+	assert.Equal(t, "okteto/dev", containers[0].(map[string]interface{})["image"])
+
+	// okteto down
+	assert.NoError(t, tr.App.Refresh(ctx, nil))
+	assert.NoError(t, tr.DevModeOff())
+	assert.NoError(t, tr.App.Deploy(ctx, nil))
+	assert.NoError(t, tr.App.DevClone().Destroy(ctx, nil))
+
+	original, err = client.Resource(rolloutGVR).Namespace("test").Get(ctx, "api", metav1.GetOptions{})
+	assert.NoError(t, err)
+	replicas, _, _ = unstructured.NestedInt64(original.Object, "spec", "replicas")
+	assert.Equal(t, int64(3), replicas)
+	assert.Empty(t, original.GetLabels()[model.DevLabel])
+	_, err = client.Resource(rolloutGVR).Namespace("test").Get(ctx, model.DevCloneName("api"), metav1.GetOptions{})
+	assert.Error(t, err)
+}
+
+func TestCustomAppGetRunningPod(t *testing.T) {
+	ctx := context.Background()
+	app, err := NewCustomApp(newRollout("api", 1), &model.PodTemplateRule{APIVersion: "argoproj.io/v1alpha1", Kind: "Rollout"}, newFakeRolloutClient())
+	assert.NoError(t, err)
+
+	rs := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "api-123",
+			Namespace:       "test",
+			UID:             "rs-uid",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Rollout", UID: "rollout-uid"}},
+		},
+	}
+	pod := &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "api-123-abcde",
+			Namespace:       "test",
+			Labels:          map[string]string{"app": "api"},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "api-123", UID: "rs-uid"}},
+		},
+		Status: apiv1.PodStatus{Phase: apiv1.PodRunning},
+	}
+
+	_, err = app.GetRunningPod(ctx, fake.NewSimpleClientset(pod))
+	assert.Error(t, err)
+
+	c := fake.NewSimpleClientset(pod, rs)
+	result, err := app.GetRunningPod(ctx, c)
+	assert.NoError(t, err)
+	assert.Equal(t, "api-123-abcde", result.Name)
+}
@@ -0,0 +1,329 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apps
+
+import (
+	"context"
+	"fmt"
+
+	oktetoErrors "github.com/okteto/okteto/pkg/errors"
+	"github.com/okteto/okteto/pkg/k8s/daemonsets"
+	"github.com/okteto/okteto/pkg/k8s/pods"
+	oktetoLog "github.com/okteto/okteto/pkg/log"
+	"github.com/okteto/okteto/pkg/model"
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+// nodeNameField is the field used by the daemonset controller to schedule pods on a given node
+const nodeNameField = "metadata.name"
+
+// DaemonSetApp is a daemonset in development mode. The development container runs on a single node, the node
+// stored in the dev.okteto.com/node annotation, and the original daemonset doesn't run on that node while
+// the development container is active
+type DaemonSetApp struct {
+	kind string
+	ds   *appsv1.DaemonSet
+}
+
+func NewDaemonSetApp(ds *appsv1.DaemonSet) *DaemonSetApp {
+	return &DaemonSetApp{kind: model.DaemonSet, ds: ds}
+}
+
+// getDaemonSetApp returns the app of a daemonset, choosing the node of the development container if it's not in development mode
+func getDaemonSetApp(ctx context.Context, ds *appsv1.DaemonSet, c kubernetes.Interface) (*DaemonSetApp, error) {
+	app := NewDaemonSetApp(ds)
+	if IsDevModeOn(app) && app.node() != "" {
+		return app, nil
+	}
+	pod, err := pods.GetPodByOwner(ctx, ds.Namespace, ds.UID, c)
+	if err != nil {
+		if oktetoErrors.IsNotFound(err) {
+			return nil, oktetoErrors.UserError{
+				E:    fmt.Errorf("daemonset '%s' has no running pods to choose the node of the development container", ds.Name),
+				Hint: "Verify that your daemonset is scheduled on at least one node and try again",
+			}
+		}
+		return nil, err
+	}
+	app.ObjectMeta().Annotations[model.DevNodeAnnotation] = pod.Spec.NodeName
+	return app, nil
+}
+
+func (i *DaemonSetApp) Kind() string {
+	return i.kind
+}
+
+func (i *DaemonSetApp) ObjectMeta() metav1.ObjectMeta {
+	if i.ds.ObjectMeta.Annotations == nil {
+		i.ds.ObjectMeta.Annotations = map[string]string{}
+	}
+	if i.ds.ObjectMeta.Labels == nil {
+		i.ds.ObjectMeta.Labels = map[string]string{}
+	}
+	return i.ds.ObjectMeta
+}
+
+// DaemonSetRolloutWarning is shown when a daemonset enters or leaves development mode
+const DaemonSetRolloutWarning = "'%s' is a DaemonSet: its pods are recreated on every node when it enters or leaves development mode"
+
+// Replicas returns 0 if the daemonset doesn't run on the node of the development container
+func (i *DaemonSetApp) Replicas() int32 {
+	if hasNodeExclusion(&i.ds.Spec.Template.Spec, i.node()) {
+		return 0
+	}
+	return 1
+}
+
+// SetReplicas excludes the node of the development container from the daemonset if n is 0.
+// The exclusion changes the pod template, so every pod of the daemonset is recreated: see DaemonSetRolloutWarning
+func (i *DaemonSetApp) SetReplicas(n int32) {
+	node := i.node()
+	if node == "" {
+		return
+	}
+	removeNodeExclusion(&i.ds.Spec.Template.Spec, node)
+	if n == 0 {
+		addNodeExclusion(&i.ds.Spec.Template.Spec, node)
+	}
+}
+
+func (i *DaemonSetApp) TemplateObjectMeta() metav1.ObjectMeta {
+	if i.ds.Spec.Template.ObjectMeta.Annotations == nil {
+		i.ds.Spec.Template.ObjectMeta.Annotations = map[string]string{}
+	}
+	if i.ds.Spec.Template.ObjectMeta.Labels == nil {
+		i.ds.Spec.Template.ObjectMeta.Labels = map[string]string{}
+	}
+	return i.ds.Spec.Template.ObjectMeta
+}
+
+func (i *DaemonSetApp) PodSpec() *apiv1.PodSpec {
+	return &i.ds.Spec.Template.Spec
+}
+
+// DevClone returns a daemonset that only runs on the node of the development container
+func (i *DaemonSetApp) DevClone() App {
+	clone := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        model.DevCloneName(i.ds.Name),
+			Namespace:   i.ds.Namespace,
+			Labels:      map[string]string{},
+			Annotations: map[string]string{},
+		},
+		Spec: *i.ds.Spec.DeepCopy(),
+	}
+	clone.Labels[model.DevCloneLabel] = string(i.ds.UID)
+	for k, v := range i.ds.Labels {
+		clone.Labels[k] = v
+	}
+	for k, v := range i.ds.Annotations {
+		clone.Annotations[k] = v
+	}
+	if node := i.node(); node != "" {
+		pinToNode(&clone.Spec.Template.Spec, node)
+	}
+	return NewDaemonSetApp(clone)
+}
+
+func (*DaemonSetApp) CheckConditionErrors(_ *model.Dev) error {
+	return nil
+}
+
+func (i *DaemonSetApp) GetRunningPod(ctx context.Context, c kubernetes.Interface) (*apiv1.Pod, error) {
+	if i.ds.Generation != i.ds.Status.ObservedGeneration || i.ds.Status.UpdatedNumberScheduled != i.ds.Status.DesiredNumberScheduled {
+		return nil, oktetoErrors.ErrNotFound
+	}
+	return pods.GetPodByOwner(ctx, i.ds.Namespace, i.ds.UID, c)
+}
+
+func (*DaemonSetApp) RestoreOriginal() error {
+	return nil
+}
+
+func (i *DaemonSetApp) Refresh(ctx context.Context, c kubernetes.Interface) error {
+	ds, err := daemonsets.Get(ctx, i.ds.Name, i.ds.Namespace, c)
+	if err == nil {
+		i.ds = ds
+	}
+	return err
+}
+
+func (i *DaemonSetApp) Watch(ctx context.Context, result chan error, c kubernetes.Interface) {
+	optsWatch := metav1.ListOptions{
+		Watch:         true,
+		FieldSelector: fmt.Sprintf("metadata.name=%s", i.ds.Name),
+	}
+
+	watcher, err := c.AppsV1().DaemonSets(i.ds.Namespace).Watch(ctx, optsWatch)
+	if err != nil {
+		result <- err
+		return
+	}
+
+	for {
+		select {
+		case e := <-watcher.ResultChan():
+			oktetoLog.Debugf("Received daemonset '%s' event: %s", i.ds.Name, e)
+			if e.Object == nil {
+				oktetoLog.Debugf("Recreating daemonset '%s' watcher", i.ds.Name)
+				watcher, err = c.AppsV1().DaemonSets(i.ds.Namespace).Watch(ctx, optsWatch)
+				if err != nil {
+					result <- err
+					return
+				}
+				continue
+			}
+			switch e.Type {
+			case watch.Deleted:
+				result <- oktetoErrors.ErrDeleteToApp
+				return
+			case watch.Modified:
+				ds, ok := e.Object.(*appsv1.DaemonSet)
+				if !ok {
+					oktetoLog.Debugf("Failed to parse daemonset event: %s", e)
+					continue
+				}
+				if ds.Generation != i.ds.Generation {
+					result <- oktetoErrors.ErrApplyToApp
+					return
+				}
+			}
+		case err := <-ctx.Done():
+			oktetoLog.Debugf("call to up.applyToApp cancelled: %v", err)
+			return
+		}
+	}
+}
+
+func (i *DaemonSetApp) Deploy(ctx context.Context, c kubernetes.Interface) error {
+	ds, err := daemonsets.Deploy(ctx, i.ds, c)
+	if err == nil {
+		i.ds = ds
+	}
+	return err
+}
+
+func (i *DaemonSetApp) PatchAnnotations(ctx context.Context, c kubernetes.Interface) error {
+	return daemonsets.PatchAnnotations(ctx, i.ds, c)
+}
+
+func (i *DaemonSetApp) Destroy(ctx context.Context, c kubernetes.Interface) error {
+	return daemonsets.Destroy(ctx, i.ds.Name, i.ds.Namespace, c)
+}
+
+func (i *DaemonSetApp) node() string {
+	return i.ds.Annotations[model.DevNodeAnnotation]
+}
+
+// pinToNode makes the pods of a pod spec only run on the given node
+func pinToNode(spec *apiv1.PodSpec, node string) {
+	if spec.Affinity == nil {
+		spec.Affinity = &apiv1.Affinity{}
+	}
+	if spec.Affinity.NodeAffinity == nil {
+		spec.Affinity.NodeAffinity = &apiv1.NodeAffinity{}
+	}
+	spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &apiv1.NodeSelector{
+		NodeSelectorTerms: []apiv1.NodeSelectorTerm{
+			{
+				MatchFields: []apiv1.NodeSelectorRequirement{getNodeRequirement(node, apiv1.NodeSelectorOpIn)},
+			},
+		},
+	}
+}
+
+// addNodeExclusion prevents the pods of a pod spec from running on the given node
+func addNodeExclusion(spec *apiv1.PodSpec, node string) {
+	if spec.Affinity == nil {
+		spec.Affinity = &apiv1.Affinity{}
+	}
+	if spec.Affinity.NodeAffinity == nil {
+		spec.Affinity.NodeAffinity = &apiv1.NodeAffinity{}
+	}
+	required := spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if required == nil || len(required.NodeSelectorTerms) == 0 {
+		required = &apiv1.NodeSelector{NodeSelectorTerms: []apiv1.NodeSelectorTerm{{}}}
+	}
+	// node selector terms are ORed, the exclusion must be part of all of them
+	for i := range required.NodeSelectorTerms {
+		required.NodeSelectorTerms[i].MatchFields = append(required.NodeSelectorTerms[i].MatchFields, getNodeRequirement(node, apiv1.NodeSelectorOpNotIn))
+	}
+	spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = required
+}
+
+// removeNodeExclusion reverts addNodeExclusion
+func removeNodeExclusion(spec *apiv1.PodSpec, node string) {
+	if !hasNodeExclusion(spec, node) {
+		return
+	}
+	required := spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	terms := []apiv1.NodeSelectorTerm{}
+	for _, term := range required.NodeSelectorTerms {
+		fields := []apiv1.NodeSelectorRequirement{}
+		for _, f := range term.MatchFields {
+			if !isNodeExclusion(f, node) {
+				fields = append(fields, f)
+			}
+		}
+		term.MatchFields = fields
+		if len(term.MatchFields) == 0 {
+			term.MatchFields = nil
+			if len(term.MatchExpressions) == 0 {
+				continue
+			}
+		}
+		terms = append(terms, term)
+	}
+	if len(terms) > 0 {
+		required.NodeSelectorTerms = terms
+		return
+	}
+	spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = nil
+	if spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution == nil {
+		spec.Affinity.NodeAffinity = nil
+	}
+	if spec.Affinity.NodeAffinity == nil && spec.Affinity.PodAffinity == nil && spec.Affinity.PodAntiAffinity == nil {
+		spec.Affinity = nil
+	}
+}
+
+func hasNodeExclusion(spec *apiv1.PodSpec, node string) bool {
+	if node == "" || spec.Affinity == nil || spec.Affinity.NodeAffinity == nil || spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return false
+	}
+	for _, term := range spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		for _, f := range term.MatchFields {
+			if isNodeExclusion(f, node) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isNodeExclusion(r apiv1.NodeSelectorRequirement, node string) bool {
+	return r.Key == nodeNameField && r.Operator == apiv1.NodeSelectorOpNotIn && len(r.Values) == 1 && r.Values[0] == node
+}
+
+func getNodeRequirement(node string, op apiv1.NodeSelectorOperator) apiv1.NodeSelectorRequirement {
+	return apiv1.NodeSelectorRequirement{
+		Key:      nodeNameField,
+		Operator: op,
+		Values:   []string{node},
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apps
+
+import (
+	"context"
+	"testing"
+
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetDaemonSet(t *testing.T) {
+	ctx := context.Background()
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "test",
+			UID:       "ds-uid",
+		},
+	}
+	pod := &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "test-abcde",
+			Namespace:       "test",
+			OwnerReferences: []metav1.OwnerReference{{UID: "ds-uid"}},
+		},
+		Spec:   apiv1.PodSpec{NodeName: "node-1"},
+		Status: apiv1.PodStatus{Phase: apiv1.PodRunning},
+	}
+	dev := &model.Dev{Name: "test", Namespace: "test"}
+
+	app, err := Get(ctx, dev, "test", fake.NewSimpleClientset(ds, pod))
+	assert.NoError(t, err)
+	assert.Equal(t, model.DaemonSet, app.Kind())
+	assert.Equal(t, "node-1", app.ObjectMeta().Annotations[model.DevNodeAnnotation])
+
+	_, err = Get(ctx, dev, "test", fake.NewSimpleClientset(ds))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "has no running pods")
+}
+
+func TestDaemonSetSetReplicas(t *testing.T) {
+	app := NewDaemonSetApp(&appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test",
+			Annotations: map[string]string{model.DevNodeAnnotation: "node-1"},
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Template: apiv1.PodTemplateSpec{
+				Spec: apiv1.PodSpec{
+					Affinity: &apiv1.Affinity{
+						NodeAffinity: &apiv1.NodeAffinity{
+							RequiredDuringSchedulingIgnoredDuringExecution: &apiv1.NodeSelector{
+								NodeSelectorTerms: []apiv1.NodeSelectorTerm{
+									{
+										MatchExpressions: []apiv1.NodeSelectorRequirement{
+											{Key: "kubernetes.io/os", Operator: apiv1.NodeSelectorOpIn, Values: []string{"linux"}},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+	original := app.PodSpec().Affinity.DeepCopy()
+	assert.Equal(t, int32(1), app.Replicas())
+
+	app.SetReplicas(0)
+	assert.Equal(t, int32(0), app.Replicas())
+	terms := app.PodSpec().Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	assert.Len(t, terms, 1)
+	assert.Equal(t, []apiv1.NodeSelectorRequirement{getNodeRequirement("node-1", apiv1.NodeSelectorOpNotIn)}, terms[0].MatchFields)
+
+	app.SetReplicas(0)
+	assert.Len(t, app.PodSpec().Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchFields, 1)
+
+	app.SetReplicas(1)
+	assert.Equal(t, int32(1), app.Replicas())
+	assert.Equal(t, original, app.PodSpec().Affinity)
+}
+
+func TestDaemonSetSetReplicasWithoutAffinity(t *testing.T) {
+	app := NewDaemonSetApp(&appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test",
+			Annotations: map[string]string{model.DevNodeAnnotation: "node-1"},
+		},
+	})
+
+	app.SetReplicas(0)
+	assert.Equal(t, int32(0), app.Replicas())
+	app.SetReplicas(1)
+	assert.Nil(t, app.PodSpec().Affinity)
+}
+
+func TestDaemonSetDevClone(t *testing.T) {
+	app := NewDaemonSetApp(&appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test",
+			Namespace:   "test",
+			UID:         "ds-uid",
+			Labels:      map[string]string{"app": "test"},
+			Annotations: map[string]string{model.DevNodeAnnotation: "node-1"},
+		},
+	})
+	app.SetReplicas(0)
+
+	clone := app.DevClone()
+	assert.Equal(t, model.DevCloneName("test"), clone.ObjectMeta().Name)
+	assert.Equal(t, "ds-uid", clone.ObjectMeta().Labels[model.DevCloneLabel])
+	assert.Equal(t, "test", clone.ObjectMeta().Labels["app"])
+	expected := []apiv1.NodeSelectorTerm{
+		{
+			MatchFields: []apiv1.NodeSelectorRequirement{getNodeRequirement("node-1", apiv1.NodeSelectorOpIn)},
+		},
+	}
+	assert.Equal(t, expected, clone.PodSpec().Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms)
+}
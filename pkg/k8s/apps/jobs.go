@@ -0,0 +1,227 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apps
+
+import (
+	"context"
+	"fmt"
+
+	oktetoErrors "github.com/okteto/okteto/pkg/errors"
+	"github.com/okteto/okteto/pkg/k8s/jobs"
+	"github.com/okteto/okteto/pkg/k8s/pods"
+	oktetoLog "github.com/okteto/okteto/pkg/log"
+	"github.com/okteto/okteto/pkg/model"
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/pointer"
+)
+
+// jobTemplateLabels are the labels added by the job controller to the pod template of a job
+var jobTemplateLabels = []string{"controller-uid", "job-name", "batch.kubernetes.io/controller-uid", "batch.kubernetes.io/job-name"}
+
+// JobApp is a job in development mode. The original job is suspended and the development container runs in a new job
+// created from the pod template of the original job
+type JobApp struct {
+	kind string
+	job  *batchv1.Job
+}
+
+func NewJobApp(job *batchv1.Job) *JobApp {
+	return &JobApp{kind: model.Job, job: job}
+}
+
+// newDevJob returns the dev clone of a job spec owned by the object with the given metadata
+func newDevJob(meta metav1.ObjectMeta, uid types.UID, spec *batchv1.JobSpec) *JobApp {
+	clone := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        model.DevCloneName(meta.Name),
+			Namespace:   meta.Namespace,
+			Labels:      map[string]string{},
+			Annotations: map[string]string{},
+		},
+		Spec: *spec.DeepCopy(),
+	}
+	clone.Labels[model.DevCloneLabel] = string(uid)
+	for k, v := range meta.Labels {
+		clone.Labels[k] = v
+	}
+	for k, v := range meta.Annotations {
+		clone.Annotations[k] = v
+	}
+
+	// the selector of the original job is generated by the job controller and can't be reused
+	clone.Spec.Selector = nil
+	clone.Spec.ManualSelector = nil
+	for _, l := range jobTemplateLabels {
+		delete(clone.Spec.Template.Labels, l)
+	}
+	clone.Spec.Suspend = nil
+	clone.Spec.Completions = pointer.Int32Ptr(1)
+	clone.Spec.Parallelism = pointer.Int32Ptr(1)
+	clone.Spec.ActiveDeadlineSeconds = nil
+	clone.Spec.TTLSecondsAfterFinished = nil
+	return NewJobApp(clone)
+}
+
+func (i *JobApp) Kind() string {
+	return i.kind
+}
+
+func (i *JobApp) ObjectMeta() metav1.ObjectMeta {
+	if i.job.ObjectMeta.Annotations == nil {
+		i.job.ObjectMeta.Annotations = map[string]string{}
+	}
+	if i.job.ObjectMeta.Labels == nil {
+		i.job.ObjectMeta.Labels = map[string]string{}
+	}
+	return i.job.ObjectMeta
+}
+
+// Replicas returns 0 if the job is suspended
+func (i *JobApp) Replicas() int32 {
+	if i.job.Spec.Suspend != nil && *i.job.Spec.Suspend {
+		return 0
+	}
+	return 1
+}
+
+// SetReplicas suspends the job if n is 0
+func (i *JobApp) SetReplicas(n int32) {
+	i.job.Spec.Suspend = pointer.BoolPtr(n == 0)
+}
+
+func (i *JobApp) TemplateObjectMeta() metav1.ObjectMeta {
+	if i.job.Spec.Template.ObjectMeta.Annotations == nil {
+		i.job.Spec.Template.ObjectMeta.Annotations = map[string]string{}
+	}
+	if i.job.Spec.Template.ObjectMeta.Labels == nil {
+		i.job.Spec.Template.ObjectMeta.Labels = map[string]string{}
+	}
+	return i.job.Spec.Template.ObjectMeta
+}
+
+func (i *JobApp) PodSpec() *apiv1.PodSpec {
+	return &i.job.Spec.Template.Spec
+}
+
+func (i *JobApp) DevClone() App {
+	return newDevJob(i.job.ObjectMeta, i.job.UID, &i.job.Spec)
+}
+
+func (i *JobApp) CheckConditionErrors(_ *model.Dev) error {
+	for _, c := range i.job.Status.Conditions {
+		if c.Type == batchv1.JobFailed && c.Status == apiv1.ConditionTrue {
+			return fmt.Errorf("job '%s' failed: %s", i.job.Name, c.Message)
+		}
+	}
+	return nil
+}
+
+func (i *JobApp) GetRunningPod(ctx context.Context, c kubernetes.Interface) (*apiv1.Pod, error) {
+	return pods.GetPodByOwner(ctx, i.job.Namespace, i.job.UID, c)
+}
+
+func (*JobApp) RestoreOriginal() error {
+	return nil
+}
+
+func (i *JobApp) Refresh(ctx context.Context, c kubernetes.Interface) error {
+	job, err := jobs.Get(ctx, i.job.Name, i.job.Namespace, c)
+	if err == nil {
+		i.job = job
+	}
+	return err
+}
+
+func (i *JobApp) Watch(ctx context.Context, result chan error, c kubernetes.Interface) {
+	optsWatch := metav1.ListOptions{
+		Watch:         true,
+		FieldSelector: fmt.Sprintf("metadata.name=%s", i.job.Name),
+	}
+
+	watcher, err := c.BatchV1().Jobs(i.job.Namespace).Watch(ctx, optsWatch)
+	if err != nil {
+		result <- err
+		return
+	}
+
+	for {
+		select {
+		case e := <-watcher.ResultChan():
+			oktetoLog.Debugf("Received job '%s' event: %s", i.job.Name, e)
+			if e.Object == nil {
+				oktetoLog.Debugf("Recreating job '%s' watcher", i.job.Name)
+				watcher, err = c.BatchV1().Jobs(i.job.Namespace).Watch(ctx, optsWatch)
+				if err != nil {
+					result <- err
+					return
+				}
+				continue
+			}
+			switch e.Type {
+			case watch.Deleted:
+				result <- oktetoErrors.ErrDeleteToApp
+				return
+			case watch.Modified:
+				job, ok := e.Object.(*batchv1.Job)
+				if !ok {
+					oktetoLog.Debugf("Failed to parse job event: %s", e)
+					continue
+				}
+				if job.Generation != i.job.Generation {
+					result <- oktetoErrors.ErrApplyToApp
+					return
+				}
+			}
+		case err := <-ctx.Done():
+			oktetoLog.Debugf("call to up.applyToApp cancelled: %v", err)
+			return
+		}
+	}
+}
+
+// Deploy recreates the dev clones. The pod template of a job is immutable,
+// only the metadata and the suspension of the original job are updated
+func (i *JobApp) Deploy(ctx context.Context, c kubernetes.Interface) error {
+	if i.job.Labels[model.DevCloneLabel] != "" {
+		i.job.ResourceVersion = ""
+		return jobs.Update(ctx, i.job, c)
+	}
+
+	job, err := jobs.Get(ctx, i.job.Name, i.job.Namespace, c)
+	if err != nil {
+		return err
+	}
+	job.Labels = i.job.Labels
+	job.Annotations = i.job.Annotations
+	job.Spec.Suspend = i.job.Spec.Suspend
+	job, err = c.BatchV1().Jobs(job.Namespace).Update(ctx, job, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+	i.job = job
+	return nil
+}
+
+func (i *JobApp) PatchAnnotations(ctx context.Context, c kubernetes.Interface) error {
+	return jobs.PatchAnnotations(ctx, i.job, c)
+}
+
+func (i *JobApp) Destroy(ctx context.Context, c kubernetes.Interface) error {
+	return jobs.Destroy(ctx, i.job.Name, i.job.Namespace, c)
+}
@@ -0,0 +1,145 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apps
+
+import (
+	"context"
+	"testing"
+
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
+)
+
+func TestGetJobAndCronJob(t *testing.T) {
+	ctx := context.Background()
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "job", Namespace: "test"},
+	}
+	cj := &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "cronjob", Namespace: "test"},
+	}
+	clientset := fake.NewSimpleClientset(job, cj)
+
+	app, err := Get(ctx, &model.Dev{Name: "job", Namespace: "test"}, "test", clientset)
+	assert.NoError(t, err)
+	assert.Equal(t, model.Job, app.Kind())
+
+	app, err = Get(ctx, &model.Dev{Name: "cronjob", Namespace: "test"}, "test", clientset)
+	assert.NoError(t, err)
+	assert.Equal(t, model.CronJob, app.Kind())
+
+	_, err = Get(ctx, &model.Dev{Name: "other", Namespace: "test"}, "test", clientset)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "doesn't exist")
+}
+
+func TestJobDevClone(t *testing.T) {
+	app := NewJobApp(&batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "test",
+			UID:       "job-uid",
+			Labels:    map[string]string{"app": "test"},
+		},
+		Spec: batchv1.JobSpec{
+			Selector:    &metav1.LabelSelector{MatchLabels: map[string]string{"controller-uid": "job-uid"}},
+			Completions: pointer.Int32Ptr(5),
+			Parallelism: pointer.Int32Ptr(2),
+			Template: apiv1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"controller-uid": "job-uid", "job-name": "test", "app": "test"},
+				},
+			},
+		},
+	})
+	app.SetReplicas(0)
+	assert.Equal(t, int32(0), app.Replicas())
+
+	clone := app.DevClone()
+	assert.Equal(t, model.Job, clone.Kind())
+	assert.Equal(t, model.DevCloneName("test"), clone.ObjectMeta().Name)
+	assert.Equal(t, "job-uid", clone.ObjectMeta().Labels[model.DevCloneLabel])
+	assert.Equal(t, map[string]string{"app": "test"}, clone.TemplateObjectMeta().Labels)
+	assert.Equal(t, int32(1), clone.Replicas())
+
+	cloneJob := clone.(*JobApp).job
+	assert.Nil(t, cloneJob.Spec.Selector)
+	assert.Equal(t, int32(1), *cloneJob.Spec.Completions)
+	assert.Equal(t, int32(1), *cloneJob.Spec.Parallelism)
+}
+
+func TestJobDeploy(t *testing.T) {
+	ctx := context.Background()
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test", UID: "job-uid"},
+		Spec: batchv1.JobSpec{
+			Template: apiv1.PodTemplateSpec{
+				Spec: apiv1.PodSpec{Containers: []apiv1.Container{{Name: "test", Image: "image"}}},
+			},
+		},
+	}
+	clientset := fake.NewSimpleClientset(job)
+
+	app := NewJobApp(job.DeepCopy())
+	app.ObjectMeta().Labels[model.DevLabel] = "true"
+	app.SetReplicas(0)
+	app.PodSpec().Containers[0].Image = "dev"
+	assert.NoError(t, app.Deploy(ctx, clientset))
+
+	result, err := clientset.BatchV1().Jobs("test").Get(ctx, "test", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "true", result.Labels[model.DevLabel])
+	assert.True(t, *result.Spec.Suspend)
+	assert.Equal(t, "image", result.Spec.Template.Spec.Containers[0].Image)
+
+	clone := app.DevClone()
+	assert.NoError(t, clone.Deploy(ctx, clientset))
+	assert.NoError(t, clone.Deploy(ctx, clientset))
+	_, err = clientset.BatchV1().Jobs("test").Get(ctx, model.DevCloneName("test"), metav1.GetOptions{})
+	assert.NoError(t, err)
+
+	assert.NoError(t, clone.Destroy(ctx, clientset))
+	_, err = clientset.BatchV1().Jobs("test").Get(ctx, model.DevCloneName("test"), metav1.GetOptions{})
+	assert.Error(t, err)
+}
+
+func TestCronJobDevClone(t *testing.T) {
+	app := NewCronJobApp(&batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test", UID: "cj-uid"},
+		Spec: batchv1.CronJobSpec{
+			JobTemplate: batchv1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					Template: apiv1.PodTemplateSpec{
+						Spec: apiv1.PodSpec{Containers: []apiv1.Container{{Name: "test", Image: "image"}}},
+					},
+				},
+			},
+		},
+	})
+	assert.Equal(t, int32(1), app.Replicas())
+	app.SetReplicas(0)
+	assert.True(t, *app.cj.Spec.Suspend)
+
+	clone := app.DevClone()
+	assert.Equal(t, model.Job, clone.Kind())
+	assert.Equal(t, model.DevCloneName("test"), clone.ObjectMeta().Name)
+	assert.Equal(t, "cj-uid", clone.ObjectMeta().Labels[model.DevCloneLabel])
+	assert.Equal(t, "image", clone.PodSpec().Containers[0].Image)
+	assert.Equal(t, int32(1), clone.Replicas())
+}
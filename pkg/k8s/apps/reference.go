@@ -0,0 +1,53 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apps
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/okteto/okteto/pkg/model"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// builtinResources are the resources of the kinds supported by okteto up, besides custom resources
+var builtinResources = map[string]schema.GroupVersionResource{
+	model.Deployment:  {Group: "apps", Version: "v1", Resource: "deployments"},
+	model.StatefulSet: {Group: "apps", Version: "v1", Resource: "statefulsets"},
+	model.DaemonSet:   {Group: "apps", Version: "v1", Resource: "daemonsets"},
+	model.Job:         {Group: "batch", Version: "v1", Resource: "jobs"},
+	model.CronJob:     {Group: "batch", Version: "v1", Resource: "cronjobs"},
+}
+
+// GetReference returns a reference to an app in the format 'resource.version.group/name', e.g. 'deployments.v1.apps/api'
+func GetReference(app App) string {
+	gvr := builtinResources[app.Kind()]
+	if custom, ok := app.(*CustomApp); ok {
+		gvr = custom.gvr
+	}
+	return fmt.Sprintf("%s.%s.%s/%s", gvr.Resource, gvr.Version, gvr.Group, app.ObjectMeta().Name)
+}
+
+// ParseReference returns the resource and name of an app reference returned by GetReference
+func ParseReference(ref string) (schema.GroupVersionResource, string, error) {
+	i := strings.LastIndex(ref, "/")
+	if i <= 0 || i == len(ref)-1 {
+		return schema.GroupVersionResource{}, "", fmt.Errorf("app reference '%s' is not valid", ref)
+	}
+	gvr, _ := schema.ParseResourceArg(ref[:i])
+	if gvr == nil {
+		return schema.GroupVersionResource{}, "", fmt.Errorf("app reference '%s' is not valid", ref)
+	}
+	return *gvr, ref[i+1:], nil
+}
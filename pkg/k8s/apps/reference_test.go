@@ -0,0 +1,76 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apps
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func Test_GetReference(t *testing.T) {
+	rollout := &CustomApp{
+		kind: "Rollout",
+		gvr:  schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "rollouts"},
+		obj:  &unstructured.Unstructured{},
+		meta: metav1.ObjectMeta{Name: "web"},
+	}
+	tests := []struct {
+		name        string
+		app         App
+		expected    string
+		expectedGVR schema.GroupVersionResource
+	}{
+		{
+			name:        "deployment",
+			app:         NewDeploymentApp(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "api"}}),
+			expected:    "deployments.v1.apps/api",
+			expectedGVR: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		},
+		{
+			name:        "cronjob",
+			app:         NewCronJobApp(&batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: "backup"}}),
+			expected:    "cronjobs.v1.batch/backup",
+			expectedGVR: schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "cronjobs"},
+		},
+		{
+			name:        "custom",
+			app:         rollout,
+			expected:    "rollouts.v1alpha1.argoproj.io/web",
+			expectedGVR: rollout.gvr,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref := GetReference(tt.app)
+			assert.Equal(t, tt.expected, ref)
+
+			gvr, name, err := ParseReference(ref)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedGVR, gvr)
+			assert.Equal(t, tt.app.ObjectMeta().Name, name)
+		})
+	}
+
+	for _, ref := range []string{"", "api", "deployments.v1.apps/", "/api"} {
+		_, _, err := ParseReference(ref)
+		assert.Error(t, err, ref)
+	}
+}
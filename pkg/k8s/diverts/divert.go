@@ -51,7 +51,13 @@ func DivertIngress(ctx context.Context, m *model.Manifest, fromIn *networkingv1.
 			}
 		}
 	}
-	return createDivertCRD(ctx, m, fromIn)
+	rules := m.Deploy.Divert.GetRules()
+	for _, rule := range rules {
+		if err := createDivertCRD(ctx, m, fromIn, rule, len(rules) > 1); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func divertService(ctx context.Context, m *model.Manifest, name string, c kubernetes.Interface) error {
@@ -112,13 +118,14 @@ func divertEndpoints(ctx context.Context, m *model.Manifest, name string, c kube
 	return err
 }
 
-func createDivertCRD(ctx context.Context, m *model.Manifest, in *networkingv1.Ingress) error {
+// createDivertCRD creates or updates the divert CRD of a rule for an ingress
+func createDivertCRD(ctx context.Context, m *model.Manifest, in *networkingv1.Ingress, rule model.DivertRule, multipleRules bool) error {
 	dClient, err := getDivertClient()
 	if err != nil {
 		return fmt.Errorf("error creating divert CRD client: %s", err.Error())
 	}
 
-	divertCRD := translateDivertCRD(m, in)
+	divertCRD := translateDivertCRD(m, in, rule, multipleRules)
 
 	old, err := dClient.Diverts(m.Namespace).Get(ctx, divertCRD.Name, metav1.GetOptions{})
 	if err != nil && !oktetoErrors.IsNotFound(err) {
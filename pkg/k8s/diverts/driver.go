@@ -0,0 +1,60 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diverts
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/okteto/okteto/pkg/model"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Driver diverts the traffic of the services of the diverted namespace to the development environment
+type Driver interface {
+	// Deploy creates or updates the resources that divert the traffic
+	Deploy(ctx context.Context) error
+	// Destroy deletes the resources that divert the traffic and are not destroyed with the development environment
+	Destroy(ctx context.Context) error
+	// ListDestroyed returns the resources that Destroy would delete or update
+	ListDestroyed(ctx context.Context) ([]DestroyedResource, error)
+}
+
+const (
+	// DeleteAction is the action of the resources deleted by Destroy
+	DeleteAction = "delete"
+	// UpdateAction is the action of the resources shared with other development environments, updated by Destroy
+	UpdateAction = "update"
+)
+
+// DestroyedResource is a resource that Destroy deletes or updates
+type DestroyedResource struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Action    string `json:"action"`
+}
+
+// NewDriver returns the driver of the divert section of the manifest
+func NewDriver(m *model.Manifest, c kubernetes.Interface, dynClient dynamic.Interface) (Driver, error) {
+	switch driver := m.Deploy.Divert.GetDriver(); driver {
+	case model.DivertWeaverDriver:
+		return &weaverDriver{manifest: m, client: c}, nil
+	case model.DivertIstioDriver:
+		return &istioDriver{manifest: m, client: dynClient}, nil
+	default:
+		return nil, fmt.Errorf("divert driver '%s' is not supported", driver)
+	}
+}
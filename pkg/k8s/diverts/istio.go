@@ -0,0 +1,335 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diverts
+
+import (
+	"context"
+	"fmt"
+
+	oktetoErrors "github.com/okteto/okteto/pkg/errors"
+	oktetoLog "github.com/okteto/okteto/pkg/log"
+	"github.com/okteto/okteto/pkg/model"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	// DefaultIstioHeader is the header that routes the traffic to the development environment if the divert doesn't define one
+	DefaultIstioHeader = "x-okteto-divert"
+
+	istioAPIVersion = "networking.istio.io/v1beta1"
+
+	// istioDefaultRoute is the name of the route to the diverted service, used by the requests without the divert header
+	istioDefaultRoute = "okteto-default"
+)
+
+var (
+	virtualServiceResource  = schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1beta1", Resource: "virtualservices"}
+	destinationRuleResource = schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1beta1", Resource: "destinationrules"}
+)
+
+// istioDriver routes the requests with the divert header to the services of the development environment.
+// Every diverted service has a virtual service in the diverted namespace, shared by all the development environments
+// that divert it: each development environment owns an http route named as its namespace.
+// The traffic between namespaces is encrypted by a destination rule in the namespace of the development environment
+type istioDriver struct {
+	manifest *model.Manifest
+	client   dynamic.Interface
+}
+
+// Deploy adds the routes of the development environment to the virtual services of the diverted services
+func (d *istioDriver) Deploy(ctx context.Context) error {
+	for _, rule := range d.manifest.Deploy.Divert.GetRules() {
+		oktetoLog.Spinner(fmt.Sprintf("Diverting service %s/%s...", d.manifest.Deploy.Divert.Namespace, rule.Service))
+		if err := d.deployVirtualService(ctx, rule); err != nil {
+			return fmt.Errorf("error diverting service '%s': %w", rule.Service, err)
+		}
+		if err := d.deployDestinationRule(ctx, rule); err != nil {
+			return fmt.Errorf("error diverting service '%s': %w", rule.Service, err)
+		}
+	}
+	return nil
+}
+
+// Destroy removes the routes of the development environment from the virtual services of the diverted services.
+// Virtual services without routes of any development environment are deleted
+func (d *istioDriver) Destroy(ctx context.Context) error {
+	divert := d.manifest.Deploy.Divert
+	for _, rule := range divert.GetRules() {
+		if err := d.destroyVirtualService(ctx, rule); err != nil {
+			return err
+		}
+	}
+
+	for _, rule := range divert.GetRules() {
+		err := d.client.Resource(destinationRuleResource).Namespace(d.manifest.Namespace).Delete(ctx, getIstioResourceName(rule.Service), metav1.DeleteOptions{})
+		if err != nil && !oktetoErrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListDestroyed returns the virtual services and destination rules that Destroy would delete or update
+func (d *istioDriver) ListDestroyed(ctx context.Context) ([]DestroyedResource, error) {
+	divert := d.manifest.Deploy.Divert
+	result := []DestroyedResource{}
+	for _, rule := range divert.GetRules() {
+		vs, err := d.client.Resource(virtualServiceResource).Namespace(divert.Namespace).Get(ctx, getIstioResourceName(rule.Service), metav1.GetOptions{})
+		if err != nil {
+			if oktetoErrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		routes, _, err := unstructured.NestedSlice(vs.Object, "spec", "http")
+		if err != nil {
+			return nil, fmt.Errorf("error reading virtual service '%s': %w", vs.GetName(), err)
+		}
+		remaining := removeIstioRoute(routes, d.manifest.Namespace)
+		action := UpdateAction
+		if len(remaining) <= 1 {
+			action = DeleteAction
+		} else if len(remaining) == len(routes) {
+			continue
+		}
+		result = append(result, DestroyedResource{Kind: "VirtualService", Namespace: vs.GetNamespace(), Name: vs.GetName(), Action: action})
+	}
+
+	for _, rule := range divert.GetRules() {
+		dr, err := d.client.Resource(destinationRuleResource).Namespace(d.manifest.Namespace).Get(ctx, getIstioResourceName(rule.Service), metav1.GetOptions{})
+		if err != nil {
+			if oktetoErrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		result = append(result, DestroyedResource{Kind: "DestinationRule", Namespace: dr.GetNamespace(), Name: dr.GetName(), Action: DeleteAction})
+	}
+	return result, nil
+}
+
+// deployVirtualService adds the route of the development environment to the virtual service of a diverted service.
+// The routes are read again on conflicts, other development environments update the same virtual service
+func (d *istioDriver) deployVirtualService(ctx context.Context, rule model.DivertRule) error {
+	vsClient := d.client.Resource(virtualServiceResource).Namespace(d.manifest.Deploy.Divert.Namespace)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		vs, err := vsClient.Get(ctx, getIstioResourceName(rule.Service), metav1.GetOptions{})
+		if err != nil {
+			if !oktetoErrors.IsNotFound(err) {
+				return err
+			}
+			vs = translateVirtualService(d.manifest, rule, nil)
+			_, err = vsClient.Create(ctx, vs, metav1.CreateOptions{})
+			if k8sErrors.IsAlreadyExists(err) {
+				// created by another development environment since it was read: retry as a conflict to update it
+				return k8sErrors.NewConflict(virtualServiceResource.GroupResource(), vs.GetName(), err)
+			}
+			return err
+		}
+
+		routes, _, err := unstructured.NestedSlice(vs.Object, "spec", "http")
+		if err != nil {
+			return fmt.Errorf("error reading virtual service '%s': %w", vs.GetName(), err)
+		}
+		updated := translateVirtualService(d.manifest, rule, routes)
+		updated.SetResourceVersion(vs.GetResourceVersion())
+		_, err = vsClient.Update(ctx, updated, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// destroyVirtualService removes the route of the development environment from the virtual service of a diverted service,
+// and deletes the virtual service if no other development environment diverts the service
+func (d *istioDriver) destroyVirtualService(ctx context.Context, rule model.DivertRule) error {
+	vsClient := d.client.Resource(virtualServiceResource).Namespace(d.manifest.Deploy.Divert.Namespace)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		vs, err := vsClient.Get(ctx, getIstioResourceName(rule.Service), metav1.GetOptions{})
+		if err != nil {
+			if oktetoErrors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		routes, _, err := unstructured.NestedSlice(vs.Object, "spec", "http")
+		if err != nil {
+			return fmt.Errorf("error reading virtual service '%s': %w", vs.GetName(), err)
+		}
+		routes = removeIstioRoute(routes, d.manifest.Namespace)
+		if len(routes) <= 1 {
+			oktetoLog.Infof("deleting virtual service '%s/%s'", vs.GetNamespace(), vs.GetName())
+			// the precondition fails with a conflict if a development environment added its route since it was read
+			uid := vs.GetUID()
+			resourceVersion := vs.GetResourceVersion()
+			deleteOpts := metav1.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &uid, ResourceVersion: &resourceVersion}}
+			if err := vsClient.Delete(ctx, vs.GetName(), deleteOpts); err != nil && !oktetoErrors.IsNotFound(err) {
+				return err
+			}
+			return nil
+		}
+		if err := unstructured.SetNestedSlice(vs.Object, routes, "spec", "http"); err != nil {
+			return err
+		}
+		_, err = vsClient.Update(ctx, vs, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// deployDestinationRule creates or updates the destination rule of the service of the development environment.
+// It is read again on conflicts with other updates of the destination rule
+func (d *istioDriver) deployDestinationRule(ctx context.Context, rule model.DivertRule) error {
+	drClient := d.client.Resource(destinationRuleResource).Namespace(d.manifest.Namespace)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		dr := translateDestinationRule(d.manifest, rule)
+		old, err := drClient.Get(ctx, dr.GetName(), metav1.GetOptions{})
+		if err != nil {
+			if !oktetoErrors.IsNotFound(err) {
+				return err
+			}
+			_, err = drClient.Create(ctx, dr, metav1.CreateOptions{})
+			if k8sErrors.IsAlreadyExists(err) {
+				// created since it was read: retry as a conflict to update it
+				return k8sErrors.NewConflict(destinationRuleResource.GroupResource(), dr.GetName(), err)
+			}
+			return err
+		}
+		dr.SetResourceVersion(old.GetResourceVersion())
+		_, err = drClient.Update(ctx, dr, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// translateVirtualService returns the virtual service of a diverted service with the route of the development environment
+// added to the current routes
+func translateVirtualService(m *model.Manifest, rule model.DivertRule, routes []interface{}) *unstructured.Unstructured {
+	divert := m.Deploy.Divert
+	route := map[string]interface{}{
+		"name":  m.Namespace,
+		"match": getIstioMatches(m, rule),
+		"route": []interface{}{
+			map[string]interface{}{"destination": getIstioDestination(rule.Service, m.Namespace, rule.Port)},
+		},
+	}
+	defaultRoute := map[string]interface{}{
+		"name": istioDefaultRoute,
+		"route": []interface{}{
+			map[string]interface{}{"destination": getIstioDestination(rule.Service, divert.Namespace, rule.Port)},
+		},
+	}
+
+	vs := &unstructured.Unstructured{}
+	vs.SetAPIVersion(istioAPIVersion)
+	vs.SetKind("VirtualService")
+	vs.SetName(getIstioResourceName(rule.Service))
+	vs.SetNamespace(divert.Namespace)
+	vs.SetAnnotations(map[string]string{model.OktetoAutoCreateAnnotation: "true"})
+	vs.Object["spec"] = map[string]interface{}{
+		"hosts": []interface{}{getServiceHost(rule.Service, divert.Namespace)},
+		"http":  setIstioRoute(routes, route, defaultRoute),
+	}
+	return vs
+}
+
+// translateDestinationRule returns the destination rule of the service of the development environment.
+// The traffic policy is not set so the mesh defaults, like its mTLS mode, apply
+func translateDestinationRule(m *model.Manifest, rule model.DivertRule) *unstructured.Unstructured {
+	dr := &unstructured.Unstructured{}
+	dr.SetAPIVersion(istioAPIVersion)
+	dr.SetKind("DestinationRule")
+	dr.SetName(getIstioResourceName(rule.Service))
+	dr.SetNamespace(m.Namespace)
+	dr.SetLabels(map[string]string{model.DeployedByLabel: m.Name})
+	dr.SetAnnotations(map[string]string{model.OktetoAutoCreateAnnotation: "true"})
+	dr.Object["spec"] = map[string]interface{}{
+		"host": getServiceHost(rule.Service, m.Namespace),
+	}
+	return dr
+}
+
+// getIstioMatches returns the matches of the route of the development environment: the header and, if any, the paths
+func getIstioMatches(m *model.Manifest, rule model.DivertRule) []interface{} {
+	name := DefaultIstioHeader
+	value := m.Namespace
+	if rule.Header != nil {
+		name = rule.Header.Name
+		if rule.Header.Value != "" {
+			value = rule.Header.Value
+		}
+	}
+	headers := map[string]interface{}{
+		name: map[string]interface{}{"exact": value},
+	}
+	if len(rule.Paths) == 0 {
+		return []interface{}{map[string]interface{}{"headers": headers}}
+	}
+	result := []interface{}{}
+	for _, path := range rule.Paths {
+		result = append(result, map[string]interface{}{
+			"headers": headers,
+			"uri":     map[string]interface{}{"prefix": path},
+		})
+	}
+	return result
+}
+
+func getIstioDestination(service, namespace string, port int) map[string]interface{} {
+	result := map[string]interface{}{"host": getServiceHost(service, namespace)}
+	if port != 0 {
+		result["port"] = map[string]interface{}{"number": int64(port)}
+	}
+	return result
+}
+
+// setIstioRoute replaces the route with the same name, keeping the default route as the last one
+func setIstioRoute(routes []interface{}, route, defaultRoute map[string]interface{}) []interface{} {
+	result := []interface{}{}
+	for _, r := range removeIstioRoute(routes, route["name"].(string)) {
+		if getIstioRouteName(r) != istioDefaultRoute {
+			result = append(result, r)
+		}
+	}
+	return append(result, route, defaultRoute)
+}
+
+// removeIstioRoute returns the routes without the route with the given name
+func removeIstioRoute(routes []interface{}, name string) []interface{} {
+	result := []interface{}{}
+	for _, r := range routes {
+		if getIstioRouteName(r) != name {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+func getIstioRouteName(route interface{}) string {
+	r, ok := route.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	name, _ := r["name"].(string)
+	return name
+}
+
+func getIstioResourceName(service string) string {
+	return fmt.Sprintf("%s-okteto-divert", service)
+}
+
+func getServiceHost(service, namespace string) string {
+	return fmt.Sprintf("%s.%s.svc.cluster.local", service, namespace)
+}
@@ -0,0 +1,232 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diverts
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/stretchr/testify/assert"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicFake "k8s.io/client-go/dynamic/fake"
+	k8sTesting "k8s.io/client-go/testing"
+)
+
+func newIstioManifest(name, namespace string) *model.Manifest {
+	return &model.Manifest{
+		Name:      name,
+		Namespace: namespace,
+		Deploy: &model.DeployInfo{
+			Divert: &model.DivertDeploy{
+				Driver:    model.DivertIstioDriver,
+				Namespace: "staging",
+				Rules: []model.DivertRule{
+					{Service: "api", Port: 8080, Deployment: "api", Paths: []string{"/api"}},
+				},
+			},
+		},
+	}
+}
+
+func newFakeIstioClient() *dynamicFake.FakeDynamicClient {
+	return dynamicFake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		virtualServiceResource:  "VirtualServiceList",
+		destinationRuleResource: "DestinationRuleList",
+	})
+}
+
+func getRouteNames(t *testing.T, vs *unstructured.Unstructured) []string {
+	t.Helper()
+	routes, _, err := unstructured.NestedSlice(vs.Object, "spec", "http")
+	assert.NoError(t, err)
+	result := []string{}
+	for _, r := range routes {
+		result = append(result, getIstioRouteName(r))
+	}
+	return result
+}
+
+func TestIstioDriver(t *testing.T) {
+	ctx := context.Background()
+	c := newFakeIstioClient()
+	cindy := &istioDriver{manifest: newIstioManifest("movies", "cindy"), client: c}
+	alice := &istioDriver{manifest: newIstioManifest("movies", "alice"), client: c}
+
+	assert.NoError(t, cindy.Deploy(ctx))
+	assert.NoError(t, alice.Deploy(ctx))
+	// deploying again doesn't duplicate the route
+	assert.NoError(t, cindy.Deploy(ctx))
+
+	vs, err := c.Resource(virtualServiceResource).Namespace("staging").Get(ctx, "api-okteto-divert", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alice", "cindy", istioDefaultRoute}, getRouteNames(t, vs))
+
+	dr, err := c.Resource(destinationRuleResource).Namespace("cindy").Get(ctx, "api-okteto-divert", metav1.GetOptions{})
+	assert.NoError(t, err)
+	host, _, _ := unstructured.NestedString(dr.Object, "spec", "host")
+	assert.Equal(t, "api.cindy.svc.cluster.local", host)
+	assert.Equal(t, "movies", dr.GetLabels()[model.DeployedByLabel])
+	// the mesh defaults apply to the traffic of the development environment
+	_, found, _ := unstructured.NestedMap(dr.Object, "spec", "trafficPolicy")
+	assert.False(t, found)
+
+	destroyed, err := cindy.ListDestroyed(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []DestroyedResource{
+		{Kind: "VirtualService", Namespace: "staging", Name: "api-okteto-divert", Action: UpdateAction},
+		{Kind: "DestinationRule", Namespace: "cindy", Name: "api-okteto-divert", Action: DeleteAction},
+	}, destroyed)
+
+	assert.NoError(t, cindy.Destroy(ctx))
+	destroyed, err = cindy.ListDestroyed(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, destroyed)
+	vs, err = c.Resource(virtualServiceResource).Namespace("staging").Get(ctx, "api-okteto-divert", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alice", istioDefaultRoute}, getRouteNames(t, vs))
+	_, err = c.Resource(destinationRuleResource).Namespace("cindy").Get(ctx, "api-okteto-divert", metav1.GetOptions{})
+	assert.Error(t, err)
+
+	destroyed, err = alice.ListDestroyed(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []DestroyedResource{
+		{Kind: "VirtualService", Namespace: "staging", Name: "api-okteto-divert", Action: DeleteAction},
+		{Kind: "DestinationRule", Namespace: "alice", Name: "api-okteto-divert", Action: DeleteAction},
+	}, destroyed)
+
+	assert.NoError(t, alice.Destroy(ctx))
+	_, err = c.Resource(virtualServiceResource).Namespace("staging").Get(ctx, "api-okteto-divert", metav1.GetOptions{})
+	assert.Error(t, err)
+
+	// destroying twice is a no-op
+	assert.NoError(t, alice.Destroy(ctx))
+}
+
+func Test_translateVirtualService(t *testing.T) {
+	m := newIstioManifest("movies", "cindy")
+	m.Deploy.Divert.Rules[0].Header = &model.DivertHeader{Name: "x-team", Value: "beta"}
+
+	vs := translateVirtualService(m, m.Deploy.Divert.Rules[0], nil)
+	assert.Equal(t, "api-okteto-divert", vs.GetName())
+	assert.Equal(t, "staging", vs.GetNamespace())
+	assert.Equal(t, map[string]interface{}{
+		"hosts": []interface{}{"api.staging.svc.cluster.local"},
+		"http": []interface{}{
+			map[string]interface{}{
+				"name": "cindy",
+				"match": []interface{}{
+					map[string]interface{}{
+						"headers": map[string]interface{}{"x-team": map[string]interface{}{"exact": "beta"}},
+						"uri":     map[string]interface{}{"prefix": "/api"},
+					},
+				},
+				"route": []interface{}{
+					map[string]interface{}{"destination": map[string]interface{}{
+						"host": "api.cindy.svc.cluster.local",
+						"port": map[string]interface{}{"number": int64(8080)},
+					}},
+				},
+			},
+			map[string]interface{}{
+				"name": istioDefaultRoute,
+				"route": []interface{}{
+					map[string]interface{}{"destination": map[string]interface{}{
+						"host": "api.staging.svc.cluster.local",
+						"port": map[string]interface{}{"number": int64(8080)},
+					}},
+				},
+			},
+		},
+	}, vs.Object["spec"])
+}
+
+func Test_getIstioMatchesDefaultHeader(t *testing.T) {
+	m := newIstioManifest("movies", "cindy")
+	matches := getIstioMatches(m, model.DivertRule{Service: "api"})
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"headers": map[string]interface{}{DefaultIstioHeader: map[string]interface{}{"exact": "cindy"}},
+		},
+	}, matches)
+}
+
+func TestNewDriver(t *testing.T) {
+	m := newIstioManifest("movies", "cindy")
+	d, err := NewDriver(m, nil, newFakeIstioClient())
+	assert.NoError(t, err)
+	assert.IsType(t, &istioDriver{}, d)
+
+	m.Deploy.Divert.Driver = ""
+	d, err = NewDriver(m, nil, nil)
+	assert.NoError(t, err)
+	assert.IsType(t, &weaverDriver{}, d)
+
+	m.Deploy.Divert.Driver = "linkerd"
+	_, err = NewDriver(m, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestIstioDriverRetriesOnConflict(t *testing.T) {
+	ctx := context.Background()
+	c := newFakeIstioClient()
+	cindy := &istioDriver{manifest: newIstioManifest("movies", "cindy"), client: c}
+	alice := &istioDriver{manifest: newIstioManifest("movies", "alice"), client: c}
+	assert.NoError(t, cindy.Deploy(ctx))
+
+	// alice adds her route while cindy is updating the virtual service
+	conflicts := 0
+	c.PrependReactor("update", "virtualservices", func(action k8sTesting.Action) (bool, runtime.Object, error) {
+		if conflicts > 0 {
+			return false, nil, nil
+		}
+		conflicts++
+		obj, err := c.Tracker().Get(virtualServiceResource, "staging", "api-okteto-divert")
+		assert.NoError(t, err)
+		vs := obj.(*unstructured.Unstructured)
+		routes, _, err := unstructured.NestedSlice(vs.Object, "spec", "http")
+		assert.NoError(t, err)
+		assert.NoError(t, c.Tracker().Update(virtualServiceResource, translateVirtualService(alice.manifest, alice.manifest.Deploy.Divert.Rules[0], routes), "staging"))
+		return true, nil, k8sErrors.NewConflict(virtualServiceResource.GroupResource(), "api-okteto-divert", errors.New("the object has been modified"))
+	})
+	assert.NoError(t, cindy.Deploy(ctx))
+	assert.Equal(t, 1, conflicts)
+
+	vs, err := c.Resource(virtualServiceResource).Namespace("staging").Get(ctx, "api-okteto-divert", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alice", "cindy", istioDefaultRoute}, getRouteNames(t, vs))
+
+	// the destination rule is read again on conflicts
+	drConflicts := 0
+	c.PrependReactor("update", "destinationrules", func(action k8sTesting.Action) (bool, runtime.Object, error) {
+		if drConflicts > 0 {
+			return false, nil, nil
+		}
+		drConflicts++
+		return true, nil, k8sErrors.NewConflict(destinationRuleResource.GroupResource(), "api-okteto-divert", errors.New("the object has been modified"))
+	})
+	assert.NoError(t, cindy.Deploy(ctx))
+	assert.Equal(t, 1, drConflicts)
+
+	conflicts = 0
+	assert.NoError(t, cindy.Destroy(ctx))
+	vs, err = c.Resource(virtualServiceResource).Namespace("staging").Get(ctx, "api-okteto-divert", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alice", istioDefaultRoute}, getRouteNames(t, vs))
+}
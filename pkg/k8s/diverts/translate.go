@@ -91,14 +91,33 @@ func translateEndpoints(m *model.Manifest, e *apiv1.Endpoints, resourceVersion s
 	return result
 }
 
-func translateDivertCRD(m *model.Manifest, in *networkingv1.Ingress) *Divert {
+// getDivertCRDName returns the name of the divert CRD of a rule for an ingress. When there are several rules
+// the name includes the name of the diverted service
+func getDivertCRDName(m *model.Manifest, in *networkingv1.Ingress, rule model.DivertRule, multipleRules bool) string {
+	name := fmt.Sprintf("%s-%s", m.Name, in.Name)
+	if multipleRules {
+		name = fmt.Sprintf("%s-%s", name, rule.Service)
+	}
+	return name
+}
+
+func translateDivertCRD(m *model.Manifest, in *networkingv1.Ingress, rule model.DivertRule, multipleRules bool) *Divert {
+	name := getDivertCRDName(m, in, rule, multipleRules)
+	header := ""
+	value := m.Namespace
+	if rule.Header != nil {
+		header = rule.Header.Name
+		if rule.Header.Value != "" {
+			value = rule.Header.Value
+		}
+	}
 	result := &Divert{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "Divert",
 			APIVersion: "weaver.okteto.com/v1",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:        fmt.Sprintf("%s-%s", m.Name, in.Name),
+			Name:        name,
 			Namespace:   m.Namespace,
 			Labels:      map[string]string{model.DeployedByLabel: m.Name},
 			Annotations: map[string]string{model.OktetoAutoCreateAnnotation: "true"},
@@ -107,20 +126,22 @@ func translateDivertCRD(m *model.Manifest, in *networkingv1.Ingress) *Divert {
 			Ingress: IngressDivertSpec{
 				Name:      in.Name,
 				Namespace: m.Namespace,
-				Value:     m.Namespace,
+				Header:    header,
+				Value:     value,
+				Paths:     rule.Paths,
 			},
 			FromService: ServiceDivertSpec{
-				Name:      m.Deploy.Divert.Service,
+				Name:      rule.Service,
 				Namespace: m.Deploy.Divert.Namespace,
-				Port:      m.Deploy.Divert.Port,
+				Port:      rule.Port,
 			},
 			ToService: ServiceDivertSpec{
-				Name:      m.Deploy.Divert.Service,
+				Name:      rule.Service,
 				Namespace: m.Namespace,
-				Port:      m.Deploy.Divert.Port,
+				Port:      rule.Port,
 			},
 			Deployment: DeploymentDivertSpec{
-				Name:      m.Deploy.Divert.Deployment,
+				Name:      rule.Deployment,
 				Namespace: m.Deploy.Divert.Namespace,
 			},
 		},
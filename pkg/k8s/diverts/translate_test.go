@@ -370,6 +370,73 @@ func Test_translateDivertCRD(t *testing.T) {
 			},
 		},
 	}
-	result := translateDivertCRD(m, in)
+	result := translateDivertCRD(m, in, m.Deploy.Divert.GetRules()[0], false)
 	assert.True(t, reflect.DeepEqual(result, expected))
 }
+
+func Test_translateDivertCRDWithRules(t *testing.T) {
+	m := &model.Manifest{
+		Name:      "test",
+		Namespace: "cindy",
+		Deploy: &model.DeployInfo{
+			Divert: &model.DivertDeploy{
+				Namespace: "staging",
+				Header:    &model.DivertHeader{Name: "x-team"},
+				Rules: []model.DivertRule{
+					{
+						Service:    "api",
+						Port:       8080,
+						Deployment: "api",
+						Paths:      []string{"/api"},
+					},
+					{
+						Service:    "frontend",
+						Deployment: "frontend",
+						Header:     &model.DivertHeader{Name: "x-frontend", Value: "beta"},
+					},
+				},
+			},
+		},
+	}
+	in := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ingress",
+			Namespace: "staging",
+		},
+	}
+	rules := m.Deploy.Divert.GetRules()
+
+	result := translateDivertCRD(m, in, rules[0], true)
+	assert.Equal(t, "test-ingress-api", result.Name)
+	assert.Equal(t, IngressDivertSpec{
+		Name:      "ingress",
+		Namespace: "cindy",
+		Header:    "x-team",
+		Value:     "cindy",
+		Paths:     []string{"/api"},
+	}, result.Spec.Ingress)
+	assert.Equal(t, ServiceDivertSpec{Name: "api", Namespace: "staging", Port: 8080}, result.Spec.FromService)
+	assert.Equal(t, ServiceDivertSpec{Name: "api", Namespace: "cindy", Port: 8080}, result.Spec.ToService)
+	assert.Equal(t, DeploymentDivertSpec{Name: "api", Namespace: "staging"}, result.Spec.Deployment)
+
+	result = translateDivertCRD(m, in, rules[1], true)
+	assert.Equal(t, "test-ingress-frontend", result.Name)
+	assert.Equal(t, IngressDivertSpec{
+		Name:      "ingress",
+		Namespace: "cindy",
+		Header:    "x-frontend",
+		Value:     "beta",
+	}, result.Spec.Ingress)
+	assert.Equal(t, DeploymentDivertSpec{Name: "frontend", Namespace: "staging"}, result.Spec.Deployment)
+}
+
+func TestDivertDeepCopy(t *testing.T) {
+	d := &Divert{
+		Spec: DivertSpec{
+			Ingress: IngressDivertSpec{Paths: []string{"/api"}},
+		},
+	}
+	copied := d.DeepCopy()
+	copied.Spec.Ingress.Paths[0] = "/web"
+	assert.Equal(t, []string{"/api"}, d.Spec.Ingress.Paths)
+}
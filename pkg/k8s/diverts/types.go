@@ -21,7 +21,11 @@ import (
 type IngressDivertSpec struct {
 	Name      string `json:"name"`
 	Namespace string `json:"namespace"`
-	Value     string `json:"value,omitempty"`
+	// Header is the name of the header that routes the requests. The controller default is used if it's empty
+	Header string `json:"header,omitempty"`
+	Value  string `json:"value,omitempty"`
+	// Paths limits the divert to the requests whose path starts with any of the paths
+	Paths []string `json:"paths,omitempty"`
 }
 
 type ServiceDivertSpec struct {
@@ -70,7 +74,7 @@ func (in *Divert) DeepCopyInto(out *Divert) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	out.Status = in.Status
 }
 
@@ -121,6 +125,10 @@ func (in *DivertList) DeepCopyObject() runtime.Object {
 // DeepCopyInto a deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DivertSpec) DeepCopyInto(out *DivertSpec) {
 	*out = *in
+	if in.Ingress.Paths != nil {
+		out.Ingress.Paths = make([]string, len(in.Ingress.Paths))
+		copy(out.Ingress.Paths, in.Ingress.Paths)
+	}
 }
 
 // DeepCopy a deepcopy function, copying the receiver, creating a new DivertSpec.
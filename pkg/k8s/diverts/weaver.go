@@ -0,0 +1,99 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diverts
+
+import (
+	"context"
+	"fmt"
+
+	oktetoErrors "github.com/okteto/okteto/pkg/errors"
+	oktetoLog "github.com/okteto/okteto/pkg/log"
+	"github.com/okteto/okteto/pkg/model"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// weaverDriver clones the ingresses, services and endpoints of the diverted namespace
+// and creates the Divert CRDs handled by the divert controller of Okteto
+type weaverDriver struct {
+	manifest *model.Manifest
+	client   kubernetes.Interface
+}
+
+// Deploy diverts every ingress of the diverted namespace and deletes the divert CRDs of previous deploys that are not
+// in the current rules, they would keep diverting the traffic
+func (d *weaverDriver) Deploy(ctx context.Context) error {
+	result, err := d.client.NetworkingV1().Ingresses(d.manifest.Deploy.Divert.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	rules := d.manifest.Deploy.Divert.GetRules()
+	current := map[string]bool{}
+	for i := range result.Items {
+		select {
+		case <-ctx.Done():
+			oktetoLog.Infof("deployDivert context cancelled")
+			return ctx.Err()
+		default:
+			oktetoLog.Spinner(fmt.Sprintf("Diverting ingress %s/%s...", result.Items[i].Namespace, result.Items[i].Name))
+			if err := DivertIngress(ctx, d.manifest, &result.Items[i], d.client); err != nil {
+				return err
+			}
+			for _, rule := range rules {
+				current[getDivertCRDName(d.manifest, &result.Items[i], rule, len(rules) > 1)] = true
+			}
+		}
+	}
+
+	dClient, err := getDivertClient()
+	if err != nil {
+		return fmt.Errorf("error creating divert CRD client: %s", err.Error())
+	}
+	return deleteStaleDivertCRDs(ctx, d.manifest, current, dClient.Diverts(d.manifest.Namespace))
+}
+
+// deleteStaleDivertCRDs deletes the divert CRDs deployed by the development environment that are not in current
+func deleteStaleDivertCRDs(ctx context.Context, m *model.Manifest, current map[string]bool, dClient DivertInterface) error {
+	selector := fmt.Sprintf("%s=%s", model.DeployedByLabel, m.Name)
+	list, err := dClient.List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			// the divert CRD isn't installed in the cluster
+			return nil
+		}
+		return fmt.Errorf("error listing divert CRDs: %s", err)
+	}
+	for _, divert := range list.Items {
+		if current[divert.Name] {
+			continue
+		}
+		oktetoLog.Infof("deleting stale divert CRD '%s'", divert.Name)
+		if err := dClient.Delete(ctx, divert.Name); err != nil && !oktetoErrors.IsNotFound(err) {
+			return fmt.Errorf("error deleting divert CRD '%s': %s", divert.Name, err)
+		}
+	}
+	return nil
+}
+
+// Destroy is a no-op: the resources created by the driver are destroyed with the development environment
+func (*weaverDriver) Destroy(_ context.Context) error {
+	return nil
+}
+
+// ListDestroyed returns no resources: the resources created by the driver are destroyed with the development environment
+func (*weaverDriver) ListDestroyed(_ context.Context) ([]DestroyedResource, error) {
+	return nil, nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diverts
+
+import (
+	"context"
+	"testing"
+
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/stretchr/testify/assert"
+	networkingv1 "k8s.io/api/networking/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type fakeDivertClient struct {
+	diverts []Divert
+	deleted []string
+	listErr error
+}
+
+func (f *fakeDivertClient) List(_ context.Context, opts metav1.ListOptions) (*DivertList, error) {
+	if f.listErr != nil {
+		return nil, f.listErr
+	}
+	selector, err := labels.Parse(opts.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+	result := &DivertList{}
+	for _, d := range f.diverts {
+		if selector.Matches(labels.Set(d.Labels)) {
+			result.Items = append(result.Items, d)
+		}
+	}
+	return result, nil
+}
+
+func (*fakeDivertClient) Get(_ context.Context, _ string, _ metav1.GetOptions) (*Divert, error) {
+	return nil, nil
+}
+
+func (*fakeDivertClient) Create(_ context.Context, divert *Divert) (*Divert, error) {
+	return divert, nil
+}
+
+func (*fakeDivertClient) Update(_ context.Context, divert *Divert) (*Divert, error) {
+	return divert, nil
+}
+
+func (f *fakeDivertClient) Delete(_ context.Context, name string) error {
+	f.deleted = append(f.deleted, name)
+	return nil
+}
+
+func Test_deleteStaleDivertCRDs(t *testing.T) {
+	ctx := context.Background()
+	m := &model.Manifest{
+		Name:      "movies",
+		Namespace: "cindy",
+		Deploy: &model.DeployInfo{
+			Divert: &model.DivertDeploy{
+				Namespace: "staging",
+				Rules: []model.DivertRule{
+					{Service: "api", Port: 8080, Deployment: "api"},
+					{Service: "frontend", Port: 80, Deployment: "frontend"},
+				},
+			},
+		},
+	}
+	in := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "staging"}}
+	current := map[string]bool{}
+	for _, rule := range m.Deploy.Divert.Rules {
+		current[getDivertCRDName(m, in, rule, true)] = true
+	}
+	newDivert := func(name, deployedBy string) Divert {
+		return Divert{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{model.DeployedByLabel: deployedBy}}}
+	}
+	dClient := &fakeDivertClient{
+		diverts: []Divert{
+			// created by a previous deploy with a single rule
+			newDivert("movies-web", "movies"),
+			newDivert("movies-web-api", "movies"),
+			newDivert("movies-web-frontend", "movies"),
+			// created by a previous deploy for an ingress that no longer exists
+			newDivert("movies-old-api", "movies"),
+			newDivert("other-web", "other"),
+		},
+	}
+
+	assert.NoError(t, deleteStaleDivertCRDs(ctx, m, current, dClient))
+	assert.Equal(t, []string{"movies-web", "movies-old-api"}, dClient.deleted)
+
+	// the divert CRD isn't installed
+	dClient = &fakeDivertClient{listErr: k8sErrors.NewNotFound(schema.GroupResource{Group: "weaver.okteto.com", Resource: "diverts"}, "")}
+	assert.NoError(t, deleteStaleDivertCRDs(ctx, m, current, dClient))
+	assert.Empty(t, dClient.deleted)
+}
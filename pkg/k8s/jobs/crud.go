@@ -15,15 +15,24 @@ package jobs
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	oktetoErrors "github.com/okteto/okteto/pkg/errors"
 	oktetoLog "github.com/okteto/okteto/pkg/log"
+	"github.com/okteto/okteto/pkg/model"
 	batchv1 "k8s.io/api/batch/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 )
 
+type patchAnnotations struct {
+	Op    string            `json:"op"`
+	Path  string            `json:"path"`
+	Value map[string]string `json:"value"`
+}
+
 func Create(ctx context.Context, job *batchv1.Job, c kubernetes.Interface) error {
 	_, err := c.BatchV1().Jobs(job.Namespace).Create(ctx, job, metav1.CreateOptions{})
 	if err != nil {
@@ -52,6 +61,55 @@ func List(ctx context.Context, namespace, labels string, c kubernetes.Interface)
 	return jobList.Items, nil
 }
 
+// Get returns a job object by name
+func Get(ctx context.Context, name, namespace string, c kubernetes.Interface) (*batchv1.Job, error) {
+	return c.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// GetByDev returns a job object given a dev struct (by name or by labels)
+func GetByDev(ctx context.Context, dev *model.Dev, namespace string, c kubernetes.Interface) (*batchv1.Job, error) {
+	if len(dev.Selector) == 0 {
+		return Get(ctx, dev.Name, namespace, c)
+	}
+
+	jobList, err := List(ctx, namespace, dev.LabelsSelector(), c)
+	if err != nil {
+		return nil, err
+	}
+	validJobs := []*batchv1.Job{}
+	for i := range jobList {
+		if jobList[i].Labels[model.DevCloneLabel] == "" {
+			validJobs = append(validJobs, &jobList[i])
+		}
+	}
+	if len(validJobs) == 0 {
+		return nil, oktetoErrors.ErrNotFound
+	}
+	if len(validJobs) > 1 {
+		return nil, fmt.Errorf("found '%d' jobs for labels '%s' instead of 1", len(validJobs), dev.LabelsSelector())
+	}
+	return validJobs[0], nil
+}
+
+// PatchAnnotations patches the job annotations
+func PatchAnnotations(ctx context.Context, job *batchv1.Job, c kubernetes.Interface) error {
+	payload := []patchAnnotations{
+		{
+			Op:    "replace",
+			Path:  "/metadata/annotations",
+			Value: job.Annotations,
+		},
+	}
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	if _, err := c.BatchV1().Jobs(job.Namespace).Patch(ctx, job.Name, types.JSONPatchType, payloadBytes, metav1.PatchOptions{}); err != nil {
+		return err
+	}
+	return nil
+}
+
 func Destroy(ctx context.Context, name, namespace string, c kubernetes.Interface) error {
 	oktetoLog.Infof("deleting job '%s'", name)
 	deletePropagation := metav1.DeletePropagationBackground
@@ -17,15 +17,19 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/ibuildthecloud/finalizers/pkg/world"
+	"github.com/okteto/okteto/pkg/cmd/pipeline"
 	"github.com/okteto/okteto/pkg/k8s/statefulsets"
 	"github.com/okteto/okteto/pkg/k8s/volumes"
 	oktetoLog "github.com/okteto/okteto/pkg/log"
 	"github.com/okteto/okteto/pkg/model"
 	"github.com/sirupsen/logrus"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -73,6 +77,56 @@ func NewNamespace(dynClient dynamic.Interface, discClient discovery.DiscoveryInt
 
 // DestroyWithLabel deletes all resources within a namespace
 func (n *Namespaces) DestroyWithLabel(ctx context.Context, ns string, opts DeleteAllOptions) error {
+	return n.wanderWithLabel(ctx, ns, opts, func(gvk schema.GroupVersionKind, mapping *meta.RESTMapping, m metav1.Object) error {
+		deleteOpts := metav1.DeleteOptions{}
+
+		// It seems that by default, client-go don't delete pods scheduled by jobs, so we need to set the propation policy
+		if gvk.Kind == jobKind {
+			deletePropagation := metav1.DeletePropagationBackground
+			deleteOpts.PropagationPolicy = &deletePropagation
+		}
+
+		err := n.dynClient.
+			Resource(mapping.Resource).
+			Namespace(ns).
+			Delete(ctx, m.GetName(), deleteOpts)
+
+		if err != nil {
+			oktetoLog.Debugf("error deleting '%s' '%s': %s", gvk.Kind, m.GetName(), err)
+			return err
+		}
+
+		oktetoLog.Debugf("successfully deleted '%s' '%s'", gvk.Kind, m.GetName())
+		return nil
+	})
+}
+
+// ListWithLabel returns the resources within a namespace that DestroyWithLabel would delete
+func (n *Namespaces) ListWithLabel(ctx context.Context, ns string, opts DeleteAllOptions) ([]pipeline.InventoryItem, error) {
+	result := []pipeline.InventoryItem{}
+	var mu sync.Mutex
+	err := n.wanderWithLabel(ctx, ns, opts, func(gvk schema.GroupVersionKind, mapping *meta.RESTMapping, m metav1.Object) error {
+		mu.Lock()
+		defer mu.Unlock()
+		result = append(result, pipeline.InventoryItem{
+			APIVersion: gvk.GroupVersion().String(),
+			Kind:       gvk.Kind,
+			Resource:   mapping.Resource.Resource,
+			Namespace:  ns,
+			Name:       m.GetName(),
+			UID:        string(m.GetUID()),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// wanderWithLabel calls fn for every resource within a namespace matching the label selector,
+// skipping the volumes if they are not included and the resources with the keep policy
+func (n *Namespaces) wanderWithLabel(ctx context.Context, ns string, opts DeleteAllOptions, fn func(schema.GroupVersionKind, *meta.RESTMapping, metav1.Object) error) error {
 	listOptions := metav1.ListOptions{
 		LabelSelector: opts.LabelSelector,
 	}
@@ -121,41 +175,122 @@ func (n *Namespaces) DestroyWithLabel(ctx context.Context, ns string, opts Delet
 		if err != nil {
 			return err
 		}
-		deleteOpts := metav1.DeleteOptions{}
+		return fn(gvk, mapping, m)
+	}))
+}
 
-		// It seems that by default, client-go don't delete pods scheduled by jobs, so we need to set the propation policy
-		if gvk.Kind == jobKind {
-			deletePropagation := metav1.DeletePropagationBackground
-			deleteOpts.PropagationPolicy = &deletePropagation
+// DestroyInventory deletes the objects of an inventory in reverse creation order, including cluster-scoped objects.
+// Objects that no longer exist or were recreated by someone else are skipped. It returns the objects that couldn't be deleted
+func (n *Namespaces) DestroyInventory(ctx context.Context, inventory pipeline.Inventory, opts DeleteAllOptions) pipeline.Inventory {
+	leftovers := pipeline.Inventory{}
+	for i := len(inventory) - 1; i >= 0; i-- {
+		item := inventory[i]
+		if err := n.destroyInventoryItem(ctx, item, opts); err != nil {
+			oktetoLog.Infof("could not delete %s: %s", item.String(), err)
+			leftovers = append(leftovers, item)
 		}
+	}
+	return leftovers
+}
 
-		err = n.dynClient.
-			Resource(mapping.Resource).
-			Namespace(ns).
-			Delete(ctx, m.GetName(), deleteOpts)
+func (n *Namespaces) destroyInventoryItem(ctx context.Context, item pipeline.InventoryItem, opts DeleteAllOptions) error {
+	client, obj, err := n.getInventoryItem(ctx, item, opts)
+	if err != nil || obj == nil {
+		return err
+	}
+
+	deletePropagation := metav1.DeletePropagationBackground
+	deleteOpts := metav1.DeleteOptions{
+		PropagationPolicy: &deletePropagation,
+	}
+	if item.UID != "" {
+		uid := obj.GetUID()
+		deleteOpts.Preconditions = &metav1.Preconditions{UID: &uid}
+	}
+	if err := client.Delete(ctx, item.Name, deleteOpts); err != nil && !k8sErrors.IsNotFound(err) {
+		return err
+	}
+	oktetoLog.Debugf("successfully deleted %s", item.String())
+	return nil
+}
 
+// ListInventory returns the objects of the inventory that DestroyInventory would delete
+func (n *Namespaces) ListInventory(ctx context.Context, inventory pipeline.Inventory, opts DeleteAllOptions) ([]pipeline.InventoryItem, error) {
+	result := []pipeline.InventoryItem{}
+	for _, item := range inventory {
+		_, obj, err := n.getInventoryItem(ctx, item, opts)
 		if err != nil {
-			oktetoLog.Debugf("error deleting '%s' '%s': %s", gvk.Kind, m.GetName(), err)
-			return err
+			return nil, fmt.Errorf("could not get %s: %w", item.String(), err)
 		}
+		if obj != nil {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
 
-		oktetoLog.Debugf("successfully deleted '%s' '%s'", gvk.Kind, m.GetName())
-		return nil
-	}))
+// getInventoryItem returns the object of an inventory item, or nil if it must not be deleted
+func (n *Namespaces) getInventoryItem(ctx context.Context, item pipeline.InventoryItem, opts DeleteAllOptions) (dynamic.ResourceInterface, *unstructured.Unstructured, error) {
+	gvr, err := item.GroupVersionResource()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var client dynamic.ResourceInterface = n.dynClient.Resource(gvr)
+	if item.Namespace != "" {
+		client = n.dynClient.Resource(gvr).Namespace(item.Namespace)
+	}
+
+	obj, err := client.Get(ctx, item.Name, metav1.GetOptions{})
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			oktetoLog.Debugf("%s was already deleted", item.String())
+			return client, nil, nil
+		}
+		return nil, nil, err
+	}
+	if item.UID != "" && string(obj.GetUID()) != item.UID {
+		oktetoLog.Debugf("skipping deletion of %s because it was recreated outside of okteto deploy", item.String())
+		return client, nil, nil
+	}
+	if item.Kind == volumeKind && !opts.IncludeVolumes {
+		oktetoLog.Debugf("skipping deletion of pvc '%s' because of volume flag", item.Name)
+		return client, nil, nil
+	}
+	if obj.GetAnnotations()[resourcePolicyAnnotation] == keepPolicy {
+		oktetoLog.Debugf("skipping deletion of %s because of policy annotation", item.String())
+		return client, nil, nil
+	}
+	return client, obj, nil
 }
 
 // DestroySFSVolumes This function deletes volumes for any statefulset that matches with opts.LabelSelector but it doesn't have any
 // dev.okteto.com/deployed-by label. This is to avoid to left PVCs behind when everything deployed with okteto deploy
 // command is deleted
 func (n *Namespaces) DestroySFSVolumes(ctx context.Context, ns string, opts DeleteAllOptions) error {
+	pvcNames, err := n.ListSFSVolumes(ctx, ns, opts)
+	if err != nil {
+		return err
+	}
+	for _, name := range pvcNames {
+		if err := volumes.DestroyWithoutTimeout(ctx, name, ns, n.k8sClient); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListSFSVolumes returns the volumes that DestroySFSVolumes would delete
+func (n *Namespaces) ListSFSVolumes(ctx context.Context, ns string, opts DeleteAllOptions) ([]string, error) {
+	result := []string{}
 	if !opts.IncludeVolumes {
-		return nil
+		return result, nil
 	}
 	pvcNames := []string{}
 
 	ssList, err := statefulsets.List(ctx, ns, opts.LabelSelector, n.k8sClient)
 	if err != nil {
-		return fmt.Errorf("error getting statefulsets: %s", err)
+		return nil, fmt.Errorf("error getting statefulsets: %s", err)
 	}
 	for _, ss := range ssList {
 		for _, pvcTemplate := range ss.Spec.VolumeClaimTemplates {
@@ -164,10 +299,10 @@ func (n *Namespaces) DestroySFSVolumes(ctx context.Context, ns string, opts Dele
 	}
 
 	if len(pvcNames) == 0 {
-		return nil
+		return result, nil
 	}
 
-	// We only need to delete all the volumes without deployed-by label. The ones with the label will be deleted by
+	// We only need the volumes without deployed-by label. The ones with the label are deleted by
 	// DestroyWithLabel function
 	deployedByNotExist, err := labels.NewRequirement(
 		model.DeployedByLabel,
@@ -175,12 +310,12 @@ func (n *Namespaces) DestroySFSVolumes(ctx context.Context, ns string, opts Dele
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	deployedByNotExistSelector := labels.NewSelector().Add(*deployedByNotExist).String()
 	vList, err := volumes.List(ctx, ns, deployedByNotExistSelector, n.k8sClient)
 	if err != nil {
-		return fmt.Errorf("error getting volumes: %s", err)
+		return nil, fmt.Errorf("error getting volumes: %s", err)
 	}
 	for _, v := range vList {
 		if v.Annotations[resourcePolicyAnnotation] == keepPolicy {
@@ -189,13 +324,11 @@ func (n *Namespaces) DestroySFSVolumes(ctx context.Context, ns string, opts Dele
 		}
 		for _, pvcName := range pvcNames {
 			if strings.HasPrefix(v.Name, pvcName) {
-				if err := volumes.DestroyWithoutTimeout(ctx, v.Name, ns, n.k8sClient); err != nil {
-					return err
-				}
+				result = append(result, v.Name)
 				break
 			}
 		}
 	}
 
-	return nil
+	return result, nil
 }
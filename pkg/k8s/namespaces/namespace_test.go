@@ -5,12 +5,18 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/okteto/okteto/pkg/cmd/pipeline"
 	"github.com/okteto/okteto/pkg/model"
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
 	apiv1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicFake "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes/fake"
 )
 
@@ -335,3 +341,70 @@ func TestDestroySFSVolumesIfNeeded(t *testing.T) {
 		})
 	}
 }
+
+func TestDestroyInventory(t *testing.T) {
+	ctx := context.Background()
+	newObject := func(apiVersion, kind, namespace, name, uid string, annotations map[string]string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(apiVersion)
+		obj.SetKind(kind)
+		obj.SetNamespace(namespace)
+		obj.SetName(name)
+		obj.SetUID(types.UID(uid))
+		obj.SetAnnotations(annotations)
+		return obj
+	}
+	deploymentGVR := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	clusterRoleGVR := schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"}
+	pvcGVR := schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumeclaims"}
+	configMapGVR := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+	dynClient := dynamicFake.NewSimpleDynamicClient(runtime.NewScheme(),
+		newObject("apps/v1", "Deployment", "test", "api", "1", nil),
+		newObject("rbac.authorization.k8s.io/v1", "ClusterRole", "", "api", "2", nil),
+		newObject("v1", "PersistentVolumeClaim", "test", "data", "3", nil),
+		newObject("v1", "ConfigMap", "test", "recreated", "other", nil),
+		newObject("v1", "ConfigMap", "test", "kept", "5", map[string]string{resourcePolicyAnnotation: keepPolicy}),
+	)
+	n := &Namespaces{
+		dynClient: dynClient,
+	}
+
+	inventory := pipeline.Inventory{
+		{APIVersion: "apps/v1", Kind: "Deployment", Resource: "deployments", Namespace: "test", Name: "api", UID: "1"},
+		{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole", Resource: "clusterroles", Name: "api", UID: "2"},
+		{APIVersion: "v1", Kind: "PersistentVolumeClaim", Resource: "persistentvolumeclaims", Namespace: "test", Name: "data", UID: "3"},
+		{APIVersion: "v1", Kind: "ConfigMap", Resource: "configmaps", Namespace: "test", Name: "recreated", UID: "4"},
+		{APIVersion: "v1", Kind: "ConfigMap", Resource: "configmaps", Namespace: "test", Name: "kept", UID: "5"},
+		{APIVersion: "v1", Kind: "Service", Resource: "services", Namespace: "test", Name: "already-deleted", UID: "6"},
+		{APIVersion: "wrong/version/v1", Kind: "Wrong", Resource: "wrongs", Namespace: "test", Name: "wrong"},
+	}
+
+	listed, err := n.ListInventory(ctx, inventory[:6], DeleteAllOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []pipeline.InventoryItem{inventory[0], inventory[1]}, listed)
+	listed, err = n.ListInventory(ctx, inventory[:6], DeleteAllOptions{IncludeVolumes: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []pipeline.InventoryItem{inventory[0], inventory[1], inventory[2]}, listed)
+	_, err = n.ListInventory(ctx, inventory, DeleteAllOptions{})
+	assert.Error(t, err)
+
+	leftovers := n.DestroyInventory(ctx, inventory, DeleteAllOptions{})
+	assert.Equal(t, pipeline.Inventory{inventory[6]}, leftovers)
+
+	_, err = dynClient.Resource(deploymentGVR).Namespace("test").Get(ctx, "api", metav1.GetOptions{})
+	assert.True(t, k8sErrors.IsNotFound(err))
+	_, err = dynClient.Resource(clusterRoleGVR).Get(ctx, "api", metav1.GetOptions{})
+	assert.True(t, k8sErrors.IsNotFound(err))
+	_, err = dynClient.Resource(pvcGVR).Namespace("test").Get(ctx, "data", metav1.GetOptions{})
+	assert.NoError(t, err)
+	_, err = dynClient.Resource(configMapGVR).Namespace("test").Get(ctx, "recreated", metav1.GetOptions{})
+	assert.NoError(t, err)
+	_, err = dynClient.Resource(configMapGVR).Namespace("test").Get(ctx, "kept", metav1.GetOptions{})
+	assert.NoError(t, err)
+
+	leftovers = n.DestroyInventory(ctx, inventory[:3], DeleteAllOptions{IncludeVolumes: true})
+	assert.Empty(t, leftovers)
+	_, err = dynClient.Resource(pvcGVR).Namespace("test").Get(ctx, "data", metav1.GetOptions{})
+	assert.True(t, k8sErrors.IsNotFound(err))
+}
@@ -0,0 +1,351 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package namespaces
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/okteto/okteto/pkg/cmd/pipeline"
+	"github.com/okteto/okteto/pkg/k8s/apps"
+	oktetoLog "github.com/okteto/okteto/pkg/log"
+	"github.com/okteto/okteto/pkg/model"
+	apiv1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	deploymentKind  = "Deployment"
+	statefulsetKind = "StatefulSet"
+	daemonsetKind   = "DaemonSet"
+	cronjobKind     = "CronJob"
+	secretKind      = "Secret"
+	configMapKind   = "ConfigMap"
+
+	oktetoSecretPrefix = "okteto-"
+)
+
+// builtinWorkloadGroups are the api groups of the workloads listed with the typed client
+var builtinWorkloadGroups = map[string]bool{"": true, "apps": true, "batch": true}
+
+// Orphan is a resource created by okteto that is no longer used
+type Orphan struct {
+	Kind              string
+	Name              string
+	Reason            string
+	CreationTimestamp time.Time
+
+	// gvr is the resource of the dev clones of custom resources
+	gvr schema.GroupVersionResource
+}
+
+// podTemplateRefs are the secrets and volumes referenced by the workloads of a namespace
+type podTemplateRefs struct {
+	secrets map[string]bool
+	pvcs    map[string]bool
+}
+
+// workload is a workload of a namespace with its pod template
+type workload struct {
+	kind string
+	meta metav1.ObjectMeta
+	spec apiv1.PodSpec
+}
+
+// ListOrphans returns the resources created by okteto in a namespace that are no longer used, sorted by kind and name:
+// dev clones whose original workload was deleted, okteto secrets not used by any workload, dev volumes whose
+// workload was deleted and pipeline configmaps whose resources were deleted without destroying the pipeline
+func ListOrphans(ctx context.Context, ns string, c kubernetes.Interface, dynClient dynamic.Interface) ([]Orphan, error) {
+	workloads, err := listWorkloads(ctx, ns, c)
+	if err != nil {
+		return nil, err
+	}
+	podList, err := c.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error getting pods: %w", err)
+	}
+
+	result := []Orphan{}
+	uids := map[string]bool{}
+	names := map[string]bool{}
+	refs := &podTemplateRefs{secrets: map[string]bool{}, pvcs: map[string]bool{}}
+	for _, w := range workloads {
+		uids[string(w.meta.UID)] = true
+		if w.meta.Labels[model.DevCloneLabel] == "" {
+			names[w.meta.Name] = true
+		}
+		refs.add(w.spec)
+	}
+	for i := range podList.Items {
+		refs.add(podList.Items[i].Spec)
+	}
+
+	for _, w := range workloads {
+		if uid := w.meta.Labels[model.DevCloneLabel]; uid != "" && !uids[uid] {
+			result = append(result, Orphan{Kind: w.kind, Name: w.meta.Name, Reason: getCloneOrphanReason(w.kind), CreationTimestamp: w.meta.CreationTimestamp.Time})
+		}
+	}
+
+	customOrphans, err := listCustomCloneOrphans(ctx, ns, c.Discovery(), dynClient, refs)
+	if err != nil {
+		return nil, err
+	}
+	result = append(result, customOrphans...)
+
+	devSelector := fmt.Sprintf("%s=true", model.DevLabel)
+	sList, err := c.CoreV1().Secrets(ns).List(ctx, metav1.ListOptions{LabelSelector: devSelector})
+	if err != nil {
+		return nil, fmt.Errorf("error getting secrets: %w", err)
+	}
+	for _, s := range sList.Items {
+		if strings.HasPrefix(s.Name, oktetoSecretPrefix) && !refs.secrets[s.Name] {
+			result = append(result, Orphan{Kind: secretKind, Name: s.Name, Reason: "not used by any development container", CreationTimestamp: s.CreationTimestamp.Time})
+		}
+	}
+
+	pvcList, err := c.CoreV1().PersistentVolumeClaims(ns).List(ctx, metav1.ListOptions{LabelSelector: devSelector})
+	if err != nil {
+		return nil, fmt.Errorf("error getting volumes: %w", err)
+	}
+	for i := range pvcList.Items {
+		pvc := &pvcList.Items[i]
+		if pvc.Annotations[resourcePolicyAnnotation] == keepPolicy {
+			oktetoLog.Debugf("skipping pvc '%s' because of policy annotation", pvc.Name)
+			continue
+		}
+		if refs.pvcs[pvc.Name] || devVolumeAppExists(ctx, pvc, ns, names, dynClient) {
+			continue
+		}
+		result = append(result, Orphan{Kind: volumeKind, Name: pvc.Name, Reason: "the workload of its development container doesn't exist", CreationTimestamp: pvc.CreationTimestamp.Time})
+	}
+
+	pipelineSelector := fmt.Sprintf("%s=true", model.GitDeployLabel)
+	cmapList, err := c.CoreV1().ConfigMaps(ns).List(ctx, metav1.ListOptions{LabelSelector: pipelineSelector})
+	if err != nil {
+		return nil, fmt.Errorf("error getting configmaps: %w", err)
+	}
+	for i := range cmapList.Items {
+		cmap := &cmapList.Items[i]
+		isOrphan, err := pipeline.IsOrphan(ctx, cmap, c, dynClient)
+		if err != nil {
+			oktetoLog.Infof("could not check the pipeline configmap '%s': %s", cmap.Name, err)
+			continue
+		}
+		if isOrphan {
+			result = append(result, Orphan{Kind: configMapKind, Name: cmap.Name, Reason: "all the resources of its development environment were deleted", CreationTimestamp: cmap.CreationTimestamp.Time})
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Kind != result[j].Kind {
+			return result[i].Kind < result[j].Kind
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// DeleteOrphan deletes an orphan returned by ListOrphans
+func DeleteOrphan(ctx context.Context, ns string, orphan Orphan, c kubernetes.Interface, dynClient dynamic.Interface) error {
+	var err error
+	switch {
+	case orphan.gvr.Resource != "":
+		err = dynClient.Resource(orphan.gvr).Namespace(ns).Delete(ctx, orphan.Name, metav1.DeleteOptions{})
+	case orphan.Kind == deploymentKind:
+		err = c.AppsV1().Deployments(ns).Delete(ctx, orphan.Name, metav1.DeleteOptions{})
+	case orphan.Kind == statefulsetKind:
+		err = c.AppsV1().StatefulSets(ns).Delete(ctx, orphan.Name, metav1.DeleteOptions{})
+	case orphan.Kind == daemonsetKind:
+		err = c.AppsV1().DaemonSets(ns).Delete(ctx, orphan.Name, metav1.DeleteOptions{})
+	case orphan.Kind == jobKind:
+		propagation := metav1.DeletePropagationBackground
+		err = c.BatchV1().Jobs(ns).Delete(ctx, orphan.Name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+	case orphan.Kind == secretKind:
+		err = c.CoreV1().Secrets(ns).Delete(ctx, orphan.Name, metav1.DeleteOptions{})
+	case orphan.Kind == volumeKind:
+		err = c.CoreV1().PersistentVolumeClaims(ns).Delete(ctx, orphan.Name, metav1.DeleteOptions{})
+	case orphan.Kind == configMapKind:
+		err = c.CoreV1().ConfigMaps(ns).Delete(ctx, orphan.Name, metav1.DeleteOptions{})
+	default:
+		return fmt.Errorf("kind '%s' is not supported", orphan.Kind)
+	}
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// listWorkloads returns the deployments, statefulsets, daemonsets, jobs and cronjobs of a namespace
+func listWorkloads(ctx context.Context, ns string, c kubernetes.Interface) ([]workload, error) {
+	result := []workload{}
+	dList, err := c.AppsV1().Deployments(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error getting deployments: %w", err)
+	}
+	for i := range dList.Items {
+		result = append(result, workload{kind: deploymentKind, meta: dList.Items[i].ObjectMeta, spec: dList.Items[i].Spec.Template.Spec})
+	}
+	sfsList, err := c.AppsV1().StatefulSets(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error getting statefulsets: %w", err)
+	}
+	for i := range sfsList.Items {
+		result = append(result, workload{kind: statefulsetKind, meta: sfsList.Items[i].ObjectMeta, spec: sfsList.Items[i].Spec.Template.Spec})
+	}
+	dsList, err := c.AppsV1().DaemonSets(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error getting daemonsets: %w", err)
+	}
+	for i := range dsList.Items {
+		result = append(result, workload{kind: daemonsetKind, meta: dsList.Items[i].ObjectMeta, spec: dsList.Items[i].Spec.Template.Spec})
+	}
+	jobList, err := c.BatchV1().Jobs(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error getting jobs: %w", err)
+	}
+	for i := range jobList.Items {
+		result = append(result, workload{kind: jobKind, meta: jobList.Items[i].ObjectMeta, spec: jobList.Items[i].Spec.Template.Spec})
+	}
+	cjList, err := c.BatchV1().CronJobs(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error getting cronjobs: %w", err)
+	}
+	for i := range cjList.Items {
+		result = append(result, workload{kind: cronjobKind, meta: cjList.Items[i].ObjectMeta, spec: cjList.Items[i].Spec.JobTemplate.Spec.Template.Spec})
+	}
+	return result, nil
+}
+
+func getCloneOrphanReason(kind string) string {
+	if kind == jobKind {
+		// the dev clones of cronjobs are jobs
+		return "the original job or cronjob doesn't exist"
+	}
+	return fmt.Sprintf("the original %s doesn't exist", strings.ToLower(kind))
+}
+
+// listCustomCloneOrphans adds the pod templates of the dev clones of custom resources to refs and returns the clones
+// whose original custom resource was deleted. The pod template is read from 'spec.template'.
+// Kinds the user isn't allowed to list are skipped
+func listCustomCloneOrphans(ctx context.Context, ns string, discClient discovery.DiscoveryInterface, dynClient dynamic.Interface, refs *podTemplateRefs) ([]Orphan, error) {
+	resourceLists, err := discovery.ServerPreferredNamespacedResources(discClient)
+	if err != nil {
+		if !discovery.IsGroupDiscoveryFailedError(err) {
+			return nil, err
+		}
+		oktetoLog.Debugf("some api groups are not available: %s", err)
+	}
+	resourceLists = discovery.FilteredBy(discovery.SupportsAllVerbs{Verbs: []string{"list"}}, resourceLists)
+
+	result := []Orphan{}
+	for _, resourceList := range resourceLists {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			return nil, err
+		}
+		if builtinWorkloadGroups[gv.Group] {
+			continue
+		}
+		for _, r := range resourceList.APIResources {
+			if strings.Contains(r.Name, "/") {
+				continue
+			}
+			resource := dynClient.Resource(gv.WithResource(r.Name)).Namespace(ns)
+			list, err := resource.List(ctx, metav1.ListOptions{LabelSelector: model.DevCloneLabel})
+			if err != nil {
+				if k8sErrors.IsForbidden(err) || k8sErrors.IsNotFound(err) || k8sErrors.IsMethodNotSupported(err) {
+					oktetoLog.Debugf("skipping %s when looking for development container clones: %s", r.Name, err)
+					continue
+				}
+				return nil, err
+			}
+			for i := range list.Items {
+				clone := &list.Items[i]
+				refs.addUnstructured(clone)
+				original, err := resource.Get(ctx, strings.TrimSuffix(clone.GetName(), model.DevCloneName("")), metav1.GetOptions{})
+				if err != nil && !k8sErrors.IsNotFound(err) {
+					oktetoLog.Infof("could not get the original %s of '%s': %s", r.Kind, clone.GetName(), err)
+					continue
+				}
+				if err == nil && string(original.GetUID()) == clone.GetLabels()[model.DevCloneLabel] {
+					continue
+				}
+				result = append(result, Orphan{
+					Kind:              clone.GetKind(),
+					Name:              clone.GetName(),
+					Reason:            fmt.Sprintf("the original %s doesn't exist", strings.ToLower(clone.GetKind())),
+					CreationTimestamp: clone.GetCreationTimestamp().Time,
+					gvr:               gv.WithResource(r.Name),
+				})
+			}
+		}
+	}
+	return result, nil
+}
+
+// devVolumeAppExists checks if the workload of the development container of a dev volume exists.
+// Volumes created before the workload was recorded in their annotations are matched by the name of the development container
+func devVolumeAppExists(ctx context.Context, pvc *apiv1.PersistentVolumeClaim, ns string, names map[string]bool, dynClient dynamic.Interface) bool {
+	ref := pvc.Annotations[model.DevVolumeAppAnnotation]
+	if ref == "" {
+		return names[strings.TrimSuffix(pvc.Name, fmt.Sprintf(model.OktetoVolumeNameTemplate, ""))]
+	}
+	gvr, name, err := apps.ParseReference(ref)
+	if err != nil {
+		oktetoLog.Infof("could not check the workload of pvc '%s': %s", pvc.Name, err)
+		return true
+	}
+	if _, err := dynClient.Resource(gvr).Namespace(ns).Get(ctx, name, metav1.GetOptions{}); err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return false
+		}
+		oktetoLog.Infof("could not check the workload of pvc '%s': %s", pvc.Name, err)
+	}
+	return true
+}
+
+func (r *podTemplateRefs) add(spec apiv1.PodSpec) {
+	for _, v := range spec.Volumes {
+		if v.Secret != nil {
+			r.secrets[v.Secret.SecretName] = true
+		}
+		if v.PersistentVolumeClaim != nil {
+			r.pvcs[v.PersistentVolumeClaim.ClaimName] = true
+		}
+	}
+}
+
+// addUnstructured adds the references of the pod template of a custom resource, read from 'spec.template.spec'
+func (r *podTemplateRefs) addUnstructured(obj *unstructured.Unstructured) {
+	spec, found, err := unstructured.NestedMap(obj.Object, "spec", "template", "spec")
+	if err != nil || !found {
+		return
+	}
+	podSpec := apiv1.PodSpec{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(spec, &podSpec); err != nil {
+		oktetoLog.Infof("could not decode the pod template of %s '%s': %s", obj.GetKind(), obj.GetName(), err)
+		return
+	}
+	r.add(podSpec)
+}
@@ -0,0 +1,184 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package namespaces
+
+import (
+	"context"
+	"testing"
+
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicFake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestListOrphans(t *testing.T) {
+	ctx := context.Background()
+	ns := "test"
+	devLabels := map[string]string{model.DevLabel: "true"}
+	podSpec := func(secret, pvc string) apiv1.PodTemplateSpec {
+		return apiv1.PodTemplateSpec{
+			Spec: apiv1.PodSpec{
+				Volumes: []apiv1.Volume{
+					{Name: "secret", VolumeSource: apiv1.VolumeSource{Secret: &apiv1.SecretVolumeSource{SecretName: secret}}},
+					{Name: "data", VolumeSource: apiv1.VolumeSource{PersistentVolumeClaim: &apiv1.PersistentVolumeClaimVolumeSource{ClaimName: pvc}}},
+				},
+			},
+		}
+	}
+	pipelineConfigMap := func(name, status, inventory string) *apiv1.ConfigMap {
+		return &apiv1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "okteto-git-" + name,
+				Namespace: ns,
+				Labels:    map[string]string{model.GitDeployLabel: "true"},
+			},
+			Data: map[string]string{"name": name, "status": status, "inventory": inventory},
+		}
+	}
+	newObject := func(apiVersion, kind, name, uid string, labels map[string]string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(apiVersion)
+		obj.SetKind(kind)
+		obj.SetNamespace(ns)
+		obj.SetName(name)
+		obj.SetUID(types.UID(uid))
+		obj.SetLabels(labels)
+		return obj
+	}
+
+	objects := []runtime.Object{
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: ns, UID: "1", Labels: map[string]string{model.DeployedByLabel: "app"}},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-okteto", Namespace: ns, Labels: map[string]string{model.DevCloneLabel: "1"}},
+			Spec:       appsv1.DeploymentSpec{Template: podSpec("okteto-api", "api-okteto")},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "deleted-okteto", Namespace: ns, Labels: map[string]string{model.DevCloneLabel: "2"}},
+			Spec:       appsv1.DeploymentSpec{Template: podSpec("okteto-deleted", "deleted-okteto")},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db-okteto", Namespace: ns, Labels: map[string]string{model.DevCloneLabel: "3"}},
+		},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: ns, UID: "6"}},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "agent-okteto", Namespace: ns, Labels: map[string]string{model.DevCloneLabel: "4"}},
+		},
+		&batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: ns, UID: "cj1"}},
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "nightly-okteto", Namespace: ns, Labels: map[string]string{model.DevCloneLabel: "cj1"}},
+		},
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "gone-okteto", Namespace: ns, Labels: map[string]string{model.DevCloneLabel: "5"}},
+		},
+		&apiv1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "okteto-api", Namespace: ns, Labels: devLabels}},
+		&apiv1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "okteto-old", Namespace: ns, Labels: devLabels}},
+		&apiv1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: ns, Labels: devLabels}},
+		&apiv1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "api-okteto", Namespace: ns, Labels: devLabels}},
+		&apiv1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "old-okteto", Namespace: ns, Labels: devLabels}},
+		&apiv1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "web-okteto", Namespace: ns, Labels: devLabels}},
+		&apiv1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "front-okteto", Namespace: ns, Labels: devLabels}},
+		&apiv1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker-okteto", Namespace: ns, Labels: devLabels, Annotations: map[string]string{model.DevVolumeAppAnnotation: "deployments.v1.apps/api"}},
+		},
+		&apiv1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "removed-okteto", Namespace: ns, Labels: devLabels, Annotations: map[string]string{model.DevVolumeAppAnnotation: "deployments.v1.apps/removed"}},
+		},
+		&apiv1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "kept-okteto", Namespace: ns, Labels: devLabels, Annotations: map[string]string{resourcePolicyAnnotation: keepPolicy}},
+		},
+		&apiv1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "not-okteto", Namespace: ns}},
+		pipelineConfigMap("app", "deployed", ""),
+		pipelineConfigMap("cron", "deployed", `[{"apiVersion":"batch/v1","kind":"CronJob","resource":"cronjobs","namespace":"test","name":"backup","uid":"c1"}]`),
+		pipelineConfigMap("labelled-cron", "deployed", ""),
+		pipelineConfigMap("removed", "deployed", `[{"apiVersion":"batch/v1","kind":"CronJob","resource":"cronjobs","namespace":"test","name":"gone","uid":"c2"}]`),
+		pipelineConfigMap("deploying", "progressing", ""),
+	}
+	c := fake.NewSimpleClientset(objects...)
+	listVerbs := metav1.Verbs{"get", "list"}
+	c.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{{Name: "deployments", Namespaced: true, Kind: "Deployment", Verbs: listVerbs}},
+		},
+		{
+			GroupVersion: "batch/v1",
+			APIResources: []metav1.APIResource{{Name: "cronjobs", Namespaced: true, Kind: "CronJob", Verbs: listVerbs}},
+		},
+		{
+			GroupVersion: "argoproj.io/v1alpha1",
+			APIResources: []metav1.APIResource{{Name: "rollouts", Namespaced: true, Kind: "Rollout", Verbs: listVerbs}},
+		},
+	}
+	rolloutClone := newObject("argoproj.io/v1alpha1", "Rollout", "front-okteto", "", map[string]string{model.DevCloneLabel: "r1"})
+	template, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&apiv1.PodTemplateSpec{Spec: podSpec("okteto-front", "front-okteto").Spec})
+	require.NoError(t, err)
+	require.NoError(t, unstructured.SetNestedMap(rolloutClone.Object, template, "spec", "template"))
+	dynClient := dynamicFake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			{Group: "apps", Version: "v1", Resource: "deployments"}:           "DeploymentList",
+			{Group: "batch", Version: "v1", Resource: "cronjobs"}:             "CronJobList",
+			{Group: "argoproj.io", Version: "v1alpha1", Resource: "rollouts"}: "RolloutList",
+		},
+		newObject("apps/v1", "Deployment", "api", "1", map[string]string{model.DeployedByLabel: "app"}),
+		newObject("batch/v1", "CronJob", "backup", "c1", nil),
+		newObject("batch/v1", "CronJob", "report", "c3", map[string]string{model.DeployedByLabel: "labelled-cron"}),
+		newObject("argoproj.io/v1alpha1", "Rollout", "front", "r1", nil),
+		rolloutClone,
+		newObject("argoproj.io/v1alpha1", "Rollout", "legacy-okteto", "", map[string]string{model.DevCloneLabel: "r2"}),
+	)
+
+	orphans, err := ListOrphans(ctx, ns, c, dynClient)
+	assert.NoError(t, err)
+
+	names := []string{}
+	for _, o := range orphans {
+		names = append(names, o.Kind+"/"+o.Name)
+	}
+	expected := []string{
+		"ConfigMap/okteto-git-removed",
+		"DaemonSet/agent-okteto",
+		"Deployment/deleted-okteto",
+		"Job/gone-okteto",
+		"PersistentVolumeClaim/old-okteto",
+		"PersistentVolumeClaim/removed-okteto",
+		"Rollout/legacy-okteto",
+		"Secret/okteto-old",
+		"StatefulSet/db-okteto",
+	}
+	assert.Equal(t, expected, names)
+
+	for _, o := range orphans {
+		assert.NoError(t, DeleteOrphan(ctx, ns, o, c, dynClient))
+	}
+	orphans, err = ListOrphans(ctx, ns, c, dynClient)
+	assert.NoError(t, err)
+	assert.Empty(t, orphans)
+
+	err = DeleteOrphan(ctx, ns, Orphan{Kind: "Pod", Name: "pod"}, c, dynClient)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "kind 'Pod' is not supported")
+}
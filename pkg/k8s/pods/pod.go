@@ -31,6 +31,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	apiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/utils/pointer"
@@ -134,6 +135,28 @@ func GetPodByStatefulSet(ctx context.Context, sfs *appsv1.StatefulSet, c kuberne
 	return nil, oktetoErrors.ErrNotFound
 }
 
+// GetPodByOwner returns a pod owned by the object with the given uid that is not being deleted or finished
+func GetPodByOwner(ctx context.Context, namespace string, uid types.UID, c kubernetes.Interface) (*apiv1.Pod, error) {
+	podList, err := c.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range podList.Items {
+		if podList.Items[i].DeletionTimestamp != nil {
+			continue
+		}
+		if podList.Items[i].Status.Phase == apiv1.PodFailed || podList.Items[i].Status.Phase == apiv1.PodSucceeded {
+			continue
+		}
+		for _, or := range podList.Items[i].OwnerReferences {
+			if or.UID == uid {
+				return &podList.Items[i], nil
+			}
+		}
+	}
+	return nil, oktetoErrors.ErrNotFound
+}
+
 // GetUserByPod returns the current user of a running pod
 func GetUserByPod(ctx context.Context, p *apiv1.Pod, container string, config *rest.Config, c *kubernetes.Clientset) (int64, error) {
 	cmd := []string{"sh", "-c", "id -u"}
@@ -46,10 +46,11 @@ func List(ctx context.Context, namespace, labels string, c kubernetes.Interface)
 	return vList.Items, nil
 }
 
-// CreateForDev deploys the volume claim for a given development container
-func CreateForDev(ctx context.Context, dev *model.Dev, c kubernetes.Interface, devPath string) error {
+// CreateForDev deploys the volume claim for a given development container.
+// appRef is the reference of the workload of the development container, used by 'okteto namespace prune'
+func CreateForDev(ctx context.Context, dev *model.Dev, appRef string, c kubernetes.Interface, devPath string) error {
 	vClient := c.CoreV1().PersistentVolumeClaims(dev.Namespace)
-	pvcForDev := translate(dev)
+	pvcForDev := translate(dev, appRef)
 	k8Volume, err := vClient.Get(ctx, pvcForDev.Name, metav1.GetOptions{})
 	if err != nil && !strings.Contains(err.Error(), "not found") {
 		return fmt.Errorf("error getting kubernetes volume claim: %s", err)
@@ -69,6 +70,11 @@ func CreateForDev(ctx context.Context, dev *model.Dev, c kubernetes.Interface, d
 			pvcForDev.Spec.StorageClassName = k8Volume.Spec.StorageClassName
 		}
 		pvcForDev.Spec.VolumeName = k8Volume.Spec.VolumeName
+		for k, v := range k8Volume.Annotations {
+			if _, ok := pvcForDev.Annotations[k]; !ok {
+				pvcForDev.Annotations[k] = v
+			}
+		}
 		_, err = vClient.Update(ctx, pvcForDev, metav1.UpdateOptions{})
 		if err != nil {
 			if !isDynamicallyProvisionedPVCError(err, pvcForDev.Name) {
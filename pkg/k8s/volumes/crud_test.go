@@ -286,7 +286,7 @@ func TestCreateForDev(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			c := fake.NewSimpleClientset()
 			existentPvc := &apiv1.PersistentVolumeClaim{
-				ObjectMeta: metav1.ObjectMeta{Name: "test-okteto"},
+				ObjectMeta: metav1.ObjectMeta{Name: "test-okteto", Annotations: map[string]string{"helm.sh/resource-policy": "keep"}},
 				Spec: apiv1.PersistentVolumeClaimSpec{
 					Resources: apiv1.ResourceRequirements{
 						Requests: apiv1.ResourceList{
@@ -305,13 +305,19 @@ func TestCreateForDev(t *testing.T) {
 				})
 			}
 
-			err = CreateForDev(context.Background(), dev, c, "")
+			err = CreateForDev(context.Background(), dev, "deployments.v1.apps/test", c, "")
 
 			if test.expectedError {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
 			}
+			if len(test.addErrors) == 0 && !test.expectedError {
+				pvc, err := c.CoreV1().PersistentVolumeClaims(namespace).Get(context.Background(), "test-okteto", metav1.GetOptions{})
+				assert.NoError(t, err)
+				assert.Equal(t, "deployments.v1.apps/test", pvc.Annotations[model.DevVolumeAppAnnotation])
+				assert.Equal(t, "keep", pvc.Annotations["helm.sh/resource-policy"])
+			}
 		})
 	}
 }
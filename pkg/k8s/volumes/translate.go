@@ -20,13 +20,14 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func translate(dev *model.Dev) *apiv1.PersistentVolumeClaim {
+func translate(dev *model.Dev, appRef string) *apiv1.PersistentVolumeClaim {
 	pvc := &apiv1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: dev.GetVolumeName(),
 			Labels: map[string]string{
 				model.DevLabel: "true",
 			},
+			Annotations: map[string]string{},
 		},
 		Spec: apiv1.PersistentVolumeClaimSpec{
 			AccessModes: []apiv1.PersistentVolumeAccessMode{apiv1.ReadWriteOnce},
@@ -37,6 +38,9 @@ func translate(dev *model.Dev) *apiv1.PersistentVolumeClaim {
 			},
 		},
 	}
+	if appRef != "" {
+		pvc.Annotations[model.DevVolumeAppAnnotation] = appRef
+	}
 	if dev.PersistentVolumeStorageClass() != "" {
 		storageClass := dev.PersistentVolumeStorageClass()
 		pvc.Spec.StorageClassName = &storageClass
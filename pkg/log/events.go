@@ -0,0 +1,213 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// EventsVersion is the version of the schema of the events. It changes when a field is removed or its meaning changes.
+// See docs/json-events.md
+const EventsVersion = "1"
+
+// EventType is the type of a lifecycle event emitted in json output mode
+type EventType string
+
+const (
+	// StageStartedEvent is emitted when a stage starts
+	StageStartedEvent EventType = "stage_started"
+	// StageFinishedEvent is emitted when a stage finishes
+	StageFinishedEvent EventType = "stage_finished"
+	// ResourceAppliedEvent is emitted when a deploy creates, updates or deletes a kubernetes resource
+	ResourceAppliedEvent EventType = "resource_applied"
+	// BuildStepEvent is emitted when a step of an image build finishes
+	BuildStepEvent EventType = "build_step"
+	// WarningEvent is emitted for every warning
+	WarningEvent EventType = "warning"
+)
+
+// Event is a typed message emitted in json output mode. It keeps the fields of the json messages
+// so consumers of the log lines can ignore the events
+type Event struct {
+	Version   string      `json:"version"`
+	Type      EventType   `json:"type"`
+	Level     string      `json:"level"`
+	Stage     string      `json:"stage"`
+	Message   string      `json:"message"`
+	Timestamp int64       `json:"timestamp"`
+	Data      interface{} `json:"data,omitempty"`
+}
+
+// StageFinishedData is the data of a stage_finished event
+type StageFinishedData struct {
+	DurationMs int64  `json:"durationMs"`
+	ExitCode   int    `json:"exitCode"`
+	Error      string `json:"error,omitempty"`
+}
+
+// ResourceAppliedData is the data of a resource_applied event
+type ResourceAppliedData struct {
+	Action     string `json:"action"`
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind,omitempty"`
+	Resource   string `json:"resource"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name,omitempty"`
+	StatusCode int    `json:"statusCode"`
+	DryRun     bool   `json:"dryRun,omitempty"`
+}
+
+// BuildStepData is the data of a build_step event
+type BuildStepData struct {
+	Image      string `json:"image,omitempty"`
+	Step       string `json:"step"`
+	DurationMs int64  `json:"durationMs"`
+	Cached     bool   `json:"cached"`
+	Error      string `json:"error,omitempty"`
+}
+
+// stageState tracks the current stage to emit its stage_finished event
+type stageState struct {
+	sync.Mutex
+	startTime time.Time
+	exitCode  int
+	err       string
+}
+
+// doneStage is the stage of the last line of the deploy logs. Readers of the logs stop at it so no events are emitted after it
+const doneStage = "done"
+
+var (
+	currentStage = &stageState{}
+
+	// stageMu guards the stage of the logger, read by the goroutines that log while the stage changes
+	stageMu sync.RWMutex
+
+	// bufferMu guards the log buffer, written by the goroutines that log
+	bufferMu sync.Mutex
+)
+
+// getStage returns the current stage of the logger
+func getStage() string {
+	stageMu.RLock()
+	defer stageMu.RUnlock()
+	return log.stage
+}
+
+// writeToBuffer adds a line to the log buffer
+func writeToBuffer(msg string) {
+	bufferMu.Lock()
+	defer bufferMu.Unlock()
+	log.buf.WriteString(msg)
+	log.buf.WriteString("\n")
+}
+
+// setStage finishes the current stage and starts a new one
+func setStage(stage string) {
+	currentStage.Lock()
+	previous := getStage()
+	if stage == previous {
+		currentStage.Unlock()
+		return
+	}
+	if previous != "" && previous != doneStage {
+		data := StageFinishedData{
+			DurationMs: time.Since(currentStage.startTime).Milliseconds(),
+			ExitCode:   currentStage.exitCode,
+			Error:      currentStage.err,
+		}
+		level := InfoLevel
+		if data.ExitCode != 0 {
+			level = ErrorLevel
+		}
+		emitEvent(StageFinishedEvent, level, previous, fmt.Sprintf("Stage '%s' finished", previous), data)
+	}
+	stageMu.Lock()
+	log.stage = stage
+	stageMu.Unlock()
+	currentStage.startTime = time.Now()
+	currentStage.exitCode = 0
+	currentStage.err = ""
+	currentStage.Unlock()
+
+	if stage != "" && stage != doneStage {
+		emitEvent(StageStartedEvent, InfoLevel, stage, fmt.Sprintf("Stage '%s' started", stage), nil)
+	}
+}
+
+// SetStageExitCode sets the exit code reported when the current stage finishes
+func SetStageExitCode(exitCode int, err error) {
+	currentStage.Lock()
+	defer currentStage.Unlock()
+	currentStage.exitCode = exitCode
+	currentStage.err = ""
+	if err != nil {
+		currentStage.err = redactMessage(err.Error())
+	}
+}
+
+// markStageFailed sets a failure exit code in the current stage if no exit code was set
+func markStageFailed(message string) {
+	currentStage.Lock()
+	defer currentStage.Unlock()
+	if currentStage.exitCode == 0 {
+		currentStage.exitCode = 1
+		currentStage.err = message
+	}
+}
+
+// ResourceApplied emits a resource_applied event
+func ResourceApplied(data ResourceAppliedData) {
+	message := fmt.Sprintf("%s %s/%s", data.Action, data.Resource, data.Name)
+	if data.Namespace != "" {
+		message = fmt.Sprintf("%s %s '%s/%s'", data.Action, data.Resource, data.Namespace, data.Name)
+	}
+	emitEvent(ResourceAppliedEvent, InfoLevel, getStage(), message, data)
+}
+
+// BuildStep emits a build_step event
+func BuildStep(data BuildStepData) {
+	level := InfoLevel
+	if data.Error != "" {
+		level = ErrorLevel
+	}
+	emitEvent(BuildStepEvent, level, getStage(), data.Step, data)
+}
+
+// emitEvent writes an event into the output and the log buffer. Events are only emitted in json output mode
+func emitEvent(eventType EventType, level, stage, message string, data interface{}) {
+	if log.outputMode != JSONFormat {
+		return
+	}
+	event := Event{
+		Version:   EventsVersion,
+		Type:      eventType,
+		Level:     level,
+		Stage:     stage,
+		Message:   ansiRegex.ReplaceAllString(redactMessage(message), ""),
+		Timestamp: time.Now().Unix(),
+		Data:      data,
+	}
+	encoded, err := json.Marshal(event)
+	if err != nil {
+		Infof("could not encode event '%s': %s", eventType, err)
+		return
+	}
+
+	writeToBuffer(string(encoded))
+	fmt.Fprintln(log.out.Out, string(encoded))
+}
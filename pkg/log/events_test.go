@@ -0,0 +1,138 @@
+// Copyright 2022 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func getEvents(t *testing.T, output *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	events := []map[string]interface{}{}
+	sc := bufio.NewScanner(output)
+	for sc.Scan() {
+		line := map[string]interface{}{}
+		if err := json.Unmarshal(sc.Bytes(), &line); err != nil {
+			t.Fatalf("invalid json line '%s': %s", sc.Text(), err)
+		}
+		if _, ok := line["type"]; ok {
+			events = append(events, line)
+		}
+	}
+	return events
+}
+
+func Test_Events(t *testing.T) {
+	defer Init(logrus.WarnLevel)
+	output := &bytes.Buffer{}
+	SetOutputFormat(JSONFormat)
+	SetOutput(output)
+
+	SetStage("Deploying")
+	ResourceApplied(ResourceAppliedData{Action: "create", APIVersion: "apps/v1", Kind: "Deployment", Resource: "deployments", Namespace: "test", Name: "api", StatusCode: 201})
+	Warning("deprecated field")
+	SetStageExitCode(2, errors.New("exit status 2"))
+	SetStage("Build")
+	BuildStep(BuildStepData{Step: "RUN make", DurationMs: 10})
+	AddToBuffer(ErrorLevel, "build failed")
+	SetStage("done")
+	AddToBuffer(InfoLevel, "EOF")
+	SetStage("")
+
+	events := getEvents(t, output)
+	types := []string{}
+	for _, e := range events {
+		assert.Equal(t, EventsVersion, e["version"])
+		types = append(types, e["type"].(string))
+	}
+	assert.Equal(t, []string{
+		"stage_started",
+		"resource_applied",
+		"warning",
+		"stage_finished",
+		"stage_started",
+		"build_step",
+		"stage_finished",
+	}, types)
+
+	deployFinished := events[3]
+	assert.Equal(t, "Deploying", deployFinished["stage"])
+	assert.Equal(t, ErrorLevel, deployFinished["level"])
+	data := deployFinished["data"].(map[string]interface{})
+	assert.Equal(t, float64(2), data["exitCode"])
+	assert.Equal(t, "exit status 2", data["error"])
+
+	buildFinished := events[6]["data"].(map[string]interface{})
+	assert.Equal(t, float64(1), buildFinished["exitCode"])
+	assert.Equal(t, "build failed", buildFinished["error"])
+
+	resource := events[1]["data"].(map[string]interface{})
+	assert.Equal(t, "deployments", resource["resource"])
+	assert.Equal(t, "api", resource["name"])
+
+	// events are stored in the log buffer
+	assert.Contains(t, GetOutputBuffer().String(), `"type":"resource_applied"`)
+}
+
+func Test_EventsNotEmittedInTTY(t *testing.T) {
+	defer Init(logrus.WarnLevel)
+	output := &bytes.Buffer{}
+	SetOutputFormat(TTYFormat)
+	SetOutput(output)
+
+	SetStage("Deploying")
+	ResourceApplied(ResourceAppliedData{Action: "create", Resource: "services", Name: "api"})
+	SetStage("")
+
+	assert.Empty(t, output.String())
+}
+
+func Test_EventsFromGoroutines(t *testing.T) {
+	defer Init(logrus.WarnLevel)
+	SetOutputFormat(JSONFormat)
+	SetOutput(io.Discard)
+
+	// the deploy proxy emits events from its goroutines while the deploy changes the stage
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				ResourceApplied(ResourceAppliedData{Action: "create", Resource: "services", Name: "api"})
+			}
+		}()
+	}
+	for i := 0; i < 200; i++ {
+		SetStage(fmt.Sprintf("stage %d", i))
+		Println("running")
+		// the buffer is read while the goroutines write to it
+		assert.Contains(t, GetOutputBuffer().String(), "running")
+	}
+	wg.Wait()
+	SetStage("")
+
+	assert.Equal(t, 1000, strings.Count(GetOutputBuffer().String(), `"type":"resource_applied"`))
+}
@@ -38,6 +38,8 @@ type jsonMessage struct {
 
 // JSONLogFormat formats the messages into json struct
 type JSONLogFormat struct {
+	// Type is the type of the event, empty for log messages
+	Type      string `json:"type,omitempty"`
 	Level     string `json:"level"`
 	Stage     string `json:"stage"`
 	Message   string `json:"message"`
@@ -53,7 +55,7 @@ func (*JSONLogFormat) Format(entry *logrus.Entry) ([]byte, error) {
 	outputJSON := &jsonMessage{
 		Level:     level,
 		Timestamp: time.Now().Unix(),
-		Stage:     log.stage,
+		Stage:     getStage(),
 		Message:   entry.Message,
 	}
 	messageJSON, err := json.Marshal(outputJSON)
@@ -159,15 +161,9 @@ func (*JSONWriter) Question(_ string, _ ...interface{}) error {
 }
 
 // Warning prints a message with the warning symbol first, and the text in yellow
-func (w *JSONWriter) Warning(format string, args ...interface{}) {
+func (*JSONWriter) Warning(format string, args ...interface{}) {
 	log.out.Infof(format, args...)
-	msg := fmt.Sprintf("%s %s", warningSymbol, fmt.Sprintf(format, args...))
-	if msg != "" {
-		msg := convertToJSON("warn", log.stage, msg)
-		if msg != "" {
-			fmt.Fprintln(w.out.Out, msg)
-		}
-	}
+	emitEvent(WarningEvent, WarningLevel, getStage(), fmt.Sprintf("%s %s", warningSymbol, fmt.Sprintf(format, args...)), nil)
 }
 
 // FWarning prints a message with the warning symbol first, and the text in yellow
@@ -175,7 +171,7 @@ func (*JSONWriter) FWarning(writer io.Writer, format string, args ...interface{}
 	log.out.Infof(format, args...)
 	msg := fmt.Sprintf("%s %s", warningSymbol, fmt.Sprintf(format, args...))
 	if msg != "" {
-		msg := convertToJSON("warn", log.stage, msg)
+		msg := convertToJSON("warn", getStage(), msg)
 		if msg != "" {
 			fmt.Fprintln(writer, msg)
 		}
@@ -194,10 +190,9 @@ func (w *JSONWriter) Fail(format string, args ...interface{}) {
 	msg := fmt.Sprintf("%s %s", errorSymbol, fmt.Sprintf(format, args...))
 	if msg != "" {
 
-		msg = convertToJSON(ErrorLevel, log.stage, msg)
+		msg = convertToJSON(ErrorLevel, getStage(), msg)
 		if msg != "" {
-			log.buf.WriteString(msg)
-			log.buf.WriteString("\n")
+			writeToBuffer(msg)
 			fmt.Fprintln(w.out.Out, msg)
 		}
 	}
@@ -216,10 +211,9 @@ func (w *JSONWriter) Fprintf(writer io.Writer, format string, a ...interface{})
 		return
 	}
 	if msg != "" && writer == w.out.Out {
-		msg = convertToJSON(InfoLevel, log.stage, msg)
+		msg = convertToJSON(InfoLevel, getStage(), msg)
 		if msg != "" {
-			log.buf.WriteString(msg)
-			log.buf.WriteString("\n")
+			writeToBuffer(msg)
 		}
 		fmt.Fprint(writer, msg)
 	}
@@ -230,10 +224,9 @@ func (w *JSONWriter) Fprintf(writer io.Writer, format string, a ...interface{})
 func (w *JSONWriter) FPrintln(writer io.Writer, args ...interface{}) {
 	msg := fmt.Sprint(args...)
 	if msg != "" && writer == w.out.Out {
-		msg = convertToJSON(InfoLevel, log.stage, msg)
+		msg = convertToJSON(InfoLevel, getStage(), msg)
 		if msg != "" {
-			log.buf.WriteString(msg)
-			log.buf.WriteString("\n")
+			writeToBuffer(msg)
 			fmt.Fprintln(writer, msg)
 		}
 
@@ -242,10 +235,9 @@ func (w *JSONWriter) FPrintln(writer io.Writer, args ...interface{}) {
 
 // Print writes a line with colors
 func (w *JSONWriter) Print(args ...interface{}) {
-	msg := convertToJSON(InfoLevel, log.stage, fmt.Sprint(args...))
+	msg := convertToJSON(InfoLevel, getStage(), fmt.Sprint(args...))
 	if msg != "" {
-		log.buf.WriteString(msg)
-		log.buf.WriteString("\n")
+		writeToBuffer(msg)
 		fmt.Fprint(w.out.Out, msg)
 	}
 
@@ -279,10 +271,9 @@ func convertToJSON(level, stage, message string) string {
 // AddToBuffer logs into the buffer and writes to stdout if its a json writer
 func (w *JSONWriter) AddToBuffer(level, format string, a ...interface{}) {
 	msg := fmt.Sprintf(format, a...)
-	msg = convertToJSON(level, log.stage, msg)
+	msg = convertToJSON(level, getStage(), msg)
 	if msg != "" {
-		log.buf.WriteString(msg)
-		log.buf.WriteString("\n")
+		writeToBuffer(msg)
 		fmt.Fprintln(w.out.Out, msg)
 	}
 }
@@ -290,7 +281,7 @@ func (w *JSONWriter) AddToBuffer(level, format string, a ...interface{}) {
 // Write logs into the buffer but does not print anything
 func (w *JSONWriter) Write(p []byte) (n int, err error) {
 	msg := string(p)
-	msg = convertToJSON(InfoLevel, log.stage, msg)
+	msg = convertToJSON(InfoLevel, getStage(), msg)
 	if msg != "" {
 		w.out.Out.Write([]byte(""))
 	}
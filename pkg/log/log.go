@@ -171,9 +171,10 @@ func GetOutputWriter() OktetoWriter {
 	return log.writer
 }
 
-// SetStage sets the stage of the logger
+// SetStage sets the stage of the logger.
+// In json output mode it emits the stage_finished event of the previous stage and the stage_started event of the new one
 func SetStage(stage string) {
-	log.stage = stage
+	setStage(stage)
 }
 
 // IsDebug checks if the level of the main logger is DEBUG or TRACE
@@ -344,13 +345,18 @@ func redactMessage(message string) string {
 	return message
 }
 
-// GetOutputBuffer returns the buffer of the running command
+// GetOutputBuffer returns a copy of the buffer of the running command, it can be read while other goroutines keep logging
 func GetOutputBuffer() *bytes.Buffer {
-	return log.buf
+	bufferMu.Lock()
+	defer bufferMu.Unlock()
+	return bytes.NewBuffer(append([]byte(nil), log.buf.Bytes()...))
 }
 
 // AddToBuffer logs into the buffer but does not print anything
 func AddToBuffer(level, format string, args ...interface{}) {
+	if level == ErrorLevel {
+		markStageFailed(redactMessage(fmt.Sprintf(format, args...)))
+	}
 	log.writer.AddToBuffer(level, format, args...)
 }
 
@@ -146,10 +146,9 @@ func (w *PlainWriter) Fail(format string, args ...interface{}) {
 	log.out.Info(msg)
 	w.Fprintf(w.out.Out, "ERROR: %s\n", fmt.Sprintf(format, args...))
 	if msg != "" {
-		msg = convertToJSON(ErrorLevel, log.stage, msg)
+		msg = convertToJSON(ErrorLevel, getStage(), msg)
 		if msg != "" {
-			log.buf.WriteString(msg)
-			log.buf.WriteString("\n")
+			writeToBuffer(msg)
 		}
 	}
 }
@@ -160,10 +159,9 @@ func (w *PlainWriter) Println(args ...interface{}) {
 	log.out.Info(msg)
 	w.FPrintln(w.out.Out, args...)
 	if msg != "" {
-		msg = convertToJSON(InfoLevel, log.stage, msg)
+		msg = convertToJSON(InfoLevel, getStage(), msg)
 		if msg != "" {
-			log.buf.WriteString(msg)
-			log.buf.WriteString("\n")
+			writeToBuffer(msg)
 		}
 	}
 }
@@ -173,9 +171,8 @@ func (w *PlainWriter) Fprintf(writer io.Writer, format string, a ...interface{})
 	msg := fmt.Sprintf(format, a...)
 	fmt.Fprint(writer, msg)
 	if msg != "" && writer == w.out.Out {
-		msg = convertToJSON(InfoLevel, log.stage, msg)
-		log.buf.WriteString(msg)
-		log.buf.WriteString("\n")
+		msg = convertToJSON(InfoLevel, getStage(), msg)
+		writeToBuffer(msg)
 	}
 }
 
@@ -184,10 +181,9 @@ func (w *PlainWriter) FPrintln(writer io.Writer, args ...interface{}) {
 	msg := fmt.Sprint(args...)
 	fmt.Fprintln(writer, args...)
 	if msg != "" && writer == w.out.Out {
-		msg = convertToJSON(InfoLevel, log.stage, msg)
+		msg = convertToJSON(InfoLevel, getStage(), msg)
 		if msg != "" {
-			log.buf.WriteString(msg)
-			log.buf.WriteString("\n")
+			writeToBuffer(msg)
 		}
 	}
 }
@@ -197,10 +193,9 @@ func (w *PlainWriter) Print(args ...interface{}) {
 	msg := fmt.Sprint(args...)
 	fmt.Fprint(w.out.Out, args...)
 	if msg != "" {
-		msg = convertToJSON(InfoLevel, log.stage, msg)
+		msg = convertToJSON(InfoLevel, getStage(), msg)
 		if msg != "" {
-			log.buf.WriteString(msg)
-			log.buf.WriteString("\n")
+			writeToBuffer(msg)
 		}
 	}
 }
@@ -219,10 +214,9 @@ func (*PlainWriter) IsInteractive() bool {
 func (*PlainWriter) AddToBuffer(level, format string, a ...interface{}) {
 	msg := fmt.Sprintf(format, a...)
 	if msg != "" {
-		msg = convertToJSON(level, log.stage, msg)
+		msg = convertToJSON(level, getStage(), msg)
 		if msg != "" {
-			log.buf.WriteString(msg)
-			log.buf.WriteString("\n")
+			writeToBuffer(msg)
 		}
 	}
 }
@@ -17,6 +17,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 	"time"
 	"unicode"
 
@@ -33,6 +34,8 @@ type spinnerLogger struct {
 	sp             *sp.Spinner
 	spinnerSupport bool
 	onHold         bool
+	// mu protects the messages of the spinner, they can be updated by several goroutines
+	mu sync.Mutex
 }
 
 // hold is used within the TTYWritter to pause the spinner to display the log
@@ -70,32 +73,50 @@ func newSpinner() *sp.Spinner {
 
 // Spinner sets the text provided as Suffix and FinalMSG of the spinner instance
 func Spinner(text string) {
+	log.spinner.mu.Lock()
+	defer log.spinner.mu.Unlock()
 	log.spinner.sp.Suffix = fmt.Sprintf(" %s", ucFirst(text))
 	log.spinner.sp.FinalMSG = log.spinner.sp.Suffix
 }
 
 // StartSpinner starts to run the spinner if enabled or Println if not
 func StartSpinner() {
-	if log.spinner.spinnerSupport {
-		if log.spinner.sp.FinalMSG == "" {
-			log.spinner.sp.FinalMSG = log.spinner.sp.Suffix
-		}
-		log.spinner.sp.Start()
-	} else {
-		Println(strings.TrimSpace(log.spinner.sp.Suffix))
+	log.spinner.mu.Lock()
+	if !log.spinner.spinnerSupport {
+		suffix := log.spinner.sp.Suffix
+		log.spinner.mu.Unlock()
+		Println(strings.TrimSpace(suffix))
+		return
+	}
+	if log.spinner.sp.FinalMSG == "" {
+		log.spinner.sp.FinalMSG = log.spinner.sp.Suffix
 	}
+	log.spinner.mu.Unlock()
+	log.spinner.sp.Start()
 }
 
 // StopSpinner deletes FinalMSG and stops the running of the spinner
 func StopSpinner() {
+	log.spinner.mu.Lock()
 	if log.spinner.sp.FinalMSG != "" {
 		log.spinner.sp.FinalMSG = ""
 	}
-	if log.spinner.spinnerSupport {
+	spinnerSupport := log.spinner.spinnerSupport
+	log.spinner.mu.Unlock()
+	if spinnerSupport {
 		log.spinner.sp.Stop()
 	}
 }
 
+// DisableSpinner prints the spinner messages instead of animating them.
+// It's used when several operations report their progress at the same time
+func DisableSpinner() {
+	StopSpinner()
+	log.spinner.mu.Lock()
+	defer log.spinner.mu.Unlock()
+	log.spinner.spinnerSupport = false
+}
+
 func ucFirst(str string) string {
 	for i, v := range str {
 		return string(unicode.ToUpper(v)) + str[i+1:]
@@ -170,10 +170,9 @@ func (w *TTYWriter) Fail(format string, args ...interface{}) {
 	w.Fprintf(w.out.Out, "%s %s\n", coloredErrorSymbol, redString(format, args...))
 	log.spinner.unhold()
 	if msg != "" {
-		msg = convertToJSON(ErrorLevel, log.stage, msg)
+		msg = convertToJSON(ErrorLevel, getStage(), msg)
 		if msg != "" {
-			log.buf.WriteString(msg)
-			log.buf.WriteString("\n")
+			writeToBuffer(msg)
 		}
 	}
 }
@@ -191,10 +190,9 @@ func (w *TTYWriter) Fprintf(writer io.Writer, format string, a ...interface{}) {
 	msg := fmt.Sprintf(format, a...)
 	fmt.Fprint(writer, msg)
 	if msg != "" && writer == w.out.Out {
-		msg = convertToJSON(InfoLevel, log.stage, msg)
+		msg = convertToJSON(InfoLevel, getStage(), msg)
 		if msg != "" {
-			log.buf.WriteString(msg)
-			log.buf.WriteString("\n")
+			writeToBuffer(msg)
 		}
 	}
 
@@ -205,10 +203,9 @@ func (w *TTYWriter) FPrintln(writer io.Writer, args ...interface{}) {
 	msg := fmt.Sprint(args...)
 	fmt.Fprintln(writer, msg)
 	if msg != "" && writer == w.out.Out {
-		msg = convertToJSON(InfoLevel, log.stage, msg)
+		msg = convertToJSON(InfoLevel, getStage(), msg)
 		if msg != "" {
-			log.buf.WriteString(msg)
-			log.buf.WriteString("\n")
+			writeToBuffer(msg)
 		}
 	}
 
@@ -219,10 +216,9 @@ func (w *TTYWriter) Print(args ...interface{}) {
 	msg := fmt.Sprint(args...)
 	fmt.Fprint(w.out.Out, args...)
 	if msg != "" {
-		msg = convertToJSON(ErrorLevel, log.stage, msg)
+		msg = convertToJSON(ErrorLevel, getStage(), msg)
 		if msg != "" {
-			log.buf.WriteString(msg)
-			log.buf.WriteString("\n")
+			writeToBuffer(msg)
 		}
 	}
 
@@ -244,10 +240,9 @@ func (*TTYWriter) IsInteractive() bool {
 func (*TTYWriter) AddToBuffer(level, format string, a ...interface{}) {
 	msg := fmt.Sprintf(format, a...)
 	if msg != "" {
-		msg = convertToJSON(level, log.stage, msg)
+		msg = convertToJSON(level, getStage(), msg)
 		if msg != "" {
-			log.buf.WriteString(msg)
-			log.buf.WriteString("\n")
+			writeToBuffer(msg)
 		}
 	}
 }
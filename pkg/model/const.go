@@ -49,6 +49,9 @@ const (
 	// DeploymentAnnotation indicates the original deployment manifest  when the development container was activated
 	DeploymentAnnotation = "dev.okteto.com/deployment"
 
+	// DevNodeAnnotation indicates the node where the development container of a daemonset runs
+	DevNodeAnnotation = "dev.okteto.com/node"
+
 	// StatefulsetAnnotation indicates the original statefulset manifest  when the development container was activated
 	StatefulsetAnnotation = "dev.okteto.com/statefulset"
 
@@ -67,6 +70,9 @@ const (
 	// OktetoDevNameAnnotation indicates the name of the dev to be deployed
 	OktetoDevNameAnnotation = "dev.okteto.com/name"
 
+	// DevVolumeAppAnnotation indicates the workload of the development container of a dev volume
+	DevVolumeAppAnnotation = "dev.okteto.com/app"
+
 	// OktetoPathAnnotation indicates the okteto manifest path of this component
 	OktetoPathAnnotation = "dev.okteto.com/path"
 
@@ -118,6 +124,10 @@ const (
 	StatefulSet = "StatefulSet"
 	// Job k8s job kind
 	Job = "job"
+	// DaemonSet k8s daemonset kind
+	DaemonSet = "DaemonSet"
+	// CronJob k8s cronjob kind
+	CronJob = "CronJob"
 
 	// Localhost localhost
 	Localhost = "localhost"
@@ -233,6 +243,9 @@ const (
 	// OktetoAutoDeployEnvVar if set the application will be deployed while running okteto up
 	OktetoAutoDeployEnvVar = "OKTETO_AUTODEPLOY"
 
+	// OktetoUpDetachedEnvVar is set on the background process started by 'okteto up --detach'
+	OktetoUpDetachedEnvVar = "OKTETO_UP_DETACHED"
+
 	// OktetoAppsSubdomainEnvVar defines which is the subdomain for urls
 	OktetoAppsSubdomainEnvVar = "OKTETO_APPS_SUBDOMAIN"
 
@@ -325,6 +338,9 @@ const (
 	// OktetoAutogenerateStignoreEnvVar skips the autogenerate stignore dialog and creates the default one
 	OktetoAutogenerateStignoreEnvVar = "OKTETO_AUTOGENERATE_STIGNORE"
 
+	// OktetoContainerRuntimeEnvVar defines the container runtime used to run the deploy commands with an image, i.e. docker or podman
+	OktetoContainerRuntimeEnvVar = "OKTETO_CONTAINER_RUNTIME"
+
 	// OktetoDefaultImageTag default tag assigned to image to build
 	OktetoDefaultImageTag = "okteto"
 
@@ -28,6 +28,7 @@ import (
 
 	"github.com/a8m/envsubst"
 	"github.com/compose-spec/godotenv"
+	"github.com/docker/docker/pkg/fileutils"
 	"github.com/google/uuid"
 	oktetoErrors "github.com/okteto/okteto/pkg/errors"
 	"github.com/okteto/okteto/pkg/filesystem"
@@ -55,6 +56,7 @@ type Dev struct {
 	Username             string             `json:"-" yaml:"-"`
 	RegistryURL          string             `json:"-" yaml:"-"`
 	Selector             Selector           `json:"selector,omitempty" yaml:"selector,omitempty"`
+	Workload             *PodTemplateRule   `json:"workload,omitempty" yaml:"workload,omitempty"`
 	Annotations          Annotations        `json:"annotations,omitempty" yaml:"annotations,omitempty"`
 	Tolerations          []apiv1.Toleration `json:"tolerations,omitempty" yaml:"tolerations,omitempty"`
 	Context              string             `json:"context,omitempty" yaml:"context,omitempty"`
@@ -74,6 +76,7 @@ type Dev struct {
 	ServiceAccount       string             `json:"serviceAccount,omitempty" yaml:"serviceAccount,omitempty"`
 	RemotePort           int                `json:"remote,omitempty" yaml:"remote,omitempty"`
 	SSHServerPort        int                `json:"sshServerPort,omitempty" yaml:"sshServerPort,omitempty"`
+	PersistentSession    bool               `json:"persistentSession,omitempty" yaml:"persistentSession,omitempty"`
 	ExternalVolumes      []ExternalVolume   `json:"externalVolumes,omitempty" yaml:"externalVolumes,omitempty"`
 	Sync                 Sync               `json:"sync,omitempty" yaml:"sync,omitempty"`
 	parentSyncFolder     string
@@ -93,6 +96,7 @@ type Dev struct {
 	EnvFiles             EnvFiles              `json:"envFiles,omitempty" yaml:"envFiles,omitempty"`
 	Environment          Environment           `json:"environment,omitempty" yaml:"environment,omitempty"`
 	Volumes              []Volume              `json:"volumes,omitempty" yaml:"volumes,omitempty"`
+	Watch                *Watch                `json:"watch,omitempty" yaml:"watch,omitempty"`
 
 	// Deprecated fields
 	Healthchecks bool   `json:"healthchecks,omitempty" yaml:"healthchecks,omitempty"`
@@ -256,6 +260,29 @@ type InitContainer struct {
 	Resources ResourceRequirements `json:"resources,omitempty" yaml:"resources,omitempty"`
 }
 
+// Watch represents a command to run in the development container when synchronized files change
+type Watch struct {
+	// Paths are the patterns of the files that run the command, relative to the synchronized folders
+	Paths    []string      `json:"paths,omitempty" yaml:"paths,omitempty"`
+	Command  Command       `json:"command,omitempty" yaml:"command,omitempty"`
+	Debounce time.Duration `json:"debounce,omitempty" yaml:"debounce,omitempty"`
+	Policy   WatchPolicy   `json:"policy,omitempty" yaml:"policy,omitempty"`
+}
+
+// WatchPolicy defines what happens when files change while the watch command is running
+type WatchPolicy string
+
+const (
+	// WatchRestartPolicy stops the running command and its child processes, and runs it again
+	WatchRestartPolicy WatchPolicy = "restart"
+
+	// WatchQueuePolicy runs the command again when the running one finishes
+	WatchQueuePolicy WatchPolicy = "queue"
+
+	// defaultWatchDebounce is the time without changes to wait before running the watch command
+	defaultWatchDebounce = 500 * time.Millisecond
+)
+
 // Timeout represents the timeout for the command
 type Timeout struct {
 	Default   time.Duration `json:"default,omitempty" yaml:"default,omitempty"`
@@ -539,6 +566,15 @@ func (dev *Dev) SetDefaults() error {
 		return err
 	}
 
+	if dev.Watch != nil {
+		if dev.Watch.Debounce == 0 {
+			dev.Watch.Debounce = defaultWatchDebounce
+		}
+		if dev.Watch.Policy == "" {
+			dev.Watch.Policy = WatchRestartPolicy
+		}
+	}
+
 	if dev.ImagePullPolicy == "" {
 		dev.ImagePullPolicy = apiv1.PullAlways
 	}
@@ -772,6 +808,14 @@ func (dev *Dev) Validate() error {
 		return err
 	}
 
+	if err := dev.validateWorkload(); err != nil {
+		return err
+	}
+
+	if err := dev.validateWatch(); err != nil {
+		return err
+	}
+
 	if _, err := resource.ParseQuantity(dev.PersistentVolumeSize()); err != nil {
 		return fmt.Errorf("'persistentVolume.size' is not valid. A sample value would be '10Gi'")
 	}
@@ -792,6 +836,51 @@ func (dev *Dev) Validate() error {
 	return nil
 }
 
+// validateWorkload validates the custom resource of a development container
+func (dev *Dev) validateWorkload() error {
+	if dev.Workload == nil {
+		return nil
+	}
+	if dev.Workload.APIVersion == "" || dev.Workload.Kind == "" {
+		return oktetoErrors.UserError{
+			E:    fmt.Errorf("'workload.apiVersion' and 'workload.kind' are required"),
+			Hint: "Update the 'workload' field in your okteto manifest file with the apiVersion and kind of your custom resource",
+		}
+	}
+	if dev.Autocreate {
+		return fmt.Errorf("'workload' and 'autocreate' cannot be used together")
+	}
+	return nil
+}
+
+// validateWatch validates the command to run when synchronized files change
+func (dev *Dev) validateWatch() error {
+	if dev.Watch == nil {
+		return nil
+	}
+	if len(dev.Watch.Paths) == 0 {
+		return oktetoErrors.UserError{
+			E:    fmt.Errorf("'watch.paths' is required"),
+			Hint: "Update the 'watch' field in your okteto manifest file with the patterns of the files that run the command",
+		}
+	}
+	if _, err := fileutils.NewPatternMatcher(dev.Watch.Paths); err != nil {
+		return fmt.Errorf("'watch.paths' is not valid: %w", err)
+	}
+	if len(dev.Watch.Command.Values) == 0 {
+		return fmt.Errorf("'watch.command' is required")
+	}
+	if dev.Watch.Debounce < 0 {
+		return fmt.Errorf("'watch.debounce' must be >= 0")
+	}
+	switch dev.Watch.Policy {
+	case WatchRestartPolicy, WatchQueuePolicy:
+	default:
+		return fmt.Errorf("'watch.policy' must be '%s' or '%s'", WatchRestartPolicy, WatchQueuePolicy)
+	}
+	return nil
+}
+
 func (dev *Dev) validateSync() error {
 	for _, folder := range dev.Sync.Folders {
 		validPath, err := os.Stat(folder.LocalPath)
@@ -1308,6 +1397,12 @@ func (service *Dev) validateForExtraFields() error {
 	if service.SSHServerPort != 0 {
 		return fmt.Errorf(errorMessage, "sshServerPort")
 	}
+	if service.PersistentSession {
+		return fmt.Errorf(errorMessage, "persistentSession")
+	}
+	if service.Watch != nil {
+		return fmt.Errorf(errorMessage, "watch")
+	}
 	if service.ExternalVolumes != nil {
 		return fmt.Errorf(errorMessage, "externalVolumes")
 	}
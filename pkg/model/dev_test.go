@@ -954,6 +954,87 @@ func Test_validate(t *testing.T) {
         runAsGroup: 0`),
 			expectErr: false,
 		},
+		{
+			name: "workload",
+			manifest: []byte(`
+      name: deployment
+      sync:
+        - .:/app
+      workload:
+        apiVersion: argoproj.io/v1alpha1
+        kind: Rollout`),
+			expectErr: false,
+		},
+		{
+			name: "workload-without-kind",
+			manifest: []byte(`
+      name: deployment
+      sync:
+        - .:/app
+      workload:
+        apiVersion: argoproj.io/v1alpha1`),
+			expectErr: true,
+		},
+		{
+			name: "workload-with-autocreate",
+			manifest: []byte(`
+      name: deployment
+      autocreate: true
+      sync:
+        - .:/app
+      workload:
+        apiVersion: argoproj.io/v1alpha1
+        kind: Rollout`),
+			expectErr: true,
+		},
+		{
+			name: "watch",
+			manifest: []byte(`
+      name: deployment
+      sync:
+        - .:/app
+      watch:
+        paths:
+          - "**/*.go"
+        command: go build -o /tmp/app && /tmp/app
+        debounce: 1s
+        policy: queue`),
+			expectErr: false,
+		},
+		{
+			name: "watch-without-paths",
+			manifest: []byte(`
+      name: deployment
+      sync:
+        - .:/app
+      watch:
+        command: make`),
+			expectErr: true,
+		},
+		{
+			name: "watch-without-command",
+			manifest: []byte(`
+      name: deployment
+      sync:
+        - .:/app
+      watch:
+        paths:
+          - "*.py"`),
+			expectErr: true,
+		},
+		{
+			name: "watch-wrong-policy",
+			manifest: []byte(`
+      name: deployment
+      sync:
+        - .:/app
+      watch:
+        paths:
+          - "*.py"
+        command: make
+        policy: ignore`),
+			expectErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -1232,6 +1313,31 @@ services:
 	}
 }
 
+func Test_watchDefaults(t *testing.T) {
+	manifest, err := Read([]byte(`
+      name: deployment
+      sync:
+        - .:/app
+      watch:
+        paths:
+          - "**/*.go"
+        command: make`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	watch := manifest.Dev["deployment"].Watch
+	if watch.Debounce != defaultWatchDebounce {
+		t.Errorf("debounce wasn't %s it was %s", defaultWatchDebounce, watch.Debounce)
+	}
+	if watch.Policy != WatchRestartPolicy {
+		t.Errorf("policy wasn't %s it was %s", WatchRestartPolicy, watch.Policy)
+	}
+	if !reflect.DeepEqual(watch.Command.Values, []string{"make"}) {
+		t.Errorf("command wasn't 'make' it was %v", watch.Command.Values)
+	}
+}
+
 func Test_validateForExtraFields(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -1296,6 +1402,17 @@ func Test_validateForExtraFields(t *testing.T) {
 			name:  "sshServerPort",
 			value: "sshServerPort: 2222",
 		},
+		{
+			name:  "persistentSession",
+			value: "persistentSession: true",
+		},
+		{
+			name: "watch",
+			value: `watch:
+                   paths:
+                   - "*.go"
+                   command: make`,
+		},
 		{
 			name:  "externalVolumes",
 			value: `externalVolumes: []`,
@@ -20,8 +20,10 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/a8m/envsubst"
 	"github.com/okteto/okteto/pkg/discovery"
@@ -49,6 +51,8 @@ var (
 	KubernetesType Archetype = "kubernetes"
 	// ChartType represents a k8s manifest type
 	ChartType Archetype = "chart"
+
+	divertHeaderNameRegex = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
 )
 
 const (
@@ -173,18 +177,115 @@ func NewManifestFromDev(dev *Dev) *Manifest {
 
 // DeployInfo represents what must be deployed for the app to work
 type DeployInfo struct {
+	// Image is the container image where the commands are executed. Commands are executed in the local shell if empty
+	Image          string              `json:"image,omitempty" yaml:"image,omitempty"`
 	Commands       []DeployCommand     `json:"commands,omitempty" yaml:"commands,omitempty"`
 	ComposeSection *ComposeSectionInfo `json:"compose,omitempty" yaml:"compose,omitempty"`
 	Endpoints      EndpointSpec        `json:"endpoints,omitempty" yaml:"endpoints,omitempty"`
 	Divert         *DivertDeploy       `json:"divert,omitempty" yaml:"divert,omitempty"`
+	PodTemplates   []PodTemplateRule   `json:"podTemplates,omitempty" yaml:"podTemplates,omitempty"`
+	Helm           *HelmDeploy         `json:"helm,omitempty" yaml:"helm,omitempty"`
+	Kustomize      *KustomizeDeploy    `json:"kustomize,omitempty" yaml:"kustomize,omitempty"`
+}
+
+// HelmDeploy represents a helm chart installed by the deploy
+type HelmDeploy struct {
+	Chart string `json:"chart,omitempty" yaml:"chart,omitempty"`
+	// Release is the name of the helm release. It defaults to the name of the development environment
+	Release string `json:"release,omitempty" yaml:"release,omitempty"`
+	// Repository is the url of the chart repository
+	Repository  string            `json:"repository,omitempty" yaml:"repository,omitempty"`
+	Version     string            `json:"version,omitempty" yaml:"version,omitempty"`
+	ValuesFiles []string          `json:"valuesFiles,omitempty" yaml:"valuesFiles,omitempty"`
+	Set         map[string]string `json:"set,omitempty" yaml:"set,omitempty"`
+	Wait        bool              `json:"wait,omitempty" yaml:"wait,omitempty"`
+}
+
+// KustomizeDeploy represents a kustomization applied by the deploy.
+// The images named as the services of the build section are replaced by the images built by okteto
+type KustomizeDeploy struct {
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+}
+
+// PodTemplateRule represents where the pod template is embedded in a workload kind, e.g.
+// apiVersion: argoproj.io/v1alpha1, kind: Rollout, path: spec.template
+type PodTemplateRule struct {
+	APIVersion string `json:"apiVersion,omitempty" yaml:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty" yaml:"kind,omitempty"`
+	// Resource is the plural name of the kind in the API. It's guessed from the kind if not defined
+	Resource string `json:"resource,omitempty" yaml:"resource,omitempty"`
+	Path     string `json:"path,omitempty" yaml:"path,omitempty"`
+	// ReplicasPath is the path of the replicas of the workload. It's only used by the 'workload' field of a development container
+	ReplicasPath string `json:"replicasPath,omitempty" yaml:"replicasPath,omitempty"`
 }
 
 // DivertDeploy represents information about the deploy divert configuration
 type DivertDeploy struct {
+	// Driver is the implementation of the divert: 'weaver' (default) or 'istio'
+	Driver     string `json:"driver,omitempty" yaml:"driver,omitempty"`
 	Namespace  string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
 	Service    string `json:"service,omitempty" yaml:"service,omitempty"`
 	Port       int    `json:"port,omitempty" yaml:"port,omitempty"`
 	Deployment string `json:"deployment,omitempty" yaml:"deployment,omitempty"`
+	// Header is the header that routes the traffic to the development environment. It's the default of every rule
+	Header *DivertHeader `json:"header,omitempty" yaml:"header,omitempty"`
+	// Rules are the services diverted. They can't be used together with service, port and deployment
+	Rules []DivertRule `json:"rules,omitempty" yaml:"rules,omitempty"`
+}
+
+const (
+	// DivertWeaverDriver diverts the traffic using the divert controller of Okteto
+	DivertWeaverDriver = "weaver"
+	// DivertIstioDriver diverts the traffic using istio virtual services
+	DivertIstioDriver = "istio"
+)
+
+// GetDriver returns the divert driver, weaver by default
+func (d *DivertDeploy) GetDriver() string {
+	if d.Driver == "" {
+		return DivertWeaverDriver
+	}
+	return d.Driver
+}
+
+// DivertRule represents a service diverted to the development environment
+type DivertRule struct {
+	Service    string        `json:"service,omitempty" yaml:"service,omitempty"`
+	Port       int           `json:"port,omitempty" yaml:"port,omitempty"`
+	Deployment string        `json:"deployment,omitempty" yaml:"deployment,omitempty"`
+	Header     *DivertHeader `json:"header,omitempty" yaml:"header,omitempty"`
+	// Paths limits the divert to the requests whose path starts with any of the paths. Empty means every path
+	Paths []string `json:"paths,omitempty" yaml:"paths,omitempty"`
+}
+
+// DivertHeader represents the header and value of the requests routed to the development environment.
+// The value defaults to the namespace of the development environment
+type DivertHeader struct {
+	Name  string `json:"name,omitempty" yaml:"name,omitempty"`
+	Value string `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+// GetRules returns the divert rules. The service, port and deployment fields are returned as a single rule.
+// Rules without header get the header of the divert section
+func (d *DivertDeploy) GetRules() []DivertRule {
+	rules := d.Rules
+	if len(rules) == 0 {
+		rules = []DivertRule{
+			{
+				Service:    d.Service,
+				Port:       d.Port,
+				Deployment: d.Deployment,
+			},
+		}
+	}
+	result := make([]DivertRule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.Header == nil {
+			rule.Header = d.Header
+		}
+		result = append(result, rule)
+	}
+	return result
 }
 
 // ComposeSectionInfo represents information about compose file
@@ -206,6 +307,10 @@ type ServicesToDeploy []string
 type DeployCommand struct {
 	Name    string `json:"name,omitempty" yaml:"name,omitempty"`
 	Command string `json:"command,omitempty" yaml:"command,omitempty"`
+	// Image is the container image where the command is executed. It overrides the image of the deploy section
+	Image string `json:"image,omitempty" yaml:"image,omitempty"`
+	// Timeout is the time the command can run before it's stopped. Zero means no limit
+	Timeout time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
 }
 
 // NewDeployInfo creates a deploy Info
@@ -659,6 +764,12 @@ func (m *Manifest) validate() error {
 	if err := m.Build.validate(); err != nil {
 		return err
 	}
+	if err := m.validatePodTemplates(); err != nil {
+		return err
+	}
+	if err := m.validateHelmAndKustomize(); err != nil {
+		return err
+	}
 	return m.validateDivert()
 }
 
@@ -736,6 +847,37 @@ func (m *Manifest) SanitizeSvcNames() error {
 	return nil
 }
 
+func (m *Manifest) validatePodTemplates() error {
+	if m.Deploy == nil {
+		return nil
+	}
+	for i, rule := range m.Deploy.PodTemplates {
+		if rule.APIVersion == "" {
+			return fmt.Errorf("the field 'deploy.podTemplates[%d].apiVersion' is mandatory", i)
+		}
+		if rule.Kind == "" {
+			return fmt.Errorf("the field 'deploy.podTemplates[%d].kind' is mandatory", i)
+		}
+		if rule.Path == "" {
+			return fmt.Errorf("the field 'deploy.podTemplates[%d].path' is mandatory", i)
+		}
+	}
+	return nil
+}
+
+func (m *Manifest) validateHelmAndKustomize() error {
+	if m.Deploy == nil {
+		return nil
+	}
+	if m.Deploy.Helm != nil && m.Deploy.Helm.Chart == "" {
+		return fmt.Errorf("the field 'deploy.helm.chart' is mandatory")
+	}
+	if m.Deploy.Kustomize != nil && m.Deploy.Kustomize.Path == "" {
+		return fmt.Errorf("the field 'deploy.kustomize.path' is mandatory")
+	}
+	return nil
+}
+
 func (m *Manifest) validateDivert() error {
 	if m.Deploy == nil {
 		return nil
@@ -743,14 +885,62 @@ func (m *Manifest) validateDivert() error {
 	if m.Deploy.Divert == nil {
 		return nil
 	}
-	if m.Deploy.Divert.Namespace == "" {
+	divert := m.Deploy.Divert
+	if divert.Namespace == "" {
 		return fmt.Errorf("the field 'deploy.divert.namespace' is mandatory")
 	}
-	if m.Deploy.Divert.Service == "" {
-		return fmt.Errorf("the field 'deploy.divert.service' is mandatory")
+	if driver := divert.GetDriver(); driver != DivertWeaverDriver && driver != DivertIstioDriver {
+		return fmt.Errorf("the value '%s' of the field 'deploy.divert.driver' is not supported. Supported values are '%s' and '%s'", driver, DivertWeaverDriver, DivertIstioDriver)
+	}
+	if err := validateDivertHeader(divert.Header, "deploy.divert.header"); err != nil {
+		return err
+	}
+	if len(divert.Rules) == 0 {
+		if divert.Service == "" {
+			return fmt.Errorf("the field 'deploy.divert.service' is mandatory")
+		}
+		if divert.Deployment == "" {
+			return fmt.Errorf("the field 'deploy.divert.deployment' is mandatory")
+		}
+		return nil
+	}
+
+	if divert.Service != "" || divert.Port != 0 || divert.Deployment != "" {
+		return fmt.Errorf("the fields 'deploy.divert.service', 'deploy.divert.port' and 'deploy.divert.deployment' can't be used together with 'deploy.divert.rules'")
+	}
+	services := map[string]bool{}
+	for i, rule := range divert.Rules {
+		if rule.Service == "" {
+			return fmt.Errorf("the field 'deploy.divert.rules[%d].service' is mandatory", i)
+		}
+		if rule.Deployment == "" {
+			return fmt.Errorf("the field 'deploy.divert.rules[%d].deployment' is mandatory", i)
+		}
+		if services[rule.Service] {
+			return fmt.Errorf("the service '%s' is diverted by more than one rule in 'deploy.divert.rules'", rule.Service)
+		}
+		services[rule.Service] = true
+		if err := validateDivertHeader(rule.Header, fmt.Sprintf("deploy.divert.rules[%d].header", i)); err != nil {
+			return err
+		}
+		for j, path := range rule.Paths {
+			if !strings.HasPrefix(path, "/") {
+				return fmt.Errorf("the field 'deploy.divert.rules[%d].paths[%d]' must start with '/'", i, j)
+			}
+		}
+	}
+	return nil
+}
+
+func validateDivertHeader(header *DivertHeader, field string) error {
+	if header == nil {
+		return nil
+	}
+	if header.Name == "" {
+		return fmt.Errorf("the field '%s.name' is mandatory", field)
 	}
-	if m.Deploy.Divert.Deployment == "" {
-		return fmt.Errorf("the field 'deploy.divert.deployment' is mandatory")
+	if !divertHeaderNameRegex.MatchString(header.Name) {
+		return fmt.Errorf("the field '%s.name' must contain only letters, numbers and '-'", field)
 	}
 	return nil
 }
@@ -966,7 +1156,7 @@ func (m *Manifest) InferFromStack(cwd string) (*Manifest, error) {
 // WriteToFile writes a manifest to a file with comments to make it easier to understand
 func (m *Manifest) WriteToFile(filePath string) error {
 	if m.Deploy != nil {
-		if len(m.Deploy.Commands) == 0 && m.Deploy.ComposeSection == nil {
+		if len(m.Deploy.Commands) == 0 && m.Deploy.ComposeSection == nil && m.Deploy.Helm == nil && m.Deploy.Kustomize == nil {
 			m.Deploy.Commands = []DeployCommand{
 				{
 					Name:    FakeCommand,
@@ -276,6 +276,225 @@ func Test_validateDivert(t *testing.T) {
 	}
 }
 
+func Test_validateDivertRules(t *testing.T) {
+	tests := []struct {
+		name        string
+		divert      DivertDeploy
+		expectedErr error
+	}{
+		{
+			name: "rules-ok",
+			divert: DivertDeploy{
+				Namespace: "namespace",
+				Header:    &DivertHeader{Name: "x-okteto-divert"},
+				Rules: []DivertRule{
+					{Service: "api", Deployment: "api", Port: 8080, Paths: []string{"/api"}},
+					{Service: "frontend", Deployment: "frontend", Header: &DivertHeader{Name: "x-frontend", Value: "beta"}},
+				},
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "driver-ko",
+			divert: DivertDeploy{
+				Driver:     "linkerd",
+				Namespace:  "namespace",
+				Service:    "api",
+				Deployment: "api",
+			},
+			expectedErr: fmt.Errorf("the value 'linkerd' of the field 'deploy.divert.driver' is not supported. Supported values are 'weaver' and 'istio'"),
+		},
+		{
+			name: "rules-ko-with-service",
+			divert: DivertDeploy{
+				Namespace: "namespace",
+				Service:   "api",
+				Rules:     []DivertRule{{Service: "api", Deployment: "api"}},
+			},
+			expectedErr: fmt.Errorf("the fields 'deploy.divert.service', 'deploy.divert.port' and 'deploy.divert.deployment' can't be used together with 'deploy.divert.rules'"),
+		},
+		{
+			name: "rules-ko-without-service",
+			divert: DivertDeploy{
+				Namespace: "namespace",
+				Rules:     []DivertRule{{Service: "api", Deployment: "api"}, {Deployment: "frontend"}},
+			},
+			expectedErr: fmt.Errorf("the field 'deploy.divert.rules[1].service' is mandatory"),
+		},
+		{
+			name: "rules-ko-without-deployment",
+			divert: DivertDeploy{
+				Namespace: "namespace",
+				Rules:     []DivertRule{{Service: "api"}},
+			},
+			expectedErr: fmt.Errorf("the field 'deploy.divert.rules[0].deployment' is mandatory"),
+		},
+		{
+			name: "rules-ko-duplicated-service",
+			divert: DivertDeploy{
+				Namespace: "namespace",
+				Rules:     []DivertRule{{Service: "api", Deployment: "api"}, {Service: "api", Deployment: "api-v2"}},
+			},
+			expectedErr: fmt.Errorf("the service 'api' is diverted by more than one rule in 'deploy.divert.rules'"),
+		},
+		{
+			name: "rules-ko-invalid-path",
+			divert: DivertDeploy{
+				Namespace: "namespace",
+				Rules:     []DivertRule{{Service: "api", Deployment: "api", Paths: []string{"/api", "web"}}},
+			},
+			expectedErr: fmt.Errorf("the field 'deploy.divert.rules[0].paths[1]' must start with '/'"),
+		},
+		{
+			name: "header-ko-without-name",
+			divert: DivertDeploy{
+				Namespace:  "namespace",
+				Service:    "api",
+				Deployment: "api",
+				Header:     &DivertHeader{Value: "beta"},
+			},
+			expectedErr: fmt.Errorf("the field 'deploy.divert.header.name' is mandatory"),
+		},
+		{
+			name: "rule-header-ko-invalid-name",
+			divert: DivertDeploy{
+				Namespace: "namespace",
+				Rules:     []DivertRule{{Service: "api", Deployment: "api", Header: &DivertHeader{Name: "x team"}}},
+			},
+			expectedErr: fmt.Errorf("the field 'deploy.divert.rules[0].header.name' must contain only letters, numbers and '-'"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Manifest{
+				Deploy: &DeployInfo{
+					Divert: &tt.divert,
+				},
+			}
+			assert.Equal(t, tt.expectedErr, m.validateDivert())
+		})
+	}
+}
+
+func TestDivertDeployGetRules(t *testing.T) {
+	header := &DivertHeader{Name: "x-team"}
+	d := &DivertDeploy{
+		Namespace:  "staging",
+		Service:    "api",
+		Port:       8080,
+		Deployment: "api",
+		Header:     header,
+	}
+	assert.Equal(t, []DivertRule{{Service: "api", Port: 8080, Deployment: "api", Header: header}}, d.GetRules())
+
+	ruleHeader := &DivertHeader{Name: "x-frontend"}
+	d = &DivertDeploy{
+		Namespace: "staging",
+		Header:    header,
+		Rules: []DivertRule{
+			{Service: "api", Deployment: "api"},
+			{Service: "frontend", Deployment: "frontend", Header: ruleHeader},
+		},
+	}
+	assert.Equal(t, []DivertRule{
+		{Service: "api", Deployment: "api", Header: header},
+		{Service: "frontend", Deployment: "frontend", Header: ruleHeader},
+	}, d.GetRules())
+}
+
+func Test_validatePodTemplates(t *testing.T) {
+	tests := []struct {
+		name        string
+		rule        PodTemplateRule
+		expectedErr error
+	}{
+		{
+			name: "pod-template-ok",
+			rule: PodTemplateRule{
+				APIVersion: "argoproj.io/v1alpha1",
+				Kind:       "Rollout",
+				Path:       "spec.template",
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "pod-template-ko-without-api-version",
+			rule: PodTemplateRule{
+				Kind: "Rollout",
+				Path: "spec.template",
+			},
+			expectedErr: fmt.Errorf("the field 'deploy.podTemplates[0].apiVersion' is mandatory"),
+		},
+		{
+			name: "pod-template-ko-without-kind",
+			rule: PodTemplateRule{
+				APIVersion: "argoproj.io/v1alpha1",
+				Path:       "spec.template",
+			},
+			expectedErr: fmt.Errorf("the field 'deploy.podTemplates[0].kind' is mandatory"),
+		},
+		{
+			name: "pod-template-ko-without-path",
+			rule: PodTemplateRule{
+				APIVersion: "argoproj.io/v1alpha1",
+				Kind:       "Rollout",
+			},
+			expectedErr: fmt.Errorf("the field 'deploy.podTemplates[0].path' is mandatory"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Manifest{
+				Deploy: &DeployInfo{
+					PodTemplates: []PodTemplateRule{tt.rule},
+				},
+			}
+			assert.Equal(t, tt.expectedErr, m.validatePodTemplates())
+		})
+	}
+}
+
+func Test_validateHelmAndKustomize(t *testing.T) {
+	tests := []struct {
+		name        string
+		deploy      *DeployInfo
+		expectedErr error
+	}{
+		{
+			name: "helm-and-kustomize-ok",
+			deploy: &DeployInfo{
+				Helm:      &HelmDeploy{Chart: "chart"},
+				Kustomize: &KustomizeDeploy{Path: "k8s"},
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "helm-ko-without-chart",
+			deploy: &DeployInfo{
+				Helm: &HelmDeploy{Release: "release"},
+			},
+			expectedErr: fmt.Errorf("the field 'deploy.helm.chart' is mandatory"),
+		},
+		{
+			name: "kustomize-ko-without-path",
+			deploy: &DeployInfo{
+				Kustomize: &KustomizeDeploy{},
+			},
+			expectedErr: fmt.Errorf("the field 'deploy.kustomize.path' is mandatory"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Manifest{
+				Deploy: tt.deploy,
+			}
+			assert.Equal(t, tt.expectedErr, m.validateHelmAndKustomize())
+		})
+	}
+}
+
 func Test_validateManifestBuild(t *testing.T) {
 	tests := []struct {
 		name         string
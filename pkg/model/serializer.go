@@ -877,9 +877,9 @@ func (d *DeployInfo) MarshalYAML() (interface{}, error) {
 	if d.ComposeSection != nil && len(d.ComposeSection.ComposesInfo) != 0 {
 		return d, nil
 	}
-	isCommandList := true
+	isCommandList := d.Image == "" && d.Helm == nil && d.Kustomize == nil
 	for _, cmd := range d.Commands {
-		if cmd.Command != cmd.Name {
+		if cmd.Command != cmd.Name || cmd.Image != "" || cmd.Timeout != 0 {
 			isCommandList = false
 		}
 	}
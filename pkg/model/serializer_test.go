@@ -1817,6 +1817,75 @@ func TestDeployInfoUnmarshalling(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "divert rules",
+			deployInfoManifest: []byte(`divert:
+  namespace: staging
+  header:
+    name: x-team
+  rules:
+  - service: api
+    port: 8080
+    deployment: api
+    paths:
+    - /api
+  - service: frontend
+    deployment: frontend
+    header:
+      name: x-frontend
+      value: beta`),
+			expected: &DeployInfo{
+				Divert: &DivertDeploy{
+					Namespace: "staging",
+					Header:    &DivertHeader{Name: "x-team"},
+					Rules: []DivertRule{
+						{Service: "api", Port: 8080, Deployment: "api", Paths: []string{"/api"}},
+						{Service: "frontend", Deployment: "frontend", Header: &DivertHeader{Name: "x-frontend", Value: "beta"}},
+					},
+				},
+			},
+		},
+		{
+			name: "command with timeout",
+			deployInfoManifest: []byte(`commands:
+- name: deploy chart
+  command: helm upgrade --install movies chart
+  timeout: 5m`),
+			expected: &DeployInfo{
+				Commands: []DeployCommand{
+					{
+						Name:    "deploy chart",
+						Command: "helm upgrade --install movies chart",
+						Timeout: 5 * time.Minute,
+					},
+				},
+			},
+		},
+		{
+			name: "helm and kustomize",
+			deployInfoManifest: []byte(`helm:
+  chart: ./chart
+  release: movies
+  valuesFiles:
+  - values.yaml
+  set:
+    api.replicas: "2"
+  wait: true
+kustomize:
+  path: k8s/overlays/dev`),
+			expected: &DeployInfo{
+				Helm: &HelmDeploy{
+					Chart:       "./chart",
+					Release:     "movies",
+					ValuesFiles: []string{"values.yaml"},
+					Set:         map[string]string{"api.replicas": "2"},
+					Wait:        true,
+				},
+				Kustomize: &KustomizeDeploy{
+					Path: "k8s/overlays/dev",
+				},
+			},
+		},
 		{
 			name: "commands",
 			deployInfoManifest: []byte(`commands:
@@ -1830,6 +1899,29 @@ func TestDeployInfoUnmarshalling(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "commands with image",
+			deployInfoManifest: []byte(`image: alpine/helm:3.10.0
+commands:
+- helm upgrade --install movies chart
+- name: kubectl
+  command: kubectl apply -f k8s.yml
+  image: bitnami/kubectl:1.25`),
+			expected: &DeployInfo{
+				Image: "alpine/helm:3.10.0",
+				Commands: []DeployCommand{
+					{
+						Name:    "helm upgrade --install movies chart",
+						Command: "helm upgrade --install movies chart",
+					},
+					{
+						Name:    "kubectl",
+						Command: "kubectl apply -f k8s.yml",
+						Image:   "bitnami/kubectl:1.25",
+					},
+				},
+			},
+		},
 		{
 			name: "compose with endpoints",
 			deployInfoManifest: []byte(`compose:
@@ -1915,6 +2007,19 @@ func TestDeployInfoMarshalling(t *testing.T) {
 			}},
 			expected: "commands:\n- name: build\n  command: okteto build\n- name: deploy\n  command: okteto deploy\n",
 		},
+		{
+			name: "commands-with-image",
+			deployInfo: &DeployInfo{
+				Image: "alpine/helm:3.10.0",
+				Commands: []DeployCommand{
+					{
+						Name:    "helm upgrade --install movies chart",
+						Command: "helm upgrade --install movies chart",
+					},
+				},
+			},
+			expected: "image: alpine/helm:3.10.0\ncommands:\n- name: helm upgrade --install movies chart\n  command: helm upgrade --install movies chart\n",
+		},
 	}
 
 	for _, tt := range tests {
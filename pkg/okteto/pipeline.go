@@ -499,6 +499,11 @@ func (c *pipelineClient) StreamLogs(ctx context.Context, name, actionName string
 				continue
 			}
 
+			// lifecycle events are for machine consumption, only warnings are shown
+			if eventLog.Type != "" && eventLog.Type != string(oktetoLog.WarningEvent) {
+				continue
+			}
+
 			// stop the scanner when the event log is in stage done and message is EOF
 			if eventLog.Stage == "done" && eventLog.Message == "EOF" {
 				break
@@ -162,6 +162,22 @@ type ItemEvent struct {
 	Data     map[string]map[string]DownloadProgressData `json:"data"`
 }
 
+// ItemFinishedEvent represents an item synchronized by syncthing.
+type ItemFinishedEvent struct {
+	Id   int                   `json:"id"`
+	Type string                `json:"type"`
+	Data DataItemFinishedEvent `json:"data"`
+}
+
+// DataItemFinishedEvent represents the data of an item synchronized by syncthing.
+type DataItemFinishedEvent struct {
+	Item   string  `json:"item"`
+	Folder string  `json:"folder"`
+	Error  *string `json:"error"`
+	Type   string  `json:"type"`
+	Action string  `json:"action"`
+}
+
 // Connections represents syncthing connections.
 type Connections struct {
 	Connections map[string]Connection `json:"connections"`
@@ -770,6 +786,25 @@ func (s *Syncthing) GetInSynchronizationFile(ctx context.Context) string {
 	return getInSynchronizationLargestFile(events[len(events)-1])
 }
 
+// GetItemFinishedEvents returns the items synchronized by the remote syncthing after the event 'since'
+func (s *Syncthing) GetItemFinishedEvents(ctx context.Context, since int) ([]ItemFinishedEvent, error) {
+	params := map[string]string{
+		"since":   strconv.Itoa(since),
+		"timeout": "0",
+		"events":  "ItemFinished",
+	}
+	body, err := s.APICall(ctx, "rest/events", "GET", 200, params, false, nil, true, 3)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []ItemFinishedEvent{}
+	if err := json.Unmarshal(body, &events); err != nil {
+		return nil, fmt.Errorf("error unmarshalling events: %w", err)
+	}
+	return events, nil
+}
+
 func getInSynchronizationLargestFile(e ItemEvent) string {
 	result := ""
 	var largerFileSize int64